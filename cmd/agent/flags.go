@@ -4,27 +4,66 @@ import (
 	"flag"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 )
 
+// parseFlags builds the agent configuration. Each setting comes from its
+// environment variable, else an explicitly set flag, else the JSON config
+// file named by -config or CONFIG, else the flag default.
 func parseFlags() (*configs.AgentConfig, error) {
 	fs := flag.NewFlagSet("agent", flag.ExitOnError)
 
+	var configFlag string
+	fs.StringVar(&configFlag, "config", "", "path to a JSON config file, re-read on SIGHUP")
+
 	options := []configs.AgentOption{
 		withServerAddress(fs),
-		withServerEndpoint(fs),
 		withLogLevel(fs),
 		withPollInterval(fs),
 		withReportInterval(fs),
 		withNumWorkers(fs),
+		withCollectors(fs),
+		withSidecarAddress(fs),
+		withTLSCA(fs),
+		withTLSInsecureSkipVerify(fs),
+		withTLSCert(fs),
+		withTLSKey(fs),
+		withAPIToken(fs),
 	}
 
 	fs.Parse(os.Args[1:])
 
+	configPath := configFlag
+	if env := os.Getenv("CONFIG"); env != "" {
+		configPath = env
+	}
+	if configPath != "" {
+		if err := configs.ApplyFile(fs, configPath, agentConfigFileKeys); err != nil {
+			return nil, err
+		}
+	}
+
 	return configs.NewAgentConfig(options...), nil
 }
 
+// agentConfigFileKeys maps config file keys to the flags they set.
+var agentConfigFileKeys = map[string]string{
+	"address":                  "a",
+	"log_level":                "l",
+	"poll_interval":            "p",
+	"report_interval":          "r",
+	"num_workers":              "w",
+	"collectors":               "c",
+	"sidecar_address":          "sidecar-addr",
+	"tls_ca_file":              "tls-ca",
+	"tls_insecure_skip_verify": "tls-insecure-skip-verify",
+	"tls_cert_file":            "tls-cert",
+	"tls_key_file":             "tls-key",
+	"api_token":                "token",
+}
+
 func withServerAddress(fs *flag.FlagSet) configs.AgentOption {
 	var addrFlag string
 	fs.StringVar(&addrFlag, "a", "localhost:8080", "HTTP server endpoint address")
@@ -38,19 +77,6 @@ func withServerAddress(fs *flag.FlagSet) configs.AgentOption {
 	}
 }
 
-func withServerEndpoint(fs *flag.FlagSet) configs.AgentOption {
-	var endpointFlag string
-	fs.StringVar(&endpointFlag, "e", "/update", "API endpoint for updating metrics")
-
-	return func(cfg *configs.AgentConfig) {
-		if env := os.Getenv("SERVER_ENDPOINT"); env != "" {
-			cfg.ServerEndpoint = env
-			return
-		}
-		cfg.ServerEndpoint = endpointFlag
-	}
-}
-
 func withLogLevel(fs *flag.FlagSet) configs.AgentOption {
 	var levelFlag string
 	fs.StringVar(&levelFlag, "l", "info", "logging level")
@@ -108,3 +134,100 @@ func withNumWorkers(fs *flag.FlagSet) configs.AgentOption {
 		cfg.NumWorkers = workersFlag
 	}
 }
+
+func withCollectors(fs *flag.FlagSet) configs.AgentOption {
+	var collectorsFlag string
+	fs.StringVar(&collectorsFlag, "c", "runtime,poll_count,telemetry", "comma-separated collectors to enable, each as name[:key=value...] (e.g. poll_count:interval=5)")
+
+	return func(cfg *configs.AgentConfig) {
+		collectors := collectorsFlag
+		if env := os.Getenv("COLLECTORS"); env != "" {
+			collectors = env
+		}
+		if collectors == "" {
+			cfg.Collectors = nil
+			return
+		}
+		cfg.Collectors = strings.Split(collectors, ",")
+	}
+}
+
+func withSidecarAddress(fs *flag.FlagSet) configs.AgentOption {
+	var addrFlag string
+	fs.StringVar(&addrFlag, "sidecar-addr", "", "local HTTP address accepting metrics from applications to relay (empty disables it)")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("SIDECAR_ADDRESS"); env != "" {
+			cfg.SidecarAddress = env
+			return
+		}
+		cfg.SidecarAddress = addrFlag
+	}
+}
+
+func withTLSCA(fs *flag.FlagSet) configs.AgentOption {
+	var caFlag string
+	fs.StringVar(&caFlag, "tls-ca", "", "PEM CA bundle used to verify the server certificate")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("TLS_CA_FILE"); env != "" {
+			cfg.TLSCAFile = env
+			return
+		}
+		cfg.TLSCAFile = caFlag
+	}
+}
+
+func withTLSInsecureSkipVerify(fs *flag.FlagSet) configs.AgentOption {
+	var skipFlag bool
+	fs.BoolVar(&skipFlag, "tls-insecure-skip-verify", false, "skip server certificate verification (testing only)")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("TLS_INSECURE_SKIP_VERIFY"); env != "" {
+			if v, err := strconv.ParseBool(env); err == nil {
+				cfg.TLSInsecureSkipVerify = v
+				return
+			}
+		}
+		cfg.TLSInsecureSkipVerify = skipFlag
+	}
+}
+
+func withTLSCert(fs *flag.FlagSet) configs.AgentOption {
+	var certFlag string
+	fs.StringVar(&certFlag, "tls-cert", "", "PEM client certificate presented to servers that require one")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("TLS_CERT_FILE"); env != "" {
+			cfg.TLSCertFile = env
+			return
+		}
+		cfg.TLSCertFile = certFlag
+	}
+}
+
+func withTLSKey(fs *flag.FlagSet) configs.AgentOption {
+	var keyFlag string
+	fs.StringVar(&keyFlag, "tls-key", "", "PEM private key file for -tls-cert")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("TLS_KEY_FILE"); env != "" {
+			cfg.TLSKeyFile = env
+			return
+		}
+		cfg.TLSKeyFile = keyFlag
+	}
+}
+
+func withAPIToken(fs *flag.FlagSet) configs.AgentOption {
+	var tokenFlag string
+	fs.StringVar(&tokenFlag, "token", "", "bearer token sent with every report")
+
+	return func(cfg *configs.AgentConfig) {
+		if env := os.Getenv("API_TOKEN"); env != "" {
+			cfg.APIToken = env
+			return
+		}
+		cfg.APIToken = tokenFlag
+	}
+}
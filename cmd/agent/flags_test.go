@@ -2,16 +2,19 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type parseFlagsTestCase struct {
 	name            string
 	env             map[string]string
 	args            []string
+	configFile      string
 	expected        configs.AgentConfig
 	expectParseFail bool
 }
@@ -24,44 +27,62 @@ func TestParseFlags_TableDriven(t *testing.T) {
 			args: []string{"cmd"},
 			expected: configs.AgentConfig{
 				ServerAddress:  "localhost:8080",
-				ServerEndpoint: "/update",
 				LogLevel:       "info",
 				PollInterval:   2,
 				ReportInterval: 10,
 				NumWorkers:     5,
+				Collectors:     []string{"runtime", "poll_count", "telemetry"},
 			},
 		},
 		{
 			name: "Env overrides",
 			env: map[string]string{
-				"ADDRESS":         "env:1234",
-				"SERVER_ENDPOINT": "/env-update",
-				"LOG_LEVEL":       "debug",
-				"POLL_INTERVAL":   "99",
-				"REPORT_INTERVAL": "100",
-				"NUM_WORKERS":     "7",
+				"ADDRESS":                  "env:1234",
+				"LOG_LEVEL":                "debug",
+				"POLL_INTERVAL":            "99",
+				"REPORT_INTERVAL":          "100",
+				"NUM_WORKERS":              "7",
+				"COLLECTORS":               "runtime:random=false",
+				"SIDECAR_ADDRESS":          "localhost:9001",
+				"TLS_CA_FILE":              "/env/ca.pem",
+				"TLS_INSECURE_SKIP_VERIFY": "true",
+				"TLS_CERT_FILE":            "/env/agent.pem",
+				"TLS_KEY_FILE":             "/env/agent-key.pem",
+				"API_TOKEN":                "env-token",
 			},
-			args: []string{"cmd", "-a=flag:5678", "-e=/flag-update", "-l=warn", "-p=1", "-r=2", "-w=3"},
+			args: []string{"cmd", "-a=flag:5678", "-l=warn", "-p=1", "-r=2", "-w=3", "-c=poll_count", "-sidecar-addr=localhost:9002", "-tls-ca=/flag/ca.pem", "-tls-insecure-skip-verify=false", "-tls-cert=/flag/agent.pem", "-tls-key=/flag/agent-key.pem"},
 			expected: configs.AgentConfig{
-				ServerAddress:  "env:1234",
-				ServerEndpoint: "/env-update",
-				LogLevel:       "debug",
-				PollInterval:   99,
-				ReportInterval: 100,
-				NumWorkers:     7,
+				ServerAddress:         "env:1234",
+				LogLevel:              "debug",
+				PollInterval:          99,
+				ReportInterval:        100,
+				NumWorkers:            7,
+				Collectors:            []string{"runtime:random=false"},
+				SidecarAddress:        "localhost:9001",
+				TLSCAFile:             "/env/ca.pem",
+				TLSInsecureSkipVerify: true,
+				TLSCertFile:           "/env/agent.pem",
+				TLSKeyFile:            "/env/agent-key.pem",
+				APIToken:              "env-token",
 			},
 		},
 		{
 			name: "Flags fallback",
 			env:  map[string]string{},
-			args: []string{"cmd", "-a=flaghost:9999", "-e=/metrics", "-l=trace", "-p=11", "-r=12", "-w=13"},
+			args: []string{"cmd", "-a=flaghost:9999", "-l=trace", "-p=11", "-r=12", "-w=13", "-c=runtime,poll_count:interval=5", "-sidecar-addr=127.0.0.1:9003", "-tls-ca=/flag/ca.pem", "-tls-insecure-skip-verify", "-tls-cert=/flag/agent.pem", "-tls-key=/flag/agent-key.pem"},
 			expected: configs.AgentConfig{
-				ServerAddress:  "flaghost:9999",
-				ServerEndpoint: "/metrics",
-				LogLevel:       "trace",
-				PollInterval:   11,
-				ReportInterval: 12,
-				NumWorkers:     13,
+				ServerAddress:         "flaghost:9999",
+				LogLevel:              "trace",
+				PollInterval:          11,
+				ReportInterval:        12,
+				NumWorkers:            13,
+				Collectors:            []string{"runtime", "poll_count:interval=5"},
+				SidecarAddress:        "127.0.0.1:9003",
+				TLSCAFile:             "/flag/ca.pem",
+				TLSInsecureSkipVerify: true,
+				TLSCertFile:           "/flag/agent.pem",
+				TLSKeyFile:            "/flag/agent-key.pem",
+				APIToken:              "flag-token",
 			},
 		},
 		{
@@ -74,13 +95,43 @@ func TestParseFlags_TableDriven(t *testing.T) {
 			args: []string{"cmd", "-p=21", "-r=22", "-w=23"},
 			expected: configs.AgentConfig{
 				ServerAddress:  "localhost:8080",
-				ServerEndpoint: "/update",
 				LogLevel:       "info",
 				PollInterval:   21,
 				ReportInterval: 22,
 				NumWorkers:     23,
+				Collectors:     []string{"runtime", "poll_count", "telemetry"},
 			},
 		},
+		{
+			name: "Config file below env and explicit flags",
+			env: map[string]string{
+				"REPORT_INTERVAL": "30",
+			},
+			args:       []string{"cmd", "-w=9"},
+			configFile: `{"address":"file:7000","poll_interval":4,"report_interval":20,"num_workers":6,"collectors":["runtime"],"sidecar_address":"localhost:9100","tls_ca_file":"/file/ca.pem"}`,
+			expected: configs.AgentConfig{
+				ServerAddress:  "file:7000",
+				LogLevel:       "info",
+				PollInterval:   4,
+				ReportInterval: 30,
+				NumWorkers:     9,
+				Collectors:     []string{"runtime"},
+				SidecarAddress: "localhost:9100",
+				TLSCAFile:      "/file/ca.pem",
+			},
+		},
+		{
+			name:            "Invalid config file",
+			args:            []string{"cmd"},
+			configFile:      `{"poll_interval":"soon"}`,
+			expectParseFail: true,
+		},
+		{
+			name:            "Removed server endpoint key",
+			args:            []string{"cmd"},
+			configFile:      `{"server_endpoint":"/update"}`,
+			expectParseFail: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -91,6 +142,11 @@ func TestParseFlags_TableDriven(t *testing.T) {
 			for k, v := range tc.env {
 				t.Setenv(k, v)
 			}
+			if tc.configFile != "" {
+				path := filepath.Join(t.TempDir(), "agent.json")
+				require.NoError(t, os.WriteFile(path, []byte(tc.configFile), 0o600))
+				t.Setenv("CONFIG", path)
+			}
 			// Установить аргументы командной строки
 			os.Args = tc.args
 
@@ -102,11 +158,12 @@ func TestParseFlags_TableDriven(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expected.ServerAddress, cfg.ServerAddress)
-			assert.Equal(t, tc.expected.ServerEndpoint, cfg.ServerEndpoint)
 			assert.Equal(t, tc.expected.LogLevel, cfg.LogLevel)
 			assert.Equal(t, tc.expected.PollInterval, cfg.PollInterval)
 			assert.Equal(t, tc.expected.ReportInterval, cfg.ReportInterval)
 			assert.Equal(t, tc.expected.NumWorkers, cfg.NumWorkers)
+			assert.Equal(t, tc.expected.Collectors, cfg.Collectors)
+			assert.Equal(t, tc.expected.SidecarAddress, cfg.SidecarAddress)
 		})
 	}
 }
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +22,8 @@ import (
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/testutils"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
 type MockAgentSuite struct {
@@ -39,7 +48,7 @@ func (s *MockAgentSuite) SetupSuite() {
 
 	// Create a new Chi router and register a mock handler
 	r := chi.NewRouter()
-	r.Post("/update/{type}/{name}/{value}", s.mockMetricUpdateHandler)
+	r.Post("/updates/", s.mockMetricUpdateBatchHandler)
 
 	s.ts = httptest.NewServer(r)
 	s.serverURL = s.ts.URL
@@ -49,18 +58,37 @@ func (s *MockAgentSuite) TearDownSuite() {
 	s.ts.Close()
 }
 
-// mockMetricUpdateHandler is a mock HTTP handler that captures metrics sent to it.
-func (s *MockAgentSuite) mockMetricUpdateHandler(w http.ResponseWriter, r *http.Request) {
-	typ := chi.URLParam(r, "type")
-	name := chi.URLParam(r, "name")
-	value := chi.URLParam(r, "value")
+// mockMetricUpdateBatchHandler is a mock HTTP handler that captures the
+// metric batches sent to it.
+func (s *MockAgentSuite) mockMetricUpdateBatchHandler(w http.ResponseWriter, r *http.Request) {
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer gz.Close()
+
+	var batch []types.Metrics
+	if err := json.NewDecoder(gz).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
 	s.mu.Lock()
-	s.metrics = append(s.metrics, MockMetric{
-		Type:  typ,
-		Name:  name,
-		Value: value,
-	})
+	for _, m := range batch {
+		var value string
+		switch {
+		case m.Delta != nil:
+			value = strconv.FormatInt(*m.Delta, 10)
+		case m.Value != nil:
+			value = strconv.FormatFloat(*m.Value, 'f', -1, 64)
+		}
+		s.metrics = append(s.metrics, MockMetric{
+			Type:  m.MType,
+			Name:  m.ID,
+			Value: value,
+		})
+	}
 	s.mu.Unlock()
 
 	w.WriteHeader(http.StatusOK)
@@ -69,11 +97,11 @@ func (s *MockAgentSuite) mockMetricUpdateHandler(w http.ResponseWriter, r *http.
 func (s *MockAgentSuite) TestAgentSendsMetrics() {
 	cfg := &configs.AgentConfig{
 		ServerAddress:  s.serverURL,
-		ServerEndpoint: "/update/",
 		LogLevel:       "debug",
 		PollInterval:   1,
 		ReportInterval: 1,
 		NumWorkers:     1,
+		Collectors:     []string{"runtime", "poll_count", "telemetry"},
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -85,6 +113,7 @@ func (s *MockAgentSuite) TestAgentSendsMetrics() {
 		logger.Initialize,
 		apps.NewAgentApp,
 		runners.NewRunContext,
+		noReloads,
 		runners.RunWorker,
 	)
 
@@ -100,13 +129,181 @@ func (s *MockAgentSuite) TestAgentSendsMetrics() {
 	s.Require().NotEmpty(s.metrics)
 
 	found := false
+	telemetry := false
 	for _, m := range s.metrics {
 		if m.Name == "Alloc" && m.Type == "gauge" {
 			found = true
-			break
+		}
+		if m.Name == "agent_sends_attempted" && m.Type == "counter" {
+			telemetry = true
 		}
 	}
 	s.Require().True(found)
+	s.Require().True(telemetry)
+}
+
+func (s *MockAgentSuite) TestAgentRelaysSidecarMetrics() {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	sidecarAddr := l.Addr().String()
+	s.Require().NoError(l.Close())
+
+	cfg := &configs.AgentConfig{
+		ServerAddress:  s.serverURL,
+		LogLevel:       "debug",
+		PollInterval:   1,
+		ReportInterval: 1,
+		NumWorkers:     1,
+		SidecarAddress: sidecarAddr,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx, cfg, logger.Initialize, apps.NewAgentApp, runners.NewRunContext, noReloads, runners.RunWorker)
+	}()
+
+	sidecarURL := "http://" + sidecarAddr
+	s.Require().Eventually(func() bool {
+		resp, err := http.Post(sidecarURL+"/update/counter/AppHits/2", "text/plain", nil)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	resp, err := http.Post(sidecarURL+"/updates/", "application/json",
+		strings.NewReader(`[{"id":"AppHits","type":"counter","delta":3},{"id":"AppTemp","type":"gauge","value":36.6}]`))
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(sidecarURL+"/update/unknown/AppHits/1", "text/plain", nil)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+
+	s.Require().Eventually(func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var hits int64
+		var temp bool
+		for _, m := range s.metrics {
+			switch {
+			case m.Type == "counter" && m.Name == "AppHits":
+				v, _ := strconv.ParseInt(m.Value, 10, 64)
+				hits += v
+			case m.Type == "gauge" && m.Name == "AppTemp" && m.Value == "36.6":
+				temp = true
+			}
+		}
+		return hits == 5 && temp
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func (s *MockAgentSuite) TestAgentReloadsConfig() {
+	var oldHits atomic.Int32
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		oldHits.Add(1)
+	}))
+	defer oldServer.Close()
+
+	cfg := &configs.AgentConfig{
+		ServerAddress:  oldServer.URL,
+		LogLevel:       "debug",
+		PollInterval:   1,
+		ReportInterval: 60,
+		NumWorkers:     1,
+		Collectors:     []string{"poll_count:name=ReloadPolls"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reloads := make(chan *configs.AgentConfig)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx, cfg, logger.Initialize, apps.NewAgentApp, runners.NewRunContext,
+			func(context.Context) <-chan *configs.AgentConfig { return reloads },
+			runners.RunWorker)
+	}()
+
+	// Let the agent buffer a few polls before switching servers.
+	time.Sleep(2500 * time.Millisecond)
+
+	reloaded := *cfg
+	reloaded.ServerAddress = s.serverURL
+	reloaded.ReportInterval = 1
+	reloaded.NumWorkers = 2
+	reloads <- &reloaded
+
+	s.Require().Eventually(func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, m := range s.metrics {
+			if m.Name == "ReloadPolls" && m.Value != "1" && m.Value != "0" {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 50*time.Millisecond, "polls buffered before the reload reach the new server")
+	s.Zero(oldHits.Load())
+
+	cancel()
+	<-done
+}
+
+func (s *MockAgentSuite) TestAgentReportsOverTLS() {
+	certFile, keyFile := testutils.SelfSignedCert(s.T(), s.T().TempDir())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	s.Require().NoError(err)
+
+	var hits atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	ts.StartTLS()
+	defer ts.Close()
+
+	cfg := &configs.AgentConfig{
+		ServerAddress:  strings.TrimPrefix(ts.URL, "https://"),
+		LogLevel:       "debug",
+		PollInterval:   1,
+		ReportInterval: 1,
+		NumWorkers:     1,
+		Collectors:     []string{"poll_count"},
+		TLSCAFile:      certFile,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx, cfg, logger.Initialize, apps.NewAgentApp, runners.NewRunContext, noReloads, runners.RunWorker)
+	}()
+
+	s.Require().Eventually(func() bool {
+		return hits.Load() > 0
+	}, 5*time.Second, 50*time.Millisecond, "the agent verifies the server against the CA bundle")
+
+	cancel()
+	<-done
+}
+
+func noReloads(context.Context) <-chan *configs.AgentConfig {
+	return nil
 }
 
 func TestMockAgentSuite(t *testing.T) {
@@ -10,21 +10,22 @@ func run(
 	ctx context.Context,
 	config *configs.AgentConfig,
 	loggerInitializeFunc func(level string) error,
-	newAgentFunc func(config *configs.AgentConfig) (func(ctx context.Context) error, error),
+	newAgentFunc func(config *configs.AgentConfig, reloads <-chan *configs.AgentConfig) (func(ctx context.Context) error, error),
 	newRunContextFunc func(ctx context.Context) (context.Context, context.CancelFunc),
+	newReloadsFunc func(ctx context.Context) <-chan *configs.AgentConfig,
 	runWorkerFunc func(ctx context.Context, worker func(ctx context.Context) error) error,
 ) error {
 	if err := loggerInitializeFunc(config.LogLevel); err != nil {
 		return err
 	}
 
-	worker, err := newAgentFunc(config)
+	ctx, cancel := newRunContextFunc(ctx)
+	defer cancel()
+
+	worker, err := newAgentFunc(config, newReloadsFunc(ctx))
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := newRunContextFunc(ctx)
-	defer cancel()
-
 	return runWorkerFunc(ctx, worker)
 }
@@ -29,7 +29,7 @@ func TestRun_Success(t *testing.T) {
 		return nil
 	}
 
-	newAgentFunc := func(c *configs.AgentConfig) (func(ctx context.Context) error, error) {
+	newAgentFunc := func(c *configs.AgentConfig, reloads <-chan *configs.AgentConfig) (func(ctx context.Context) error, error) {
 		assert.Equal(t, cfg, c)
 		return workerFunc, nil
 	}
@@ -40,6 +40,12 @@ func TestRun_Success(t *testing.T) {
 		return runCtx, func() { cancelCalled = true }
 	}
 
+	reloads := make(chan *configs.AgentConfig)
+	newReloadsFunc := func(ctx context.Context) <-chan *configs.AgentConfig {
+		assert.Equal(t, runCtx, ctx)
+		return reloads
+	}
+
 	runWorkerCalled := false
 	runWorkerFunc := func(ctx context.Context, worker func(ctx context.Context) error) error {
 		runWorkerCalled = true
@@ -48,7 +54,7 @@ func TestRun_Success(t *testing.T) {
 		return nil
 	}
 
-	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, runWorkerFunc)
+	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, newReloadsFunc, runWorkerFunc)
 	require.NoError(t, err)
 
 	assert.True(t, loggerCalled, "loggerInitializeFunc should be called")
@@ -66,7 +72,7 @@ func TestRun_LoggerError(t *testing.T) {
 		return wantErr
 	}
 
-	newAgentFunc := func(c *configs.AgentConfig) (func(ctx context.Context) error, error) {
+	newAgentFunc := func(c *configs.AgentConfig, reloads <-chan *configs.AgentConfig) (func(ctx context.Context) error, error) {
 		t.Fatal("newAgentFunc should not be called if logger fails")
 		return nil, nil
 	}
@@ -76,12 +82,17 @@ func TestRun_LoggerError(t *testing.T) {
 		return ctx, func() {}
 	}
 
+	newReloadsFunc := func(ctx context.Context) <-chan *configs.AgentConfig {
+		t.Fatal("newReloadsFunc should not be called if logger fails")
+		return nil
+	}
+
 	runWorkerFunc := func(ctx context.Context, worker func(ctx context.Context) error) error {
 		t.Fatal("runWorkerFunc should not be called if logger fails")
 		return nil
 	}
 
-	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, runWorkerFunc)
+	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, newReloadsFunc, runWorkerFunc)
 	assert.ErrorIs(t, err, wantErr)
 }
 
@@ -96,7 +107,7 @@ func TestRun_NewAgentFuncError(t *testing.T) {
 		return nil
 	}
 
-	newAgentFunc := func(c *configs.AgentConfig) (func(ctx context.Context) error, error) {
+	newAgentFunc := func(c *configs.AgentConfig, reloads <-chan *configs.AgentConfig) (func(ctx context.Context) error, error) {
 		assert.Equal(t, cfg, c)
 		return nil, wantErr
 	}
@@ -105,11 +116,15 @@ func TestRun_NewAgentFuncError(t *testing.T) {
 		return ctx, func() {}
 	}
 
+	newReloadsFunc := func(ctx context.Context) <-chan *configs.AgentConfig {
+		return nil
+	}
+
 	runWorkerFunc := func(ctx context.Context, worker func(ctx context.Context) error) error {
 		t.Fatal("runWorkerFunc should not be called when newAgentFunc fails")
 		return nil
 	}
 
-	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, runWorkerFunc)
+	err := run(ctx, cfg, loggerInitializeFunc, newAgentFunc, newRunContextFunc, newReloadsFunc, runWorkerFunc)
 	assert.ErrorIs(t, err, wantErr)
 }
@@ -3,23 +3,109 @@ package main
 import (
 	"flag"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 )
 
+// parseFlags builds the server configuration. Each setting comes from its
+// environment variable, else an explicitly set flag, else the JSON config
+// file named by -config or CONFIG, else the flag default.
 func parseFlags() (*configs.ServerConfig, error) {
 	fs := flag.NewFlagSet("server", flag.ExitOnError)
 
+	var configFlag string
+	fs.StringVar(&configFlag, "config", "", "path to a JSON config file, re-read on SIGHUP")
+
 	options := []configs.ServerOption{
 		withAddr(fs),
 		withLogLevel(fs),
+		withFileStoragePath(fs),
+		withStoreInterval(fs),
+		withRestore(fs),
+		withWALFsync(fs),
+		withWALFsyncInterval(fs),
+		withAdminToken(fs),
+		withStorageShards(fs),
+		withStreamBuffer(fs),
+		withStreamSlowPolicy(fs),
+		withStatsDAddress(fs),
+		withGraphiteAddress(fs),
+		withGraphiteCounterRules(fs),
+		withGraphiteReadTimeout(fs),
+		withInfluxCounterSuffixes(fs),
+		withForwardUpstreams(fs),
+		withForwardInterval(fs),
+		withForwardQueueSize(fs),
+		withReplicateFrom(fs),
+		withReplicationResyncInterval(fs),
+		withUpstreamToken(fs),
+		withUpstreamTLSCA(fs),
+		withUpstreamTLSCert(fs),
+		withUpstreamTLSKey(fs),
+		withTLSCert(fs),
+		withTLSKey(fs),
+		withTLSClientCA(fs),
+		withIdentityPrefixes(fs),
+		withTokensFile(fs),
+		withRateLimit(fs),
+		withRateBurst(fs),
+		withMaxInFlight(fs),
 	}
 
 	fs.Parse(os.Args[1:])
 
+	configPath := configFlag
+	if env := os.Getenv("CONFIG"); env != "" {
+		configPath = env
+	}
+	if configPath != "" {
+		if err := configs.ApplyFile(fs, configPath, serverConfigFileKeys); err != nil {
+			return nil, err
+		}
+	}
+
 	return configs.NewServerConfig(options...), nil
 }
 
+// serverConfigFileKeys maps config file keys to the flags they set.
+var serverConfigFileKeys = map[string]string{
+	"address":                   "a",
+	"log_level":                 "l",
+	"file_storage_path":         "f",
+	"store_interval":            "i",
+	"restore":                   "r",
+	"wal_fsync":                 "wal-fsync",
+	"wal_fsync_interval":        "wal-fsync-interval",
+	"admin_token":               "admin-token",
+	"storage_shards":            "shards",
+	"stream_buffer":             "stream-buffer",
+	"stream_slow_consumer":      "stream-slow-consumer",
+	"statsd_address":            "statsd-addr",
+	"graphite_address":          "graphite-addr",
+	"graphite_counters":         "graphite-counters",
+	"graphite_read_timeout":     "graphite-read-timeout",
+	"influx_counter_suffixes":   "influx-counter-suffixes",
+	"forward_to":                "forward-to",
+	"forward_interval":          "forward-interval",
+	"forward_queue_size":        "forward-queue-size",
+	"replicate_from":            "replicate-from",
+	"replicate_resync_interval": "replicate-resync-interval",
+	"upstream_token":            "upstream-token",
+	"upstream_tls_ca_file":      "upstream-tls-ca",
+	"upstream_tls_cert_file":    "upstream-tls-cert",
+	"upstream_tls_key_file":     "upstream-tls-key",
+	"tls_cert_file":             "tls-cert",
+	"tls_key_file":              "tls-key",
+	"tls_client_ca_file":        "tls-client-ca",
+	"identity_prefixes":         "identity-prefixes",
+	"tokens_file":               "tokens-file",
+	"rate_limit":                "rate-limit",
+	"rate_burst":                "rate-burst",
+	"max_in_flight":             "max-in-flight",
+}
+
 func withAddr(fs *flag.FlagSet) configs.ServerOption {
 	var addrFlag string
 	fs.StringVar(&addrFlag, "a", ":8080", "address and port to run server")
@@ -45,3 +131,446 @@ func withLogLevel(fs *flag.FlagSet) configs.ServerOption {
 		cfg.LogLevel = levelFlag
 	}
 }
+
+func withFileStoragePath(fs *flag.FlagSet) configs.ServerOption {
+	var pathFlag string
+	fs.StringVar(&pathFlag, "f", "", "metrics snapshot file path, write-ahead log is kept next to it (empty disables persistence)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("FILE_STORAGE_PATH"); env != "" {
+			cfg.FileStoragePath = env
+			return
+		}
+		cfg.FileStoragePath = pathFlag
+	}
+}
+
+func withStoreInterval(fs *flag.FlagSet) configs.ServerOption {
+	var intervalFlag int
+	fs.IntVar(&intervalFlag, "i", 300, "metrics snapshot interval in seconds (0 snapshots only on shutdown)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("STORE_INTERVAL"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v >= 0 {
+				cfg.StoreInterval = v
+				return
+			}
+		}
+		cfg.StoreInterval = intervalFlag
+	}
+}
+
+func withRestore(fs *flag.FlagSet) configs.ServerOption {
+	var restoreFlag bool
+	fs.BoolVar(&restoreFlag, "r", true, "restore metrics from snapshot and write-ahead log on startup")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("RESTORE"); env != "" {
+			if v, err := strconv.ParseBool(env); err == nil {
+				cfg.Restore = v
+				return
+			}
+		}
+		cfg.Restore = restoreFlag
+	}
+}
+
+func withWALFsync(fs *flag.FlagSet) configs.ServerOption {
+	var fsyncFlag string
+	fs.StringVar(&fsyncFlag, "wal-fsync", "always", "write-ahead log fsync policy: always, interval or none")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("WAL_FSYNC"); env != "" {
+			cfg.WALFsync = env
+			return
+		}
+		cfg.WALFsync = fsyncFlag
+	}
+}
+
+func withWALFsyncInterval(fs *flag.FlagSet) configs.ServerOption {
+	var intervalFlag int
+	fs.IntVar(&intervalFlag, "wal-fsync-interval", 1, "write-ahead log fsync interval in seconds for the interval policy")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("WAL_FSYNC_INTERVAL"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.WALFsyncInterval = v
+				return
+			}
+		}
+		cfg.WALFsyncInterval = intervalFlag
+	}
+}
+
+func withAdminToken(fs *flag.FlagSet) configs.ServerOption {
+	var tokenFlag string
+	fs.StringVar(&tokenFlag, "admin-token", "", "bearer token for /admin endpoints (empty disables them)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("ADMIN_TOKEN"); env != "" {
+			cfg.AdminToken = env
+			return
+		}
+		cfg.AdminToken = tokenFlag
+	}
+}
+
+func withStorageShards(fs *flag.FlagSet) configs.ServerOption {
+	var shardsFlag int
+	fs.IntVar(&shardsFlag, "shards", 1, "number of independently locked storage shards (1 disables sharding)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("STORAGE_SHARDS"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.StorageShards = v
+				return
+			}
+		}
+		cfg.StorageShards = shardsFlag
+	}
+}
+
+func withStreamBuffer(fs *flag.FlagSet) configs.ServerOption {
+	var bufferFlag int
+	fs.IntVar(&bufferFlag, "stream-buffer", 64, "number of pending events buffered per /stream client")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("STREAM_BUFFER"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.StreamBuffer = v
+				return
+			}
+		}
+		cfg.StreamBuffer = bufferFlag
+	}
+}
+
+func withStreamSlowPolicy(fs *flag.FlagSet) configs.ServerOption {
+	var policyFlag string
+	fs.StringVar(&policyFlag, "stream-slow-consumer", "drop", "what to do with a /stream client that falls behind: drop or disconnect")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("STREAM_SLOW_CONSUMER"); env != "" {
+			cfg.StreamSlowPolicy = env
+			return
+		}
+		cfg.StreamSlowPolicy = policyFlag
+	}
+}
+
+func withStatsDAddress(fs *flag.FlagSet) configs.ServerOption {
+	var addrFlag string
+	fs.StringVar(&addrFlag, "statsd-addr", "", "UDP address for the StatsD listener (empty disables it)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("STATSD_ADDRESS"); env != "" {
+			cfg.StatsDAddress = env
+			return
+		}
+		cfg.StatsDAddress = addrFlag
+	}
+}
+
+func withGraphiteAddress(fs *flag.FlagSet) configs.ServerOption {
+	var addrFlag string
+	fs.StringVar(&addrFlag, "graphite-addr", "", "TCP address for the Graphite plaintext listener (empty disables it)")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("GRAPHITE_ADDRESS"); env != "" {
+			cfg.GraphiteAddress = env
+			return
+		}
+		cfg.GraphiteAddress = addrFlag
+	}
+}
+
+func withGraphiteCounterRules(fs *flag.FlagSet) configs.ServerOption {
+	var rulesFlag string
+	fs.StringVar(&rulesFlag, "graphite-counters", "", "comma-separated dotted patterns of Graphite paths stored as counters")
+
+	return func(cfg *configs.ServerConfig) {
+		rules := rulesFlag
+		if env := os.Getenv("GRAPHITE_COUNTERS"); env != "" {
+			rules = env
+		}
+		if rules == "" {
+			cfg.GraphiteCounterRules = nil
+			return
+		}
+		cfg.GraphiteCounterRules = strings.Split(rules, ",")
+	}
+}
+
+func withGraphiteReadTimeout(fs *flag.FlagSet) configs.ServerOption {
+	var timeoutFlag int
+	fs.IntVar(&timeoutFlag, "graphite-read-timeout", 60, "seconds a Graphite connection may stay idle before it is closed")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("GRAPHITE_READ_TIMEOUT"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.GraphiteReadTimeout = v
+				return
+			}
+		}
+		cfg.GraphiteReadTimeout = timeoutFlag
+	}
+}
+
+func withInfluxCounterSuffixes(fs *flag.FlagSet) configs.ServerOption {
+	var suffixesFlag string
+	fs.StringVar(&suffixesFlag, "influx-counter-suffixes", "", "comma-separated Influx field suffixes stored as counters (e.g. _total,_count)")
+
+	return func(cfg *configs.ServerConfig) {
+		suffixes := suffixesFlag
+		if env := os.Getenv("INFLUX_COUNTER_SUFFIXES"); env != "" {
+			suffixes = env
+		}
+		if suffixes == "" {
+			cfg.InfluxCounterSuffixes = nil
+			return
+		}
+		cfg.InfluxCounterSuffixes = strings.Split(suffixes, ",")
+	}
+}
+
+func withForwardUpstreams(fs *flag.FlagSet) configs.ServerOption {
+	var upstreamsFlag string
+	fs.StringVar(&upstreamsFlag, "forward-to", "", "comma-separated addresses of upstream servers accepted updates are forwarded to")
+
+	return func(cfg *configs.ServerConfig) {
+		upstreams := upstreamsFlag
+		if env := os.Getenv("FORWARD_TO"); env != "" {
+			upstreams = env
+		}
+		if upstreams == "" {
+			cfg.ForwardUpstreams = nil
+			return
+		}
+		cfg.ForwardUpstreams = strings.Split(upstreams, ",")
+	}
+}
+
+func withForwardInterval(fs *flag.FlagSet) configs.ServerOption {
+	var intervalFlag int
+	fs.IntVar(&intervalFlag, "forward-interval", 10, "seconds between batches forwarded upstream")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("FORWARD_INTERVAL"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.ForwardInterval = v
+				return
+			}
+		}
+		cfg.ForwardInterval = intervalFlag
+	}
+}
+
+func withForwardQueueSize(fs *flag.FlagSet) configs.ServerOption {
+	var sizeFlag int
+	fs.IntVar(&sizeFlag, "forward-queue-size", 100, "batches buffered per upstream while it is unreachable")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("FORWARD_QUEUE_SIZE"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.ForwardQueueSize = v
+				return
+			}
+		}
+		cfg.ForwardQueueSize = sizeFlag
+	}
+}
+
+func withReplicateFrom(fs *flag.FlagSet) configs.ServerOption {
+	var leaderFlag string
+	fs.StringVar(&leaderFlag, "replicate-from", "", "leader address; when set the server runs as a read-only replica and proxies writes to it")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("REPLICATE_FROM"); env != "" {
+			cfg.ReplicateFrom = env
+			return
+		}
+		cfg.ReplicateFrom = leaderFlag
+	}
+}
+
+func withReplicationResyncInterval(fs *flag.FlagSet) configs.ServerOption {
+	var intervalFlag int
+	fs.IntVar(&intervalFlag, "replicate-resync-interval", 300, "seconds between full snapshot reloads on a replica")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("REPLICATE_RESYNC_INTERVAL"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v > 0 {
+				cfg.ReplicationResyncInterval = v
+				return
+			}
+		}
+		cfg.ReplicationResyncInterval = intervalFlag
+	}
+}
+
+func withUpstreamToken(fs *flag.FlagSet) configs.ServerOption {
+	var tokenFlag string
+	fs.StringVar(&tokenFlag, "upstream-token", "", "bearer token sent to -forward-to upstreams and used to replicate from the -replicate-from leader; proxied writes keep the client's token")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("UPSTREAM_TOKEN"); env != "" {
+			cfg.UpstreamToken = env
+			return
+		}
+		cfg.UpstreamToken = tokenFlag
+	}
+}
+
+func withUpstreamTLSCA(fs *flag.FlagSet) configs.ServerOption {
+	var caFlag string
+	fs.StringVar(&caFlag, "upstream-tls-ca", "", "PEM CA bundle used to verify the certificates of upstreams and the leader")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("UPSTREAM_TLS_CA_FILE"); env != "" {
+			cfg.UpstreamTLSCAFile = env
+			return
+		}
+		cfg.UpstreamTLSCAFile = caFlag
+	}
+}
+
+func withUpstreamTLSCert(fs *flag.FlagSet) configs.ServerOption {
+	var certFlag string
+	fs.StringVar(&certFlag, "upstream-tls-cert", "", "PEM client certificate presented to upstreams and the leader when they require one")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("UPSTREAM_TLS_CERT_FILE"); env != "" {
+			cfg.UpstreamTLSCertFile = env
+			return
+		}
+		cfg.UpstreamTLSCertFile = certFlag
+	}
+}
+
+func withUpstreamTLSKey(fs *flag.FlagSet) configs.ServerOption {
+	var keyFlag string
+	fs.StringVar(&keyFlag, "upstream-tls-key", "", "PEM private key file for -upstream-tls-cert")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("UPSTREAM_TLS_KEY_FILE"); env != "" {
+			cfg.UpstreamTLSKeyFile = env
+			return
+		}
+		cfg.UpstreamTLSKeyFile = keyFlag
+	}
+}
+
+func withTLSCert(fs *flag.FlagSet) configs.ServerOption {
+	var certFlag string
+	fs.StringVar(&certFlag, "tls-cert", "", "PEM certificate file; with -tls-key the server serves HTTPS")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("TLS_CERT_FILE"); env != "" {
+			cfg.TLSCertFile = env
+			return
+		}
+		cfg.TLSCertFile = certFlag
+	}
+}
+
+func withTLSKey(fs *flag.FlagSet) configs.ServerOption {
+	var keyFlag string
+	fs.StringVar(&keyFlag, "tls-key", "", "PEM private key file for -tls-cert")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("TLS_KEY_FILE"); env != "" {
+			cfg.TLSKeyFile = env
+			return
+		}
+		cfg.TLSKeyFile = keyFlag
+	}
+}
+
+func withTLSClientCA(fs *flag.FlagSet) configs.ServerOption {
+	var caFlag string
+	fs.StringVar(&caFlag, "tls-client-ca", "", "PEM CA bundle; when set, clients must present a certificate it signed")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("TLS_CLIENT_CA_FILE"); env != "" {
+			cfg.TLSClientCAFile = env
+			return
+		}
+		cfg.TLSClientCAFile = caFlag
+	}
+}
+
+func withIdentityPrefixes(fs *flag.FlagSet) configs.ServerOption {
+	var prefixesFlag string
+	fs.StringVar(&prefixesFlag, "identity-prefixes", "", "comma-separated identity=prefix entries restricting the metric names each client certificate may write (not allowed with -replicate-from)")
+
+	return func(cfg *configs.ServerConfig) {
+		prefixes := prefixesFlag
+		if env := os.Getenv("IDENTITY_PREFIXES"); env != "" {
+			prefixes = env
+		}
+		if prefixes == "" {
+			cfg.IdentityPrefixes = nil
+			return
+		}
+		cfg.IdentityPrefixes = strings.Split(prefixes, ",")
+	}
+}
+
+func withTokensFile(fs *flag.FlagSet) configs.ServerOption {
+	var pathFlag string
+	fs.StringVar(&pathFlag, "tokens-file", "", "JSON file of API tokens (name, hex SHA-256, scopes); when set, every route needs a bearer token; re-read on SIGHUP")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("TOKENS_FILE"); env != "" {
+			cfg.TokensFile = env
+			return
+		}
+		cfg.TokensFile = pathFlag
+	}
+}
+
+func withRateLimit(fs *flag.FlagSet) configs.ServerOption {
+	var rateLimitFlag int
+	fs.IntVar(&rateLimitFlag, "rate-limit", 0, "requests per second allowed per client certificate identity or IP (0 disables); re-read on SIGHUP")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("RATE_LIMIT"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v >= 0 {
+				cfg.RateLimit = v
+				return
+			}
+		}
+		cfg.RateLimit = rateLimitFlag
+	}
+}
+
+func withRateBurst(fs *flag.FlagSet) configs.ServerOption {
+	var rateBurstFlag int
+	fs.IntVar(&rateBurstFlag, "rate-burst", 0, "requests a client may send at once above -rate-limit (defaults to -rate-limit); re-read on SIGHUP")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("RATE_BURST"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v >= 0 {
+				cfg.RateBurst = v
+				return
+			}
+		}
+		cfg.RateBurst = rateBurstFlag
+	}
+}
+
+func withMaxInFlight(fs *flag.FlagSet) configs.ServerOption {
+	var maxInFlightFlag int
+	fs.IntVar(&maxInFlightFlag, "max-in-flight", 0, "requests served at once before the rest get 503 (0 disables); re-read on SIGHUP")
+
+	return func(cfg *configs.ServerConfig) {
+		if env := os.Getenv("MAX_IN_FLIGHT"); env != "" {
+			if v, err := strconv.Atoi(env); err == nil && v >= 0 {
+				cfg.MaxInFlight = v
+				return
+			}
+		}
+		cfg.MaxInFlight = maxInFlightFlag
+	}
+}
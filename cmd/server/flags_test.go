@@ -3,10 +3,12 @@ package main
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseFlags(t *testing.T) {
@@ -81,6 +83,38 @@ func TestParseFlags(t *testing.T) {
 	}
 }
 
+func TestParseFlags_ConfigFile(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	path := filepath.Join(t.TempDir(), "server.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"address": ":7000",
+		"log_level": "debug",
+		"store_interval": 0,
+		"restore": false,
+		"admin_token": "file-secret",
+		"forward_to": ["up1:8080", "up2:8080"]
+	}`), 0o600))
+
+	os.Args = []string{"cmd", "-config", path, "-a", ":9090"}
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := parseFlags()
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.Address, "explicit flags win over the file")
+	assert.Equal(t, "warn", cfg.LogLevel, "env wins over the file")
+	assert.Equal(t, 0, cfg.StoreInterval)
+	assert.False(t, cfg.Restore)
+	assert.Equal(t, "file-secret", cfg.AdminToken)
+	assert.Equal(t, []string{"up1:8080", "up2:8080"}, cfg.ForwardUpstreams)
+	assert.Equal(t, 1, cfg.StorageShards, "missing keys keep flag defaults")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"storage_shards": "many"}`), 0o600))
+	_, err = parseFlags()
+	assert.Error(t, err)
+}
+
 func TestWithAddr(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -170,3 +204,554 @@ func TestWithLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestWithStorageOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagArgs []string
+		env      map[string]string
+		want     configs.ServerConfig
+	}{
+		{
+			name:     "defaults",
+			flagArgs: []string{},
+			want: configs.ServerConfig{
+				StoreInterval:    300,
+				Restore:          true,
+				WALFsync:         "always",
+				WALFsyncInterval: 1,
+			},
+		},
+		{
+			name:     "flags",
+			flagArgs: []string{"-f", "/tmp/flag.json", "-i", "10", "-r=false", "-wal-fsync", "interval", "-wal-fsync-interval", "5"},
+			want: configs.ServerConfig{
+				FileStoragePath:  "/tmp/flag.json",
+				StoreInterval:    10,
+				Restore:          false,
+				WALFsync:         "interval",
+				WALFsyncInterval: 5,
+			},
+		},
+		{
+			name:     "env overrides flags",
+			flagArgs: []string{"-f", "/tmp/flag.json", "-i", "10", "-r=false", "-wal-fsync", "interval"},
+			env: map[string]string{
+				"FILE_STORAGE_PATH":  "/tmp/env.json",
+				"STORE_INTERVAL":     "0",
+				"RESTORE":            "true",
+				"WAL_FSYNC":          "none",
+				"WAL_FSYNC_INTERVAL": "3",
+			},
+			want: configs.ServerConfig{
+				FileStoragePath:  "/tmp/env.json",
+				StoreInterval:    0,
+				Restore:          true,
+				WALFsync:         "none",
+				WALFsyncInterval: 3,
+			},
+		},
+		{
+			name:     "invalid env falls back to flags",
+			flagArgs: []string{"-i", "10", "-r=false"},
+			env: map[string]string{
+				"STORE_INTERVAL":     "bad",
+				"RESTORE":            "bad",
+				"WAL_FSYNC_INTERVAL": "bad",
+			},
+			want: configs.ServerConfig{
+				StoreInterval:    10,
+				Restore:          false,
+				WALFsync:         "always",
+				WALFsyncInterval: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"FILE_STORAGE_PATH", "STORE_INTERVAL", "RESTORE", "WAL_FSYNC", "WAL_FSYNC_INTERVAL"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withFileStoragePath(fs),
+				withStoreInterval(fs),
+				withRestore(fs),
+				withWALFsync(fs),
+				withWALFsyncInterval(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.want, *cfg)
+		})
+	}
+}
+
+func TestWithAdminToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		flagArgs  []string
+		envToken  string
+		wantToken string
+	}{
+		{"default disabled", []string{}, "", ""},
+		{"flag only", []string{"-admin-token", "flag-secret"}, "", "flag-secret"},
+		{"env overrides flag", []string{"-admin-token", "flag-secret"}, "env-secret", "env-secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_TOKEN", tt.envToken)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opt := withAdminToken(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			opt(cfg)
+			assert.Equal(t, tt.wantToken, cfg.AdminToken)
+		})
+	}
+}
+
+func TestWithStorageShards(t *testing.T) {
+	tests := []struct {
+		name       string
+		flagArgs   []string
+		envShards  string
+		wantShards int
+	}{
+		{"default", []string{}, "", 1},
+		{"flag only", []string{"-shards", "16"}, "", 16},
+		{"env overrides flag", []string{"-shards", "16"}, "32", 32},
+		{"invalid env falls back to flag", []string{"-shards", "16"}, "0", 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORAGE_SHARDS", tt.envShards)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opt := withStorageShards(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			opt(cfg)
+			assert.Equal(t, tt.wantShards, cfg.StorageShards)
+		})
+	}
+}
+
+func TestWithStreamOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		flagArgs   []string
+		envBuffer  string
+		envPolicy  string
+		wantBuffer int
+		wantPolicy string
+	}{
+		{"defaults", []string{}, "", "", 64, "drop"},
+		{"flags", []string{"-stream-buffer", "8", "-stream-slow-consumer", "disconnect"}, "", "", 8, "disconnect"},
+		{"env overrides flags", []string{"-stream-buffer", "8"}, "16", "disconnect", 16, "disconnect"},
+		{"invalid env buffer falls back to flag", []string{"-stream-buffer", "8"}, "-1", "", 8, "drop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STREAM_BUFFER", tt.envBuffer)
+			t.Setenv("STREAM_SLOW_CONSUMER", tt.envPolicy)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			bufferOpt := withStreamBuffer(fs)
+			policyOpt := withStreamSlowPolicy(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			bufferOpt(cfg)
+			policyOpt(cfg)
+			assert.Equal(t, tt.wantBuffer, cfg.StreamBuffer)
+			assert.Equal(t, tt.wantPolicy, cfg.StreamSlowPolicy)
+		})
+	}
+}
+
+func TestWithStatsDAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagArgs []string
+		envAddr  string
+		wantAddr string
+	}{
+		{"disabled by default", []string{}, "", ""},
+		{"flag only", []string{"-statsd-addr", ":8125"}, "", ":8125"},
+		{"env overrides flag", []string{"-statsd-addr", ":8125"}, ":9125", ":9125"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STATSD_ADDRESS", tt.envAddr)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opt := withStatsDAddress(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			opt(cfg)
+			assert.Equal(t, tt.wantAddr, cfg.StatsDAddress)
+		})
+	}
+}
+
+func TestWithGraphiteOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		flagArgs    []string
+		envAddr     string
+		envRules    string
+		envTimeout  string
+		wantAddr    string
+		wantRules   []string
+		wantTimeout int
+	}{
+		{"defaults", []string{}, "", "", "", "", nil, 60},
+		{
+			"flags",
+			[]string{"-graphite-addr", ":2003", "-graphite-counters", "*.requests,app.*.hits", "-graphite-read-timeout", "5"},
+			"", "", "",
+			":2003", []string{"*.requests", "app.*.hits"}, 5,
+		},
+		{
+			"env overrides flags",
+			[]string{"-graphite-addr", ":2003", "-graphite-counters", "*.requests"},
+			":2004", "stats.*", "10",
+			":2004", []string{"stats.*"}, 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GRAPHITE_ADDRESS", tt.envAddr)
+			t.Setenv("GRAPHITE_COUNTERS", tt.envRules)
+			t.Setenv("GRAPHITE_READ_TIMEOUT", tt.envTimeout)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withGraphiteAddress(fs),
+				withGraphiteCounterRules(fs),
+				withGraphiteReadTimeout(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantAddr, cfg.GraphiteAddress)
+			assert.Equal(t, tt.wantRules, cfg.GraphiteCounterRules)
+			assert.Equal(t, tt.wantTimeout, cfg.GraphiteReadTimeout)
+		})
+	}
+}
+
+func TestWithInfluxCounterSuffixes(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagArgs     []string
+		envSuffixes  string
+		wantSuffixes []string
+	}{
+		{"default", []string{}, "", nil},
+		{"flag only", []string{"-influx-counter-suffixes", "_total,_count"}, "", []string{"_total", "_count"}},
+		{"env overrides flag", []string{"-influx-counter-suffixes", "_total"}, "_sum", []string{"_sum"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("INFLUX_COUNTER_SUFFIXES", tt.envSuffixes)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opt := withInfluxCounterSuffixes(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			opt(cfg)
+			assert.Equal(t, tt.wantSuffixes, cfg.InfluxCounterSuffixes)
+		})
+	}
+}
+
+func TestWithForward(t *testing.T) {
+	tests := []struct {
+		name          string
+		flagArgs      []string
+		envUpstreams  string
+		envInterval   string
+		envQueueSize  string
+		wantUpstreams []string
+		wantInterval  int
+		wantQueueSize int
+	}{
+		{"defaults", []string{}, "", "", "", nil, 10, 100},
+		{
+			"flags only",
+			[]string{"-forward-to", "central:8080,backup:8080", "-forward-interval", "5", "-forward-queue-size", "20"},
+			"", "", "",
+			[]string{"central:8080", "backup:8080"}, 5, 20,
+		},
+		{
+			"env overrides flags",
+			[]string{"-forward-to", "central:8080", "-forward-interval", "5", "-forward-queue-size", "20"},
+			"dc2:8080", "30", "500",
+			[]string{"dc2:8080"}, 30, 500,
+		},
+		{
+			"invalid env falls back to flags",
+			[]string{"-forward-interval", "5", "-forward-queue-size", "20"},
+			"", "soon", "-1",
+			nil, 5, 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FORWARD_TO", tt.envUpstreams)
+			t.Setenv("FORWARD_INTERVAL", tt.envInterval)
+			t.Setenv("FORWARD_QUEUE_SIZE", tt.envQueueSize)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withForwardUpstreams(fs),
+				withForwardInterval(fs),
+				withForwardQueueSize(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantUpstreams, cfg.ForwardUpstreams)
+			assert.Equal(t, tt.wantInterval, cfg.ForwardInterval)
+			assert.Equal(t, tt.wantQueueSize, cfg.ForwardQueueSize)
+		})
+	}
+}
+
+func TestWithReplication(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagArgs     []string
+		envLeader    string
+		envResync    string
+		wantLeader   string
+		wantInterval int
+	}{
+		{"defaults", []string{}, "", "", "", 300},
+		{"flags only", []string{"-replicate-from", "leader:8080", "-replicate-resync-interval", "60"}, "", "", "leader:8080", 60},
+		{"env overrides flags", []string{"-replicate-from", "leader:8080", "-replicate-resync-interval", "60"}, "dc1:8080", "30", "dc1:8080", 30},
+		{"invalid env interval falls back to flag", []string{"-replicate-resync-interval", "60"}, "", "0", "", 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REPLICATE_FROM", tt.envLeader)
+			t.Setenv("REPLICATE_RESYNC_INTERVAL", tt.envResync)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withReplicateFrom(fs),
+				withReplicationResyncInterval(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantLeader, cfg.ReplicateFrom)
+			assert.Equal(t, tt.wantInterval, cfg.ReplicationResyncInterval)
+		})
+	}
+}
+
+func TestWithUpstreamOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		flagArgs  []string
+		envToken  string
+		envCA     string
+		wantToken string
+		wantCA    string
+		wantCert  string
+		wantKey   string
+	}{
+		{"defaults", []string{}, "", "", "", "", "", ""},
+		{
+			"flags only",
+			[]string{"-upstream-token", "edge", "-upstream-tls-ca", "ca.crt", "-upstream-tls-cert", "edge.crt", "-upstream-tls-key", "edge.key"},
+			"", "", "edge", "ca.crt", "edge.crt", "edge.key",
+		},
+		{"env overrides flags", []string{"-upstream-token", "edge", "-upstream-tls-ca", "ca.crt"}, "secret", "/etc/tls/ca.crt", "secret", "/etc/tls/ca.crt", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UPSTREAM_TOKEN", tt.envToken)
+			t.Setenv("UPSTREAM_TLS_CA_FILE", tt.envCA)
+			t.Setenv("UPSTREAM_TLS_CERT_FILE", "")
+			t.Setenv("UPSTREAM_TLS_KEY_FILE", "")
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withUpstreamToken(fs),
+				withUpstreamTLSCA(fs),
+				withUpstreamTLSCert(fs),
+				withUpstreamTLSKey(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantToken, cfg.UpstreamToken)
+			assert.Equal(t, tt.wantCA, cfg.UpstreamTLSCAFile)
+			assert.Equal(t, tt.wantCert, cfg.UpstreamTLSCertFile)
+			assert.Equal(t, tt.wantKey, cfg.UpstreamTLSKeyFile)
+		})
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagArgs []string
+		envCert  string
+		envKey   string
+		wantCert string
+		wantKey  string
+	}{
+		{"defaults", []string{}, "", "", "", ""},
+		{"flags only", []string{"-tls-cert", "server.crt", "-tls-key", "server.key"}, "", "", "server.crt", "server.key"},
+		{"env overrides flags", []string{"-tls-cert", "server.crt", "-tls-key", "server.key"}, "/etc/tls/tls.crt", "/etc/tls/tls.key", "/etc/tls/tls.crt", "/etc/tls/tls.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TLS_CERT_FILE", tt.envCert)
+			t.Setenv("TLS_KEY_FILE", tt.envKey)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withTLSCert(fs),
+				withTLSKey(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantCert, cfg.TLSCertFile)
+			assert.Equal(t, tt.wantKey, cfg.TLSKeyFile)
+		})
+	}
+}
+
+func TestWithClientAuth(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagArgs     []string
+		envCA        string
+		envPrefixes  string
+		wantCA       string
+		wantPrefixes []string
+	}{
+		{"defaults", []string{}, "", "", "", nil},
+		{
+			"flags only",
+			[]string{"-tls-client-ca", "clients.crt", "-identity-prefixes", "agent-a=app.,agent-b=db."},
+			"", "",
+			"clients.crt", []string{"agent-a=app.", "agent-b=db."},
+		},
+		{
+			"env overrides flags",
+			[]string{"-tls-client-ca", "clients.crt", "-identity-prefixes", "agent-a=app."},
+			"/etc/tls/clients.crt", "agent-c=cpu",
+			"/etc/tls/clients.crt", []string{"agent-c=cpu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TLS_CLIENT_CA_FILE", tt.envCA)
+			t.Setenv("IDENTITY_PREFIXES", tt.envPrefixes)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withTLSClientCA(fs),
+				withIdentityPrefixes(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantCA, cfg.TLSClientCAFile)
+			assert.Equal(t, tt.wantPrefixes, cfg.IdentityPrefixes)
+		})
+	}
+}
+
+func TestWithTokensFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagArgs []string
+		envPath  string
+		wantPath string
+	}{
+		{"default disabled", []string{}, "", ""},
+		{"flag only", []string{"-tokens-file", "tokens.json"}, "", "tokens.json"},
+		{"env overrides flag", []string{"-tokens-file", "tokens.json"}, "/etc/metrics/tokens.json", "/etc/metrics/tokens.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TOKENS_FILE", tt.envPath)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opt := withTokensFile(fs)
+			fs.Parse(tt.flagArgs)
+
+			cfg := &configs.ServerConfig{}
+			opt(cfg)
+			assert.Equal(t, tt.wantPath, cfg.TokensFile)
+		})
+	}
+}
+
+func TestWithLoadLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		flagArgs        []string
+		envRate         string
+		envBurst        string
+		envMaxInFlight  string
+		wantRate        int
+		wantBurst       int
+		wantMaxInFlight int
+	}{
+		{"defaults disabled", []string{}, "", "", "", 0, 0, 0},
+		{"flags only", []string{"-rate-limit", "50", "-rate-burst", "100", "-max-in-flight", "200"}, "", "", "", 50, 100, 200},
+		{"env overrides flags", []string{"-rate-limit", "50", "-rate-burst", "100", "-max-in-flight", "200"}, "0", "20", "400", 0, 20, 400},
+		{"invalid env falls back to flags", []string{"-rate-limit", "50", "-rate-burst", "100", "-max-in-flight", "200"}, "fast", "-1", "many", 50, 100, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RATE_LIMIT", tt.envRate)
+			t.Setenv("RATE_BURST", tt.envBurst)
+			t.Setenv("MAX_IN_FLIGHT", tt.envMaxInFlight)
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			opts := []configs.ServerOption{
+				withRateLimit(fs),
+				withRateBurst(fs),
+				withMaxInFlight(fs),
+			}
+			fs.Parse(tt.flagArgs)
+
+			cfg := configs.NewServerConfig(opts...)
+			assert.Equal(t, tt.wantRate, cfg.RateLimit)
+			assert.Equal(t, tt.wantBurst, cfg.RateBurst)
+			assert.Equal(t, tt.wantMaxInFlight, cfg.MaxInFlight)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/apps"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
 )
@@ -20,9 +21,15 @@ func main() {
 		logger.Initialize,
 		apps.NewServerApp,
 		runners.NewRunContext,
+		newConfigReloads,
 		runners.RunServer,
 	)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// newConfigReloads re-reads flags, env and the config file on every SIGHUP.
+func newConfigReloads(ctx context.Context) <-chan *configs.ServerConfig {
+	return runners.WatchReloads(ctx, runners.NotifyReload(ctx), parseFlags)
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/apps"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -30,11 +37,11 @@ func (s *UpdateMetricSuite) SetupSuite() {
 	err := logger.Initialize(config.LogLevel)
 	s.Require().NoError(err)
 
-	srv, err := apps.NewServerApp(config)
+	app, err := apps.NewServerApp(config)
 	s.Require().NoError(err)
 
 	// Start httptest server with the app's handler
-	ts := httptest.NewServer(srv.Handler)
+	ts := httptest.NewServer(app.Server.Handler)
 	s.T().Cleanup(ts.Close)
 
 	s.serverURL = ts.URL
@@ -142,6 +149,527 @@ func (s *UpdateMetricSuite) TestMetricsListHandler() {
 	s.Contains(resp.String(), "<html>", "Expected HTML response body")
 }
 
+func (s *UpdateMetricSuite) TestMetricsExport() {
+	resp, err := s.client.R().Post("/update/counter/ExportHits/4")
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode())
+
+	resp, err = s.client.R().SetHeader("Accept", "text/csv").Get("/export")
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode())
+	s.Equal("text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	s.True(strings.HasPrefix(resp.String(), "id,type,delta,value,updated_at\n"))
+	s.Contains(resp.String(), "\nExportHits,counter,4,,")
+
+	resp, err = s.client.R().Get("/export?format=ndjson")
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode())
+	s.Contains(resp.String(), `{"id":"ExportHits","type":"counter","delta":4,`)
+
+	resp, err = s.client.R().Get("/export?format=xml")
+	s.Require().NoError(err)
+	s.Equal(http.StatusBadRequest, resp.StatusCode())
+}
+
+func (s *UpdateMetricSuite) TestMetricsDashboardFilters() {
+	for _, url := range []string{"/update/gauge/DashHeap/1", "/update/counter/DashHeap/2", "/update/gauge/DashStack/3"} {
+		resp, err := s.client.R().Post(url)
+		s.Require().NoError(err)
+		s.Require().Equal(http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err := s.client.R().Get("/?type=gauge&q=dashheap")
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode())
+	s.Contains(resp.String(), `data-name="DashHeap" data-type="gauge"`)
+	s.NotContains(resp.String(), `data-name="DashHeap" data-type="counter"`)
+	s.NotContains(resp.String(), `data-name="DashStack"`)
+
+	resp, err = s.client.R().Get("/?type=histogram")
+	s.Require().NoError(err)
+	s.Equal(http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = s.client.R().Get("/static/dashboard.js")
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode())
+}
+
+func (s *UpdateMetricSuite) TestMetricsQueryHandler() {
+	for _, url := range []string{
+		"/update/gauge/QueryHeapAlloc/10",
+		"/update/gauge/QueryHeapSys/30",
+		"/update/counter/QueryHeapObjects/5",
+	} {
+		resp, err := s.client.R().
+			SetContext(context.Background()).
+			SetHeader("Content-Type", "text/plain").
+			Post(url)
+		s.Require().NoError(err)
+		s.Require().Equal(http.StatusOK, resp.StatusCode())
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Sum over pattern", "/query?match=QueryHeap*&agg=sum", http.StatusOK, `[{"agg":"sum","value":45,"count":3}]`},
+		{"Max grouped by type", "/query?match=QueryHeap*&agg=max&by=type", http.StatusOK, `[{"group":"counter","agg":"max","value":5,"count":1},{"group":"gauge","agg":"max","value":30,"count":2}]`},
+		{"Invalid aggregation", "/query?match=QueryHeap*&agg=median", http.StatusBadRequest, "invalid query aggregation"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			resp, err := s.client.R().
+				SetContext(context.Background()).
+				Get(tt.url)
+
+			s.Require().NoError(err, "HTTP error in request for URL: %s", tt.url)
+			s.Equal(tt.expectedStatus, resp.StatusCode(), "Unexpected status for %s", tt.url)
+			s.Contains(resp.String(), tt.expectedBody)
+		})
+	}
+}
+
 func TestUpdateMetricSuite(t *testing.T) {
 	suite.Run(t, new(UpdateMetricSuite))
 }
+
+func TestAdminSnapshotRestoreMigration(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	newServer := func() *resty.Client {
+		app, err := apps.NewServerApp(&configs.ServerConfig{
+			Address:    ":0",
+			AdminToken: "secret",
+		})
+		require.NoError(t, err)
+
+		ts := httptest.NewServer(app.Server.Handler)
+		t.Cleanup(ts.Close)
+
+		return resty.New().SetBaseURL(ts.URL)
+	}
+
+	source := newServer()
+	target := newServer()
+
+	for _, url := range []string{"/update/counter/requests/100", "/update/counter/requests/50", "/update/gauge/temperature/42.5"} {
+		resp, err := source.R().Post(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err := source.R().Get("/admin/snapshot?format=gzip")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+
+	resp, err = source.R().SetAuthToken("secret").Get("/admin/snapshot?format=gzip")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	dump := resp.Body()
+
+	resp, err = target.R().SetAuthToken("secret").SetBody(dump).Post("/admin/restore")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = target.R().Get("/value/counter/requests")
+	require.NoError(t, err)
+	assert.Equal(t, "150", resp.String())
+
+	resp, err = target.R().Get("/value/gauge/temperature")
+	require.NoError(t, err)
+	assert.Equal(t, "42.5", resp.String())
+}
+
+func TestMetricsStream(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	app, err := apps.NewServerApp(&configs.ServerConfig{Address: ":0"})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(app.Server.Handler)
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/stream?type=counter", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+
+	client := resty.New().SetBaseURL(ts.URL)
+	for _, url := range []string{"/update/gauge/temperature/42.5", "/update/counter/requests/5", "/update/counter/requests/7"} {
+		r, err := client.R().Post(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, r.StatusCode())
+	}
+
+	var events []string
+	for len(events) < 2 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimSpace(strings.TrimPrefix(line, "data: ")))
+		}
+	}
+
+	assert.Contains(t, events[0], `"saved":{"id":"requests","type":"counter","delta":5,`)
+	assert.Contains(t, events[1], `"update":{"id":"requests","type":"counter","delta":7}`)
+	assert.Contains(t, events[1], `"saved":{"id":"requests","type":"counter","delta":12,`)
+}
+
+func TestMetricsExportImportRoundTrip(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	newServer := func() *resty.Client {
+		app, err := apps.NewServerApp(&configs.ServerConfig{Address: ":0"})
+		require.NoError(t, err)
+
+		ts := httptest.NewServer(app.Server.Handler)
+		t.Cleanup(ts.Close)
+
+		return resty.New().SetBaseURL(ts.URL)
+	}
+
+	source := newServer()
+	target := newServer()
+
+	for _, url := range []string{"/update/counter/requests/100", "/update/gauge/temperature/42.5"} {
+		resp, err := source.R().Post(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err := target.R().Post("/update/counter/requests/1")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = source.R().Get("/export?format=csv")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	backup := resp.Body()
+
+	resp, err = target.R().
+		SetHeader("Content-Type", "text/csv").
+		SetBody(append(backup, []byte("broken,counter,x,,\n")...)).
+		Post("/import?mode=merge")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.JSONEq(t, `{"mode":"merge","format":"csv","accepted":2,"rejected":[{"row":3,"id":"broken","reason":"delta: strconv.ParseInt: parsing \"x\": invalid syntax"}]}`, resp.String())
+
+	resp, err = target.R().Get("/value/counter/requests")
+	require.NoError(t, err)
+	assert.Equal(t, "101", resp.String())
+
+	resp, err = target.R().SetHeader("Content-Type", "text/csv").SetBody(backup).Post("/import?mode=replace")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = target.R().Get("/value/counter/requests")
+	require.NoError(t, err)
+	assert.Equal(t, "100", resp.String())
+
+	resp, err = target.R().Get("/value/gauge/temperature")
+	require.NoError(t, err)
+	assert.Equal(t, "42.5", resp.String())
+}
+
+func TestStatsDIngestion(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	probe, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	statsdAddr := probe.LocalAddr().String()
+	require.NoError(t, probe.Close())
+
+	app, err := apps.NewServerApp(&configs.ServerConfig{
+		Address:       "127.0.0.1:0",
+		StatsDAddress: statsdAddr,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runners.RunServer(ctx, app.Server, app.Workers...) }()
+
+	ts := httptest.NewServer(app.Server.Handler)
+	t.Cleanup(ts.Close)
+	client := resty.New().SetBaseURL(ts.URL)
+
+	conn, err := net.Dial("udp", statsdAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		conn.Write([]byte("statsd_hits:1|c|@0.5\nnot a metric"))
+		resp, err := client.R().Get("/value/counter/statsd_hits")
+		return err == nil && resp.StatusCode() == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestGraphiteIngestion(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	graphiteAddr := probe.Addr().String()
+	require.NoError(t, probe.Close())
+
+	app, err := apps.NewServerApp(&configs.ServerConfig{
+		Address:              "127.0.0.1:0",
+		GraphiteAddress:      graphiteAddr,
+		GraphiteCounterRules: []string{"*.requests"},
+		GraphiteReadTimeout:  60,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runners.RunServer(ctx, app.Server, app.Workers...) }()
+
+	ts := httptest.NewServer(app.Server.Handler)
+	t.Cleanup(ts.Close)
+	client := resty.New().SetBaseURL(ts.URL)
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", graphiteAddr)
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+
+	_, err = fmt.Fprint(conn, "web.requests 2 1700000000\nweb.requests 3 1700000000\nweb.load 0.5 -1\n")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		resp, err := client.R().Get("/value/gauge/web.load")
+		return err == nil && resp.StatusCode() == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	resp, err := client.R().Get("/value/counter/web.requests")
+	require.NoError(t, err)
+	assert.Equal(t, "5", resp.String())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestInfluxWrite(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	app, err := apps.NewServerApp(&configs.ServerConfig{
+		Address:               ":0",
+		InfluxCounterSuffixes: []string{"_total"},
+	})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(app.Server.Handler)
+	t.Cleanup(ts.Close)
+	client := resty.New().SetBaseURL(ts.URL)
+
+	body := "http,host=web1,method=GET requests_total=3i,latency=0.25 1700000000000000000\n" +
+		"http,host=web1,method=GET requests_total=2i 1700000001000000000\n" +
+		"broken line\n"
+
+	resp, err := client.R().SetBody(body).Post("/write?precision=ns")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	assert.Contains(t, resp.String(), `"line":3`)
+
+	resp, err = client.R().Get("/value/counter/" + url.PathEscape("http_requests_total{host=web1,method=GET}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "5", resp.String())
+
+	resp, err = client.R().Get("/value/gauge/" + url.PathEscape("http_latency{host=web1,method=GET}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "0.25", resp.String())
+}
+
+func TestOTLPReceiver(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	app, err := apps.NewServerApp(&configs.ServerConfig{Address: ":0"})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(app.Server.Handler)
+	t.Cleanup(ts.Close)
+	client := resty.New().SetBaseURL(ts.URL)
+
+	body := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+		"scopeMetrics":[{"metrics":[
+			{"name":"http.requests","sum":{"isMonotonic":true,"aggregationTemporality":1,"dataPoints":[{"asInt":"3"}]}},
+			{"name":"queue.depth","gauge":{"dataPoints":[{"asDouble":4.5}]}},
+			{"name":"http.duration","histogram":{"aggregationTemporality":1,"dataPoints":[{"count":"2","sum":0.3}]}},
+			{"name":"bytes","sum":{"isMonotonic":true,"aggregationTemporality":2,"dataPoints":[{"asInt":"100"}]}}
+		]}]}]}`
+
+	for range 2 {
+		resp, err := client.R().SetHeader("Content-Type", "application/json").SetBody(body).Post("/v1/metrics")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Contains(t, resp.String(), `"rejectedDataPoints":"1"`)
+	}
+
+	resp, err := client.R().Get("/value/counter/" + url.PathEscape("http.requests{service.name=checkout}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "6", resp.String())
+
+	resp, err = client.R().Get("/value/gauge/" + url.PathEscape("queue.depth{service.name=checkout}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "4.5", resp.String())
+
+	resp, err = client.R().Get("/value/counter/" + url.PathEscape("http.duration_count{service.name=checkout}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "4", resp.String())
+
+	resp, err = client.R().Get("/value/counter/" + url.PathEscape("bytes{service.name=checkout}"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+}
+
+func TestForwarding(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	central, err := apps.NewServerApp(&configs.ServerConfig{Address: ":0"})
+	require.NoError(t, err)
+	centralTS := httptest.NewServer(central.Server.Handler)
+	t.Cleanup(centralTS.Close)
+	centralClient := resty.New().SetBaseURL(centralTS.URL)
+
+	edge, err := apps.NewServerApp(&configs.ServerConfig{
+		Address:          "127.0.0.1:0",
+		ForwardUpstreams: []string{centralTS.URL},
+		ForwardInterval:  1,
+		ForwardQueueSize: 10,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runners.RunServer(ctx, edge.Server, edge.Workers...) }()
+
+	edgeTS := httptest.NewServer(edge.Server.Handler)
+	t.Cleanup(edgeTS.Close)
+	edgeClient := resty.New().SetBaseURL(edgeTS.URL)
+
+	resp, err := centralClient.R().Post("/update/counter/hits/10")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	for _, path := range []string{"/update/counter/hits/2", "/update/counter/hits/3", "/update/gauge/temp/1.5"} {
+		resp, err := edgeClient.R().Post(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	// The central server adds the edge's increments to its own total.
+	require.Eventually(t, func() bool {
+		resp, err := centralClient.R().Get("/value/counter/hits")
+		return err == nil && resp.String() == "15"
+	}, 3*time.Second, 50*time.Millisecond)
+
+	resp, err = centralClient.R().Get("/value/gauge/temp")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", resp.String())
+
+	// Updates still pending at shutdown are flushed on the way out.
+	resp, err = edgeClient.R().Post("/update/counter/hits/1")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	resp, err = centralClient.R().Get("/value/counter/hits")
+	require.NoError(t, err)
+	assert.Equal(t, "16", resp.String())
+}
+
+func TestReplication(t *testing.T) {
+	require.NoError(t, logger.Initialize("debug"))
+
+	leader, err := apps.NewServerApp(&configs.ServerConfig{Address: ":0"})
+	require.NoError(t, err)
+	leaderTS := httptest.NewServer(leader.Server.Handler)
+	t.Cleanup(leaderTS.Close)
+	leaderClient := resty.New().SetBaseURL(leaderTS.URL)
+
+	resp, err := leaderClient.R().Post("/update/counter/hits/5")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	follower, err := apps.NewServerApp(&configs.ServerConfig{
+		Address:                   "127.0.0.1:0",
+		ReplicateFrom:             leaderTS.URL,
+		ReplicationResyncInterval: 300,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runners.RunServer(ctx, follower.Server, follower.Workers...) }()
+
+	followerTS := httptest.NewServer(follower.Server.Handler)
+	t.Cleanup(followerTS.Close)
+	followerClient := resty.New().SetBaseURL(followerTS.URL)
+
+	valueOn := func(client *resty.Client, path string) string {
+		resp, err := client.R().Get(path)
+		if err != nil || resp.StatusCode() != http.StatusOK {
+			return ""
+		}
+		return resp.String()
+	}
+
+	// Bootstrapped from the leader's snapshot.
+	require.Eventually(t, func() bool {
+		return valueOn(followerClient, "/value/counter/hits") == "5"
+	}, 3*time.Second, 20*time.Millisecond)
+
+	// Streamed from the leader.
+	resp, err = leaderClient.R().Post("/update/gauge/temp/36.6")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Eventually(t, func() bool {
+		return valueOn(followerClient, "/value/gauge/temp") == "36.6"
+	}, 3*time.Second, 20*time.Millisecond)
+
+	// Writes to the follower are proxied to the leader and replicated back.
+	resp, err = followerClient.R().Post("/update/counter/hits/2")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "7", valueOn(leaderClient, "/value/counter/hits"))
+	require.Eventually(t, func() bool {
+		return valueOn(followerClient, "/value/counter/hits") == "7"
+	}, 3*time.Second, 20*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return valueOn(followerClient, "/value/gauge/replication_lag_seconds") != ""
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Empty(t, valueOn(leaderClient, "/value/gauge/replication_lag_seconds"))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
-	"net/http"
 
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/apps"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
 )
 
@@ -12,16 +13,17 @@ func run(
 	ctx context.Context,
 	config *configs.ServerConfig,
 	loggerInitializeFunc func(level string) error,
-	newServerFunc func(*configs.ServerConfig) (*http.Server, error),
+	newServerFunc func(*configs.ServerConfig) (*apps.ServerApp, error),
 	newRunContextFunc func(ctx context.Context) (context.Context, context.CancelFunc),
-	runServerFunc func(ctx context.Context, srv runners.Server) error,
+	newReloadsFunc func(ctx context.Context) <-chan *configs.ServerConfig,
+	runServerFunc func(ctx context.Context, srv runners.Server, workers ...func(ctx context.Context) error) error,
 ) error {
 	err := loggerInitializeFunc(config.LogLevel)
 	if err != nil {
 		return err
 	}
 
-	srv, err := newServerFunc(config)
+	app, err := newServerFunc(config)
 	if err != nil {
 		return err
 	}
@@ -29,5 +31,19 @@ func run(
 	ctx, cancel := newRunContextFunc(ctx)
 	defer cancel()
 
-	return runServerFunc(ctx, srv)
+	reloads := newReloadsFunc(ctx)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case next := <-reloads:
+				if err := app.Reload(next); err != nil {
+					logger.Log.Errorw("Server configuration rejected, keeping the current one", "error", err)
+				}
+			}
+		}
+	}()
+
+	return runServerFunc(ctx, app.Runner(), app.Workers...)
 }
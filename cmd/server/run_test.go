@@ -6,13 +6,14 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/apps"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRun(t *testing.T) {
-	mockServer := &http.Server{}
+	mockServer := &apps.ServerApp{Server: &http.Server{}}
 
 	tests := []struct {
 		name            string
@@ -58,7 +59,7 @@ func TestRun(t *testing.T) {
 			loggerInitializeFunc := func(level string) error {
 				return tt.loggerErr
 			}
-			newServerFunc := func(cfg *configs.ServerConfig) (*http.Server, error) {
+			newServerFunc := func(cfg *configs.ServerConfig) (*apps.ServerApp, error) {
 				if tt.serverAppErr != nil {
 					return nil, tt.serverAppErr
 				}
@@ -67,7 +68,10 @@ func TestRun(t *testing.T) {
 			newRunContextFunc := func(ctx context.Context) (context.Context, context.CancelFunc) {
 				return context.WithCancel(ctx)
 			}
-			runServerFunc := func(ctx context.Context, srv runners.Server) error {
+			newReloadsFunc := func(ctx context.Context) <-chan *configs.ServerConfig {
+				return nil
+			}
+			runServerFunc := func(ctx context.Context, srv runners.Server, workers ...func(ctx context.Context) error) error {
 				return tt.runServerErr
 			}
 
@@ -77,6 +81,7 @@ func TestRun(t *testing.T) {
 				loggerInitializeFunc,
 				newServerFunc,
 				newRunContextFunc,
+				newReloadsFunc,
 				runServerFunc,
 			)
 
@@ -89,3 +94,38 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_Reload(t *testing.T) {
+	applied := make(chan *configs.ServerConfig, 2)
+	app := &apps.ServerApp{
+		Server: &http.Server{},
+		Reload: func(cfg *configs.ServerConfig) error {
+			applied <- cfg
+			if cfg.LogLevel == "bogus" {
+				return errors.New("invalid log level")
+			}
+			return nil
+		},
+	}
+
+	reloads := make(chan *configs.ServerConfig)
+	runServerFunc := func(ctx context.Context, srv runners.Server, workers ...func(ctx context.Context) error) error {
+		reloads <- &configs.ServerConfig{LogLevel: "bogus"}
+		reloads <- &configs.ServerConfig{LogLevel: "debug"}
+
+		require.Equal(t, "bogus", (<-applied).LogLevel)
+		require.Equal(t, "debug", (<-applied).LogLevel, "a rejected reload does not stop later ones")
+		return nil
+	}
+
+	err := run(
+		context.Background(),
+		&configs.ServerConfig{LogLevel: "info"},
+		func(string) error { return nil },
+		func(*configs.ServerConfig) (*apps.ServerApp, error) { return app, nil },
+		func(ctx context.Context) (context.Context, context.CancelFunc) { return context.WithCancel(ctx) },
+		func(context.Context) <-chan *configs.ServerConfig { return reloads },
+		runServerFunc,
+	)
+	require.NoError(t, err)
+}
@@ -2,24 +2,179 @@ package apps
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/collectors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/facades"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/handlers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/middlewares"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/routers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/validators"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/workers"
 )
 
-func NewAgentApp(config *configs.AgentConfig) (func(ctx context.Context) error, error) {
-	client := resty.New()
+// NewAgentApp builds the agent worker. Every configuration received from
+// reloads is applied to the running worker; the log level, sidecar address,
+// TLS options and API token still need a restart to change.
+func NewAgentApp(config *configs.AgentConfig, reloads <-chan *configs.AgentConfig) (func(ctx context.Context) error, error) {
+	client, err := newAgentClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry := collectors.NewAgentTelemetry()
+
+	registry := collectors.NewDefaultRegistry()
+	registry.Register(collectors.TelemetryCollectorName, telemetry.NewCollector)
+
+	var relayCollector *collectors.RelayCollector
+	if config.SidecarAddress != "" {
+		relayCollector = collectors.NewRelayCollector()
+	}
+
+	buildCollectors := func(config *configs.AgentConfig) ([]collectors.Collector, error) {
+		metricCollectors, err := registry.Build(config.Collectors)
+		if err != nil {
+			return nil, err
+		}
+		if relayCollector != nil {
+			metricCollectors = append(metricCollectors, relayCollector)
+		}
+		return metricCollectors, nil
+	}
+
+	metricCollectors, err := buildCollectors(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var agentWorkers []func(ctx context.Context) error
+
+	if relayCollector != nil {
+		sidecarRouter := routers.NewSidecarRouter(
+			handlers.NewMetricUpdatePathHandler(
+				validators.ValidateMetricPath,
+				validators.HandleMetricsValidationError,
+				relayCollector,
+			),
+			handlers.NewMetricUpdateBatchHandler(
+				validators.ValidateMetrics,
+				validators.HandleMetricsValidationError,
+				relayCollector,
+			),
+			middlewares.LoggingMiddleware,
+		)
+
+		agentWorkers = append(agentWorkers, runners.NewServerWorker(&http.Server{
+			Addr:    config.SidecarAddress,
+			Handler: sidecarRouter,
+		}))
+	}
+
+	agentReloads := make(chan workers.MetricAgentReload)
+
+	agentWorkers = append(agentWorkers, func(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case next, ok := <-reloads:
+				if !ok {
+					return nil
+				}
+
+				if err := validateAgentReload(next); err != nil {
+					logger.Log.Errorw("Agent configuration rejected, keeping the current one", "error", err)
+					continue
+				}
+
+				metricCollectors, err := buildCollectors(next)
+				if err != nil {
+					logger.Log.Errorw("Agent configuration rejected, keeping the current one", "error", err)
+					continue
+				}
 
-	metricUpdateFacade := facades.NewMetricUpdateFacade(client, config.ServerAddress, config.ServerEndpoint)
+				select {
+				case agentReloads <- workers.MetricAgentReload{
+					Updater:        facades.NewMetricUpdateBatchFacade(client, agentServerAddress(next)),
+					Collectors:     metricCollectors,
+					PollInterval:   next.PollInterval,
+					ReportInterval: next.ReportInterval,
+					WorkerCount:    next.NumWorkers,
+				}:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	})
 
-	worker := workers.NewMetricAgentWorker(
-		metricUpdateFacade,
+	agentWorkers = append(agentWorkers, workers.NewMetricAgentWorker(
+		facades.NewMetricUpdateBatchFacade(client, agentServerAddress(config)),
+		metricCollectors,
+		telemetry,
 		config.PollInterval,
 		config.ReportInterval,
 		config.NumWorkers,
-	)
+		agentReloads,
+	))
+
+	return runners.NewWorkerGroup(agentWorkers...), nil
+}
+
+// validateAgentReload rejects reloaded settings the running worker cannot
+// apply: its tickers need positive intervals and it needs a worker to send.
+func validateAgentReload(config *configs.AgentConfig) error {
+	if config.PollInterval < 1 || config.ReportInterval < 1 {
+		return errors.ErrInvalidAgentInterval
+	}
+	if config.NumWorkers < 1 {
+		return errors.ErrInvalidAgentWorkers
+	}
+	return nil
+}
+
+// newAgentClient returns the HTTP client for reporting. It sends the API
+// token, trusts the CA bundle in TLSCAFile in addition to the system roots
+// and presents the client certificate when the server requires one.
+func newAgentClient(config *configs.AgentConfig) (*resty.Client, error) {
+	client := resty.New()
+	if config.APIToken != "" {
+		client.SetAuthToken(config.APIToken)
+	}
+
+	if !agentUsesTLS(config) {
+		return client, nil
+	}
+
+	tlsConfig, err := newClientTLSConfig(config.TLSCAFile, config.TLSCertFile, config.TLSKeyFile, config.TLSInsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.SetTLSClientConfig(tlsConfig), nil
+}
+
+func agentUsesTLS(config *configs.AgentConfig) bool {
+	return config.TLSCAFile != "" || config.TLSInsecureSkipVerify ||
+		config.TLSCertFile != "" || config.TLSKeyFile != ""
+}
 
-	return worker, nil
+// agentServerAddress defaults the server address to HTTPS when TLS options
+// are set and the address has no scheme.
+func agentServerAddress(config *configs.AgentConfig) string {
+	addr := config.ServerAddress
+	if !agentUsesTLS(config) {
+		return addr
+	}
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return addr
+	}
+	return "https://" + addr
 }
@@ -2,22 +2,32 @@ package apps
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/testutils"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewAgentApp(t *testing.T) {
 	config := &configs.AgentConfig{
 		ServerAddress:  "http://localhost:8080",
-		ServerEndpoint: "/update",
 		PollInterval:   1,
 		ReportInterval: 1,
 		NumWorkers:     1,
+		Collectors:     []string{"runtime", "poll_count", "telemetry"},
 	}
 
-	workerFunc, err := NewAgentApp(config)
+	workerFunc, err := NewAgentApp(config, nil)
 	require.NoError(t, err)
 	require.NotNil(t, workerFunc)
 
@@ -27,3 +37,163 @@ func TestNewAgentApp(t *testing.T) {
 	// Run the worker function in a goroutine to check it does not panic or block indefinitely
 	go workerFunc(ctx)
 }
+
+func TestNewAgentApp_InvalidCollectors(t *testing.T) {
+	config := &configs.AgentConfig{
+		ServerAddress: "http://localhost:8080",
+		Collectors:    []string{"runtime", "disk"},
+	}
+
+	workerFunc, err := NewAgentApp(config, nil)
+	require.ErrorIs(t, err, errors.ErrUnknownCollector)
+	require.Nil(t, workerFunc)
+}
+
+func TestNewAgentApp_Sidecar(t *testing.T) {
+	config := &configs.AgentConfig{
+		ServerAddress:  "http://localhost:8080",
+		PollInterval:   1,
+		ReportInterval: 1,
+		NumWorkers:     1,
+		SidecarAddress: "127.0.0.1:0",
+	}
+
+	workerFunc, err := NewAgentApp(config, nil)
+	require.NoError(t, err)
+	require.NotNil(t, workerFunc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, workerFunc(ctx), context.DeadlineExceeded)
+}
+
+func TestNewAgentApp_RejectsInvalidReload(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core).Sugar()
+	defer func() { logger.Log = previous }()
+
+	config := &configs.AgentConfig{
+		ServerAddress:  "http://localhost:8080",
+		PollInterval:   1,
+		ReportInterval: 1,
+		NumWorkers:     1,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *configs.AgentConfig)
+		wantErr error
+	}{
+		{"zero poll interval", func(c *configs.AgentConfig) { c.PollInterval = 0 }, errors.ErrInvalidAgentInterval},
+		{"negative report interval", func(c *configs.AgentConfig) { c.ReportInterval = -1 }, errors.ErrInvalidAgentInterval},
+		{"zero workers", func(c *configs.AgentConfig) { c.NumWorkers = 0 }, errors.ErrInvalidAgentWorkers},
+	}
+
+	reloads := make(chan *configs.AgentConfig)
+	workerFunc, err := NewAgentApp(config, reloads)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- workerFunc(ctx) }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := *config
+			tt.modify(&next)
+			reloads <- &next
+
+			require.Eventually(t, func() bool {
+				for _, entry := range logs.TakeAll() {
+					if entry.ContextMap()["error"] == tt.wantErr.Error() {
+						return true
+					}
+				}
+				return false
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled, "the agent keeps running until it is stopped")
+}
+
+func TestNewAgentApp_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutils.SelfSignedCert(t, dir)
+	clientCert, clientKey := testutils.ClientCert(t, dir, certFile, keyFile, "agent-a")
+
+	tests := []struct {
+		name     string
+		caFile   string
+		certFile string
+		keyFile  string
+		wantErr  error
+	}{
+		{name: "CA bundle", caFile: certFile},
+		{name: "not a CA bundle", caFile: keyFile, wantErr: errors.ErrInvalidCABundle},
+		{name: "missing CA bundle", caFile: filepath.Join(dir, "missing.pem"), wantErr: os.ErrNotExist},
+		{name: "client certificate", caFile: certFile, certFile: clientCert, keyFile: clientKey},
+		{name: "client certificate without key", caFile: certFile, certFile: clientCert, wantErr: errors.ErrIncompleteTLSKeyPair},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configs.AgentConfig{
+				ServerAddress:  "localhost:8080",
+				PollInterval:   1,
+				ReportInterval: 1,
+				NumWorkers:     1,
+				TLSCAFile:      tt.caFile,
+				TLSCertFile:    tt.certFile,
+				TLSKeyFile:     tt.keyFile,
+			}
+
+			workerFunc, err := NewAgentApp(config, nil)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, workerFunc)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, workerFunc)
+		})
+	}
+}
+
+func TestAgentServerAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		config configs.AgentConfig
+		want   string
+	}{
+		{name: "plain", config: configs.AgentConfig{ServerAddress: "localhost:8080"}, want: "localhost:8080"},
+		{name: "CA bundle", config: configs.AgentConfig{ServerAddress: "localhost:8443", TLSCAFile: "ca.pem"}, want: "https://localhost:8443"},
+		{name: "insecure", config: configs.AgentConfig{ServerAddress: "localhost:8443", TLSInsecureSkipVerify: true}, want: "https://localhost:8443"},
+		{name: "client certificate", config: configs.AgentConfig{ServerAddress: "localhost:8443", TLSCertFile: "agent.pem"}, want: "https://localhost:8443"},
+		{name: "explicit scheme", config: configs.AgentConfig{ServerAddress: "http://localhost:8080", TLSCAFile: "ca.pem"}, want: "http://localhost:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, agentServerAddress(&tt.config))
+		})
+	}
+}
+
+func TestNewAgentClient_APIToken(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	client, err := newAgentClient(&configs.AgentConfig{APIToken: "secret"})
+	require.NoError(t, err)
+
+	_, err = client.R().Post(ts.URL + "/update/counter/hits/1")
+	require.NoError(t, err)
+	require.Equal(t, "Bearer secret", got)
+}
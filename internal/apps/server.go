@@ -1,41 +1,259 @@
 package apps
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/facades"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/handlers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/listeners"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/middlewares"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/parsers"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/repositories"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/routers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/services"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/validators"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/workers"
 )
 
-func NewServerApp(config *configs.ServerConfig) (*http.Server, error) {
-	memStorage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+const (
+	forwardRequestTimeout = 10 * time.Second
+	// serverMetricsReportInterval is how often, in seconds, the server
+	// stores its own metrics.
+	serverMetricsReportInterval = 10
+)
+
+type ServerApp struct {
+	Server  *http.Server
+	Workers []func(ctx context.Context) error
+	// Reload applies the settings of a new configuration that are safe to
+	// change at runtime. An invalid configuration is rejected as a whole.
+	Reload func(config *configs.ServerConfig) error
+
+	tlsCertFile string
+	tlsKeyFile  string
+}
+
+// Runner returns Server ready for runners.RunServer, serving HTTPS when a
+// TLS certificate is configured.
+func (a *ServerApp) Runner() runners.Server {
+	if a.tlsCertFile != "" {
+		return runners.NewTLSServer(a.Server, a.tlsCertFile, a.tlsKeyFile)
+	}
+	return a.Server
+}
+
+func NewServerApp(config *configs.ServerConfig) (*ServerApp, error) {
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, errors.ErrIncompleteTLSKeyPair
+	}
+	if config.TLSCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile); err != nil {
+			return nil, fmt.Errorf("load TLS key pair: %w", err)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if config.TLSClientCAFile != "" {
+		if config.TLSCertFile == "" {
+			return nil, errors.ErrClientCAWithoutTLS
+		}
+
+		clientCAs, err := appendCABundle(x509.NewCertPool(), config.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = &tls.Config{
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+
+	if len(config.IdentityPrefixes) > 0 && config.TLSClientCAFile == "" {
+		return nil, errors.ErrIdentityPrefixesWithoutClient
+	}
+	identityPrefixes, err := parsers.ParseIdentityPrefixes(config.IdentityPrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimiter := middlewares.NewRateLimiter(
+		config.RateLimit,
+		config.RateBurst,
+		config.MaxInFlight,
+		isStreamRequest,
+	)
+
+	settings, err := newServerSettings(config, rateLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	var memStorage engines.Storage[types.MetricID, types.Metrics]
+	if config.StorageShards > 1 {
+		memStorage = engines.NewShardedMemoryStorage[types.MetricID, types.Metrics](config.StorageShards)
+	} else {
+		memStorage = engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	}
+
+	var serverWorkers []func(ctx context.Context) error
 
 	metricMemoryGetRepository := repositories.NewMetricMemoryGetRepository(memStorage)
-	metricMemorySaverRepository := repositories.NewMetricMemorySaveRepository(memStorage)
 	metricMemoryListerRepository := repositories.NewMetricMemoryListRepository(memStorage)
 
+	var metricMemorySaverRepository services.MetricUpdateSaver = repositories.NewMetricMemorySaveRepository(memStorage)
+
+	var wal *engines.WAL
+	if config.FileStoragePath != "" {
+		var err error
+		wal, err = engines.NewWAL(config.FileStoragePath+".wal", config.WALFsync)
+		if err != nil {
+			return nil, err
+		}
+
+		metricFileSnapshotRepository := repositories.NewMetricFileSnapshotRepository(
+			memStorage,
+			wal,
+			config.FileStoragePath,
+		)
+
+		if config.Restore {
+			if err := metricFileSnapshotRepository.Restore(context.Background()); err != nil {
+				return nil, err
+			}
+		}
+
+		metricMemorySaverRepository = repositories.NewMetricMemoryWALSaveRepository(memStorage, wal)
+
+		serverWorkers = append(serverWorkers, workers.NewMetricSnapshotWorker(
+			metricFileSnapshotRepository,
+			config.StoreInterval,
+		))
+		if config.WALFsync == engines.FsyncInterval {
+			serverWorkers = append(serverWorkers, workers.NewWALSyncWorker(
+				wal,
+				config.WALFsyncInterval,
+			))
+		}
+	}
+
+	metricMemoryDumpRepository := repositories.NewMetricMemoryDumpRepository(memStorage, wal)
+
+	metricStreamService, err := services.NewMetricStreamService(
+		config.StreamBuffer,
+		config.StreamSlowPolicy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	metricUpdateListeners := []services.MetricUpdateListener{metricStreamService}
+
+	upstreamTLSConfig, err := newUpstreamTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.ForwardUpstreams) > 0 {
+		client := newUpstreamClient(config, upstreamTLSConfig).SetTimeout(forwardRequestTimeout)
+
+		senders := make(map[string]services.MetricForwardSender, len(config.ForwardUpstreams))
+		for _, upstream := range config.ForwardUpstreams {
+			senders[upstream] = facades.NewMetricUpdateBatchFacade(client, upstreamAddress(upstreamTLSConfig, upstream))
+		}
+
+		metricForwardService := services.NewMetricForwardService(config.ForwardQueueSize, senders)
+		metricUpdateListeners = append(metricUpdateListeners, metricForwardService)
+		serverWorkers = append(serverWorkers, workers.NewMetricForwardWorker(
+			metricForwardService,
+			config.ForwardInterval,
+		))
+	}
+
 	metricUpdateService := services.NewMetricUpdateService(
 		metricMemorySaverRepository,
 		metricMemoryGetRepository,
+		metricUpdateListeners...,
 	)
+
+	// The server's own metrics describe this instance only: a replica would
+	// mix them into the keyspace it copies from the leader, and they are
+	// stored without the forwarding listener so edges do not sum theirs
+	// into one upstream series.
+	if config.ReplicateFrom == "" {
+		serverWorkers = append(serverWorkers, workers.NewServerMetricsWorker(
+			rateLimiter,
+			services.NewMetricUpdateService(
+				metricMemorySaverRepository,
+				metricMemoryGetRepository,
+				metricStreamService,
+			),
+			serverMetricsReportInterval,
+		))
+	}
+
+	if config.StatsDAddress != "" {
+		serverWorkers = append(serverWorkers, runners.NewServerWorker(
+			listeners.NewStatsDListener(config.StatsDAddress, metricUpdateService),
+		))
+	}
+
+	if config.GraphiteAddress != "" {
+		graphiteCounterRules, err := parsers.NewGraphiteCounterRules(config.GraphiteCounterRules)
+		if err != nil {
+			return nil, err
+		}
+
+		serverWorkers = append(serverWorkers, runners.NewServerWorker(
+			listeners.NewGraphiteListener(
+				config.GraphiteAddress,
+				metricUpdateService,
+				graphiteCounterRules,
+				config.GraphiteReadTimeout,
+			),
+		))
+	}
 	metricGetService := services.NewMetricGetService(
 		metricMemoryGetRepository,
 	)
 	metricListService := services.NewMetricListService(
 		metricMemoryListerRepository,
 	)
+	metricQueryService := services.NewMetricQueryService(
+		metricMemoryListerRepository,
+	)
+	metricImportService := services.NewMetricImportService(
+		metricUpdateService,
+		metricMemoryDumpRepository,
+	)
+
+	// Writes over HTTP are checked against the client identity; the StatsD,
+	// Graphite and replication paths above keep using metricUpdateService.
+	var metricWriteUpdater services.MetricAuthorizedUpdater = metricUpdateService
+	var metricWriteImporter services.MetricAuthorizedImporter = metricImportService
+	if len(identityPrefixes) > 0 {
+		metricAuthorizationService := services.NewMetricAuthorizationService(
+			metricUpdateService,
+			metricImportService,
+			identityPrefixes,
+		)
+		metricWriteUpdater = metricAuthorizationService
+		metricWriteImporter = metricAuthorizationService
+	}
 
 	metricUpdatePathHandler := handlers.NewMetricUpdatePathHandler(
 		validators.ValidateMetricPath,
 		validators.HandleMetricsValidationError,
-		metricUpdateService,
+		metricWriteUpdater,
 	)
 	metricGetPathHandler := handlers.NewMetricGetPathHandler(
 		validators.ValidateMetricIDPath,
@@ -43,25 +261,155 @@ func NewServerApp(config *configs.ServerConfig) (*http.Server, error) {
 		metricGetService,
 	)
 	metricListHTMLHandler := handlers.NewMetricListHTMLHandler(
+		validators.ValidateMetricsListFilter,
 		validators.HandleMetricsValidationError,
 		metricListService,
 	)
+	metricQueryHandler := handlers.NewMetricQueryHandler(
+		validators.ValidateMetricsQuery,
+		validators.HandleMetricsValidationError,
+		metricQueryService,
+	)
+	metricExportHandler := handlers.NewMetricExportHandler(
+		validators.ValidateExportFormat,
+		validators.HandleMetricsValidationError,
+		metricListService,
+	)
+	metricImportHandler := handlers.NewMetricImportHandler(
+		validators.ValidateExportFormat,
+		validators.ValidateImportMode,
+		validators.ValidateMetrics,
+		validators.HandleMetricsValidationError,
+		metricWriteImporter,
+	)
+	metricInfluxWriteHandler := handlers.NewMetricInfluxWriteHandler(
+		validators.ValidateInfluxPrecision,
+		parsers.NewInfluxParser(config.InfluxCounterSuffixes).ParseLine,
+		validators.HandleMetricsValidationError,
+		metricWriteUpdater,
+	)
+	metricUpdateBatchHandler := handlers.NewMetricUpdateBatchHandler(
+		validators.ValidateMetrics,
+		validators.HandleMetricsValidationError,
+		metricWriteUpdater,
+	)
+	metricOTLPHandler := handlers.NewMetricOTLPHandler(
+		parsers.ConvertOTLPMetrics,
+		validators.HandleMetricsValidationError,
+		metricWriteUpdater,
+	)
+	metricStreamHandler := handlers.NewMetricStreamHandler(
+		validators.ValidateMetricsStreamFilter,
+		validators.HandleMetricsValidationError,
+		metricStreamService,
+	)
 
-	middlewares := []func(next http.Handler) http.Handler{
+	var serverMiddlewares []func(next http.Handler) http.Handler
+	if tlsConfig != nil {
+		serverMiddlewares = append(serverMiddlewares, middlewares.ClientIdentityMiddleware)
+	}
+	serverMiddlewares = append(serverMiddlewares,
 		middlewares.LoggingMiddleware,
+		rateLimiter.Middleware,
+		middlewares.NewTokenScopeMiddleware(settings.tokenScopes, settings.requiredScope),
+	)
+
+	if config.ReplicateFrom != "" {
+		if config.StatsDAddress != "" || config.GraphiteAddress != "" {
+			return nil, errors.ErrReplicaListeners
+		}
+		// Writes are proxied before any handler runs, so the prefix check
+		// would never see them.
+		if len(config.IdentityPrefixes) > 0 {
+			return nil, errors.ErrReplicaIdentityPrefixes
+		}
+
+		leaderAddr := upstreamAddress(upstreamTLSConfig, config.ReplicateFrom)
+
+		readOnlyMiddleware, err := middlewares.NewReadOnlyMiddleware(
+			leaderAddr,
+			newUpstreamTransport(upstreamTLSConfig),
+		)
+		if err != nil {
+			return nil, err
+		}
+		serverMiddlewares = append(serverMiddlewares, readOnlyMiddleware)
+
+		metricReplicationService := services.NewMetricReplicationService(
+			facades.NewMetricReplicationFacade(newUpstreamClient(config, upstreamTLSConfig), leaderAddr),
+			metricMemoryDumpRepository,
+			metricMemorySaverRepository,
+			metricMemoryGetRepository,
+			config.ReplicationResyncInterval,
+			metricUpdateListeners...,
+		)
+		serverWorkers = append(serverWorkers, workers.NewMetricReplicationWorker(
+			metricReplicationService,
+		))
 	}
 
 	metricsRouter := routers.NewMetricsRouter(
 		metricUpdatePathHandler,
 		metricGetPathHandler,
 		metricListHTMLHandler,
-		middlewares...,
+		metricQueryHandler,
+		metricStreamHandler,
+		metricExportHandler,
+		metricImportHandler,
+		metricInfluxWriteHandler,
+		metricOTLPHandler,
+		metricUpdateBatchHandler,
+		handlers.NewStaticHandler(),
+		serverMiddlewares...,
+	)
+
+	// Admin endpoints stay mounted so a reload can enable them; without an
+	// admin token every request is rejected by the token scope middleware.
+	metricDumpService := services.NewMetricDumpService(
+		metricMemoryDumpRepository,
+	)
+	metricRestoreService := services.NewMetricRestoreService(
+		metricMemoryDumpRepository,
 	)
 
+	metricSnapshotHandler := handlers.NewMetricSnapshotHandler(
+		validators.ValidateDumpFormat,
+		validators.HandleMetricsValidationError,
+		metricDumpService,
+	)
+	metricRestoreHandler := handlers.NewMetricRestoreHandler(
+		validators.ValidateRestoreMode,
+		validators.ValidateMetrics,
+		validators.HandleMetricsValidationError,
+		metricRestoreService,
+	)
+
+	adminRouter := routers.NewAdminRouter(
+		metricSnapshotHandler,
+		metricRestoreHandler,
+	)
+
+	metricsRouter.Mount("/admin", adminRouter)
+
 	srv := &http.Server{
-		Addr:    config.Address,
-		Handler: metricsRouter,
+		Addr:      config.Address,
+		Handler:   metricsRouter,
+		TLSConfig: tlsConfig,
 	}
+	srv.RegisterOnShutdown(metricStreamService.Close)
+
+	return &ServerApp{
+		Server:  srv,
+		Workers: serverWorkers,
+		Reload:  settings.reload,
+
+		tlsCertFile: config.TLSCertFile,
+		tlsKeyFile:  config.TLSKeyFile,
+	}, nil
+}
 
-	return srv, nil
+// isStreamRequest reports whether r opens a stream, which stays open for as
+// long as the subscriber listens.
+func isStreamRequest(r *http.Request) bool {
+	return r.URL.Path == "/stream"
 }
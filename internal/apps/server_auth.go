@@ -0,0 +1,63 @@
+package apps
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// loadTokenFile reads the JSON array of API tokens in path and indexes them
+// by their hex-encoded SHA-256.
+func loadTokenFile(path string) (map[string]types.APIToken, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+
+	var entries []types.APIToken
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidTokenFile, err)
+	}
+
+	tokens := make(map[string]types.APIToken, len(entries))
+	for _, entry := range entries {
+		hash := strings.ToLower(entry.SHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("%w: token %q: sha256 must be 64 hex characters", errors.ErrInvalidTokenFile, entry.Name)
+		}
+		for _, scope := range entry.Scopes {
+			switch scope {
+			case types.ScopeRead, types.ScopeWrite, types.ScopeAdmin:
+			default:
+				return nil, fmt.Errorf("%w: token %q: unknown scope %q", errors.ErrInvalidTokenFile, entry.Name, scope)
+			}
+		}
+		tokens[hash] = entry
+	}
+	return tokens, nil
+}
+
+// metricRouteScope is the scope a request needs: admin for the admin
+// endpoints, write for any other request that is not a GET, HEAD or
+// OPTIONS, and read for the rest. Static assets need no token.
+func metricRouteScope(r *http.Request) string {
+	switch {
+	case r.URL.Path == "/admin" || strings.HasPrefix(r.URL.Path, "/admin/"):
+		return types.ScopeAdmin
+	case strings.HasPrefix(r.URL.Path, "/static/"):
+		return ""
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return types.ScopeRead
+	default:
+		return types.ScopeWrite
+	}
+}
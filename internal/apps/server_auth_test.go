@@ -0,0 +1,216 @@
+package apps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func writeTokenFile(t *testing.T, path string, tokens map[string][]string) {
+	t.Helper()
+
+	var entries []types.APIToken
+	for token, scopes := range tokens {
+		sum := sha256.Sum256([]byte(token))
+		entries = append(entries, types.APIToken{Name: token, SHA256: hex.EncodeToString(sum[:]), Scopes: scopes})
+	}
+	data, err := json.Marshal(entries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestLoadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	hash := strings.Repeat("AB", 32)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{name: "valid", content: `[{"name":"agent","sha256":"` + hash + `","scopes":["write","read"]}]`},
+		{name: "not JSON", content: `agent:secret`, wantErr: errors.ErrInvalidTokenFile},
+		{name: "short hash", content: `[{"name":"agent","sha256":"abcd","scopes":["write"]}]`, wantErr: errors.ErrInvalidTokenFile},
+		{name: "unknown scope", content: `[{"name":"agent","sha256":"` + hash + `","scopes":["delete"]}]`, wantErr: errors.ErrInvalidTokenFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "tokens.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			tokens, err := loadTokenFile(path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"write", "read"}, tokens[strings.ToLower(hash)].Scopes)
+		})
+	}
+
+	_, err := loadTokenFile(filepath.Join(dir, "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMetricRouteScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/update/counter/hits/1", types.ScopeWrite},
+		{http.MethodPost, "/updates/", types.ScopeWrite},
+		{http.MethodPost, "/import", types.ScopeWrite},
+		{http.MethodGet, "/value/counter/hits", types.ScopeRead},
+		{http.MethodGet, "/", types.ScopeRead},
+		{http.MethodGet, "/stream", types.ScopeRead},
+		{http.MethodGet, "/admin/snapshot", types.ScopeAdmin},
+		{http.MethodPost, "/admin/restore", types.ScopeAdmin},
+		{http.MethodGet, "/static/style.css", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, metricRouteScope(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestServerApp_Tokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokenFile(t, path, map[string][]string{
+		"reader": {types.ScopeRead},
+		"writer": {types.ScopeWrite},
+		"admin":  {types.ScopeAdmin},
+	})
+
+	config := &configs.ServerConfig{Address: ":8080", LogLevel: "info", TokensFile: path}
+	app, err := NewServerApp(config)
+	require.NoError(t, err)
+
+	do := func(method, target, token string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		app.Server.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		token      string
+		wantStatus int
+	}{
+		{"write with write scope", http.MethodPost, "/update/counter/hits/1", "writer", http.StatusOK},
+		{"write with read scope", http.MethodPost, "/update/counter/hits/1", "reader", http.StatusForbidden},
+		{"write without token", http.MethodPost, "/update/counter/hits/1", "", http.StatusUnauthorized},
+		{"read with read scope", http.MethodGet, "/value/counter/hits", "reader", http.StatusOK},
+		{"read with write scope", http.MethodGet, "/", "writer", http.StatusForbidden},
+		{"admin with admin scope", http.MethodGet, "/admin/snapshot", "admin", http.StatusOK},
+		{"admin with read scope", http.MethodGet, "/admin/snapshot", "reader", http.StatusForbidden},
+		{"unknown token", http.MethodGet, "/", "guess", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantStatus, do(tt.method, tt.target, tt.token))
+		})
+	}
+
+	t.Run("reload revokes removed tokens", func(t *testing.T) {
+		writeTokenFile(t, path, map[string][]string{"reader": {types.ScopeRead}})
+		require.NoError(t, app.Reload(config))
+
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/update/counter/hits/1", "writer"))
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/value/counter/hits", "reader"))
+	})
+
+	t.Run("invalid token file keeps the current tokens", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+		require.ErrorIs(t, app.Reload(config), errors.ErrInvalidTokenFile)
+
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/value/counter/hits", "reader"))
+	})
+
+	t.Run("disabling the token file opens metric routes", func(t *testing.T) {
+		next := *config
+		next.TokensFile = ""
+		require.NoError(t, app.Reload(&next))
+
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/value/counter/hits", ""))
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/snapshot", ""))
+	})
+}
+
+func TestServerApp_ReplicatesFromTokenProtectedLeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokenFile(t, path, map[string][]string{
+		"replica": {types.ScopeRead},
+		"writer":  {types.ScopeWrite},
+	})
+
+	leader, err := NewServerApp(&configs.ServerConfig{TokensFile: path})
+	require.NoError(t, err)
+	leaderTS := httptest.NewServer(leader.Server.Handler)
+	defer leaderTS.Close()
+
+	replica, err := NewServerApp(&configs.ServerConfig{
+		ReplicateFrom:             leaderTS.URL,
+		ReplicationResyncInterval: 300,
+		UpstreamToken:             "replica",
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runners.RunServer(ctx, &http.Server{Addr: "127.0.0.1:0", Handler: replica.Server.Handler}, replica.Workers...)
+
+	do := func(handler http.Handler, method, target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Proxied writes are authorized by the leader against the client's own
+	// token, never the replica's.
+	assert.Equal(t, http.StatusUnauthorized, do(replica.Server.Handler, http.MethodPost, "/update/counter/hits/1", "").Code)
+	assert.Equal(t, http.StatusForbidden, do(replica.Server.Handler, http.MethodPost, "/update/counter/hits/1", "replica").Code)
+	require.Equal(t, http.StatusOK, do(replica.Server.Handler, http.MethodPost, "/update/counter/hits/3", "writer").Code)
+	assert.Equal(t, "3", do(leader.Server.Handler, http.MethodGet, "/value/counter/hits", "replica").Body.String())
+
+	// The replica reads the snapshot and stream with its upstream token.
+	require.Eventually(t, func() bool {
+		rec := do(replica.Server.Handler, http.MethodGet, "/value/counter/hits", "")
+		return rec.Code == http.StatusOK && rec.Body.String() == "3"
+	}, 3*time.Second, 20*time.Millisecond)
+}
+
+func TestNewServerApp_InvalidTokenFile(t *testing.T) {
+	_, err := NewServerApp(&configs.ServerConfig{TokensFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,143 @@
+package apps
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/middlewares"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// reloadableServerFields are the ServerConfig fields that can change while
+// the server runs without dropping connections or in-memory state.
+var reloadableServerFields = map[string]bool{
+	"LogLevel":    true,
+	"AdminToken":  true,
+	"TokensFile":  true,
+	"RateLimit":   true,
+	"RateBurst":   true,
+	"MaxInFlight": true,
+}
+
+// serverSettings holds the active server configuration for the parts of the
+// server that read it at runtime.
+type serverSettings struct {
+	mu      sync.RWMutex
+	config  configs.ServerConfig
+	tokens  map[string]types.APIToken
+	limiter *middlewares.RateLimiter
+}
+
+func newServerSettings(config *configs.ServerConfig, limiter *middlewares.RateLimiter) (*serverSettings, error) {
+	tokens, err := loadServerTokens(config)
+	if err != nil {
+		return nil, err
+	}
+	return &serverSettings{config: *config, tokens: tokens, limiter: limiter}, nil
+}
+
+func loadServerTokens(config *configs.ServerConfig) (map[string]types.APIToken, error) {
+	if config.TokensFile == "" {
+		return nil, nil
+	}
+	return loadTokenFile(config.TokensFile)
+}
+
+// tokenScopes looks up a bearer token. The admin token, if set, is granted
+// the admin scope alongside the tokens from the token file.
+func (s *serverSettings) tokenScopes(token string) (string, []string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if admin := s.config.AdminToken; admin != "" && subtle.ConstantTimeCompare([]byte(token), []byte(admin)) == 1 {
+		return "admin-token", []string{types.ScopeAdmin}, true
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	entry, ok := s.tokens[hex.EncodeToString(sum[:])]
+	return entry.Name, entry.Scopes, ok
+}
+
+// requiredScope is the token scope a request needs. The admin endpoints
+// always need one; other routes only once a token file is configured.
+func (s *serverSettings) requiredScope(r *http.Request) string {
+	scope := metricRouteScope(r)
+	if scope == types.ScopeAdmin {
+		return scope
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.config.TokensFile == "" {
+		return ""
+	}
+	return scope
+}
+
+// reload applies the reloadable settings of next once all of them are
+// valid; otherwise nothing changes. Other differences are logged and wait
+// for a restart.
+func (s *serverSettings) reload(next *configs.ServerConfig) error {
+	if _, err := zapcore.ParseLevel(next.LogLevel); err != nil {
+		return fmt.Errorf("%w: %q", errors.ErrInvalidLogLevel, next.LogLevel)
+	}
+	// The token file is re-read even if its path is unchanged, so removing a
+	// token from it and reloading revokes the token.
+	tokens, err := loadServerTokens(next)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fields := restartRequiredFields(&s.config, next); len(fields) > 0 {
+		logger.Log.Warnw("Ignoring server settings that need a restart", "fields", fields)
+	}
+
+	logger.SetLevel(next.LogLevel)
+	s.config.LogLevel = next.LogLevel
+	s.config.AdminToken = next.AdminToken
+	s.config.TokensFile = next.TokensFile
+	s.tokens = tokens
+	s.config.RateLimit = next.RateLimit
+	s.config.RateBurst = next.RateBurst
+	s.config.MaxInFlight = next.MaxInFlight
+	s.limiter.SetLimits(next.RateLimit, next.RateBurst, next.MaxInFlight)
+
+	logger.Log.Infow("Server configuration reloaded",
+		"log_level", next.LogLevel,
+		"tokens", len(tokens),
+		"rate_limit", next.RateLimit,
+		"rate_burst", next.RateBurst,
+		"max_in_flight", next.MaxInFlight,
+	)
+	return nil
+}
+
+func restartRequiredFields(current, next *configs.ServerConfig) []string {
+	var fields []string
+
+	cur := reflect.ValueOf(current).Elem()
+	nxt := reflect.ValueOf(next).Elem()
+	for i := range cur.NumField() {
+		name := cur.Type().Field(i).Name
+		if reloadableServerFields[name] {
+			continue
+		}
+		if !reflect.DeepEqual(cur.Field(i).Interface(), nxt.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
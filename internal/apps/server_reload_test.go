@@ -0,0 +1,80 @@
+package apps
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+func TestServerApp_Reload(t *testing.T) {
+	config := &configs.ServerConfig{Address: ":8080", LogLevel: "info"}
+
+	app, err := NewServerApp(config)
+	require.NoError(t, err)
+	defer logger.SetLevel("info")
+
+	snapshot := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		app.Server.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, snapshot(""), "admin endpoints are closed without a token")
+
+	next := *config
+	next.AdminToken = "secret"
+	next.LogLevel = "debug"
+	next.StorageShards = 4
+	require.NoError(t, app.Reload(&next))
+	assert.Equal(t, http.StatusOK, snapshot("secret"))
+
+	invalid := next
+	invalid.AdminToken = "other"
+	invalid.LogLevel = "loud"
+	err = app.Reload(&invalid)
+	require.ErrorIs(t, err, errors.ErrInvalidLogLevel)
+	assert.Equal(t, http.StatusOK, snapshot("secret"), "a rejected reload keeps the old token")
+	assert.Equal(t, http.StatusUnauthorized, snapshot("other"))
+}
+
+func TestRestartRequiredFields(t *testing.T) {
+	current := &configs.ServerConfig{Address: ":8080", LogLevel: "info", AdminToken: "a"}
+	next := &configs.ServerConfig{Address: ":9090", LogLevel: "debug", AdminToken: "b", ForwardUpstreams: []string{"up"}}
+
+	assert.Equal(t, []string{"Address", "ForwardUpstreams"}, restartRequiredFields(current, next))
+	assert.Empty(t, restartRequiredFields(current, current))
+}
+
+func TestServerApp_ReloadRateLimits(t *testing.T) {
+	config := &configs.ServerConfig{Address: ":8080", LogLevel: "info", RateLimit: 1, RateBurst: 1}
+
+	app, err := NewServerApp(config)
+	require.NoError(t, err)
+
+	update := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/update/counter/hits/1", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		rec := httptest.NewRecorder()
+		app.Server.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, update().Code)
+	rec := update()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	next := *config
+	next.RateLimit = 0
+	require.NoError(t, app.Reload(&next))
+	assert.Equal(t, http.StatusOK, update().Code, "a reload lifts the limit without a restart")
+}
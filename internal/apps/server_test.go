@@ -1,17 +1,27 @@
 package apps
 
 import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/runners"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServerApp(t *testing.T) {
 	tests := []struct {
-		name    string
-		config  *configs.ServerConfig
-		wantErr bool
+		name        string
+		config      *configs.ServerConfig
+		wantErr     bool
+		wantWorkers int
 	}{
 		{
 			name: "valid config",
@@ -20,6 +30,113 @@ func TestNewServerApp(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "file storage adds snapshot worker",
+			config: &configs.ServerConfig{
+				Address:         ":8080",
+				FileStoragePath: filepath.Join(t.TempDir(), "metrics.json"),
+				WALFsync:        "always",
+			},
+			wantWorkers: 1,
+		},
+		{
+			name: "interval fsync adds wal sync worker",
+			config: &configs.ServerConfig{
+				Address:          ":8080",
+				FileStoragePath:  filepath.Join(t.TempDir(), "metrics.json"),
+				Restore:          true,
+				WALFsync:         "interval",
+				WALFsyncInterval: 1,
+			},
+			wantWorkers: 2,
+		},
+		{
+			name: "sharded storage",
+			config: &configs.ServerConfig{
+				Address:       ":8080",
+				StorageShards: 16,
+			},
+		},
+		{
+			name: "statsd listener adds worker",
+			config: &configs.ServerConfig{
+				Address:       ":8080",
+				StatsDAddress: "127.0.0.1:0",
+			},
+			wantWorkers: 1,
+		},
+		{
+			name: "graphite listener adds worker",
+			config: &configs.ServerConfig{
+				Address:              ":8080",
+				GraphiteAddress:      "127.0.0.1:0",
+				GraphiteCounterRules: []string{"*.requests"},
+			},
+			wantWorkers: 1,
+		},
+		{
+			name: "forwarding adds worker",
+			config: &configs.ServerConfig{
+				Address:          ":8080",
+				ForwardUpstreams: []string{"central:8080", "backup:8080"},
+				ForwardInterval:  10,
+				ForwardQueueSize: 100,
+			},
+			wantWorkers: 1,
+		},
+		{
+			name: "replica adds replication worker",
+			config: &configs.ServerConfig{
+				Address:                   ":8080",
+				ReplicateFrom:             "leader:8080",
+				ReplicationResyncInterval: 300,
+			},
+			wantWorkers: 1,
+		},
+		{
+			name: "replica with incomplete upstream key pair",
+			config: &configs.ServerConfig{
+				Address:             ":8080",
+				ReplicateFrom:       "leader:8443",
+				UpstreamTLSCertFile: "replica.crt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "replica rejects ingestion listeners",
+			config: &configs.ServerConfig{
+				Address:       ":8080",
+				ReplicateFrom: "leader:8080",
+				StatsDAddress: "127.0.0.1:0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid graphite counter rule",
+			config: &configs.ServerConfig{
+				Address:              ":8080",
+				GraphiteAddress:      "127.0.0.1:0",
+				GraphiteCounterRules: []string{"app.[.requests"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid stream slow consumer policy",
+			config: &configs.ServerConfig{
+				Address:          ":8080",
+				StreamSlowPolicy: "block",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid fsync policy",
+			config: &configs.ServerConfig{
+				Address:         ":8080",
+				FileStoragePath: filepath.Join(t.TempDir(), "metrics.json"),
+				WALFsync:        "sometimes",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -31,8 +148,149 @@ func TestNewServerApp(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, app)
-				assert.Equal(t, tt.config.Address, app.Addr)
+				assert.Equal(t, tt.config.Address, app.Server.Addr)
+				// Every server but a replica also runs the worker storing
+				// its own metrics.
+				wantWorkers := tt.wantWorkers
+				if tt.config.ReplicateFrom == "" {
+					wantWorkers++
+				}
+				assert.Len(t, app.Workers, wantWorkers)
 			}
 		})
 	}
 }
+
+func TestNewServerApp_TLS(t *testing.T) {
+	certFile, keyFile := testutils.SelfSignedCert(t, t.TempDir())
+
+	app, err := NewServerApp(&configs.ServerConfig{Address: ":8443", TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+	assert.NotEqual(t, runners.Server(app.Server), app.Runner(), "TLS is served through an adapter")
+
+	app, err = NewServerApp(&configs.ServerConfig{Address: ":8080"})
+	require.NoError(t, err)
+	assert.Equal(t, runners.Server(app.Server), app.Runner())
+
+	_, err = NewServerApp(&configs.ServerConfig{Address: ":8443", TLSCertFile: certFile})
+	assert.ErrorIs(t, err, errors.ErrIncompleteTLSKeyPair)
+
+	_, err = NewServerApp(&configs.ServerConfig{Address: ":8443", TLSCertFile: keyFile, TLSKeyFile: certFile})
+	assert.ErrorContains(t, err, "load TLS key pair")
+}
+
+func TestNewServerApp_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutils.SelfSignedCert(t, dir)
+	agentCert, agentKey := testutils.ClientCert(t, dir, certFile, keyFile, "agent-a")
+	otherCert, otherKey := testutils.ClientCert(t, dir, certFile, keyFile, "agent-b")
+
+	app, err := NewServerApp(&configs.ServerConfig{
+		Address:          ":8443",
+		TLSCertFile:      certFile,
+		TLSKeyFile:       keyFile,
+		TLSClientCAFile:  certFile,
+		IdentityPrefixes: []string{"agent-a=app."},
+	})
+	require.NoError(t, err)
+
+	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	ts := httptest.NewUnstartedServer(app.Server.Handler)
+	ts.TLS = app.Server.TLSConfig.Clone()
+	ts.TLS.Certificates = []tls.Certificate{serverCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	newClient := func(cert, key string) *resty.Client {
+		client, err := newAgentClient(&configs.AgentConfig{TLSCAFile: certFile, TLSCertFile: cert, TLSKeyFile: key})
+		require.NoError(t, err)
+		return client
+	}
+
+	tests := []struct {
+		name       string
+		client     *resty.Client
+		path       string
+		wantStatus int
+	}{
+		{"allowed prefix", newClient(agentCert, agentKey), "/update/counter/app.hits/1", http.StatusOK},
+		{"other prefix", newClient(agentCert, agentKey), "/update/counter/db.hits/1", http.StatusForbidden},
+		{"identity without prefixes", newClient(otherCert, otherKey), "/update/counter/app.hits/1", http.StatusForbidden},
+		{"batch with one disallowed name", newClient(agentCert, agentKey), "/updates/", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.client.R()
+			if tt.path == "/updates/" {
+				req.SetHeader("Content-Type", "application/json").
+					SetBody(`[{"id":"app.hits","type":"counter","delta":1},{"id":"cpu","type":"gauge","value":1}]`)
+			}
+			resp, err := req.Post(ts.URL + tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode())
+		})
+	}
+
+	t.Run("reads are not restricted", func(t *testing.T) {
+		resp, err := newClient(otherCert, otherKey).R().Get(ts.URL + "/value/counter/app.hits")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("client without certificate is refused", func(t *testing.T) {
+		_, err := newClient("", "").R().Post(ts.URL + "/update/counter/app.hits/1")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewServerApp_ClientAuthConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutils.SelfSignedCert(t, dir)
+
+	tests := []struct {
+		name    string
+		config  *configs.ServerConfig
+		wantErr error
+	}{
+		{
+			name:    "client CA without TLS",
+			config:  &configs.ServerConfig{TLSClientCAFile: certFile},
+			wantErr: errors.ErrClientCAWithoutTLS,
+		},
+		{
+			name:    "client CA is not a CA bundle",
+			config:  &configs.ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: keyFile},
+			wantErr: errors.ErrInvalidCABundle,
+		},
+		{
+			name:    "identity prefixes without client CA",
+			config:  &configs.ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, IdentityPrefixes: []string{"agent-a=app."}},
+			wantErr: errors.ErrIdentityPrefixesWithoutClient,
+		},
+		{
+			name:    "invalid identity prefix",
+			config:  &configs.ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: certFile, IdentityPrefixes: []string{"agent-a"}},
+			wantErr: errors.ErrInvalidIdentityPrefix,
+		},
+		{
+			name: "identity prefixes on a replica",
+			config: &configs.ServerConfig{
+				TLSCertFile:      certFile,
+				TLSKeyFile:       keyFile,
+				TLSClientCAFile:  certFile,
+				IdentityPrefixes: []string{"agent-a=app."},
+				ReplicateFrom:    "leader:8080",
+			},
+			wantErr: errors.ErrReplicaIdentityPrefixes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServerApp(tt.config)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package apps
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/configs"
+)
+
+// newUpstreamTLSConfig returns the TLS settings used to reach forwarding
+// upstreams and the replication leader, or nil when none are configured.
+func newUpstreamTLSConfig(config *configs.ServerConfig) (*tls.Config, error) {
+	if config.UpstreamTLSCAFile == "" && config.UpstreamTLSCertFile == "" && config.UpstreamTLSKeyFile == "" {
+		return nil, nil
+	}
+	return newClientTLSConfig(config.UpstreamTLSCAFile, config.UpstreamTLSCertFile, config.UpstreamTLSKeyFile, false)
+}
+
+// newUpstreamClient returns a client for upstreams and the leader that
+// sends UpstreamToken and uses tlsConfig when set.
+func newUpstreamClient(config *configs.ServerConfig, tlsConfig *tls.Config) *resty.Client {
+	client := resty.New()
+	if config.UpstreamToken != "" {
+		client.SetAuthToken(config.UpstreamToken)
+	}
+	if tlsConfig != nil {
+		client.SetTLSClientConfig(tlsConfig)
+	}
+	return client
+}
+
+// newUpstreamTransport returns the transport writes are proxied to the
+// leader with, or nil for the default one.
+func newUpstreamTransport(tlsConfig *tls.Config) http.RoundTripper {
+	if tlsConfig == nil {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// upstreamAddress defaults addr to HTTPS when upstream TLS options are set
+// and it has no scheme.
+func upstreamAddress(tlsConfig *tls.Config, addr string) string {
+	if tlsConfig == nil || strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return addr
+	}
+	return "https://" + addr
+}
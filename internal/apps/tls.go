@@ -0,0 +1,52 @@
+package apps
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+)
+
+// appendCABundle adds the PEM certificates in path to pool.
+func appendCABundle(pool *x509.CertPool, path string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidCABundle, path)
+	}
+	return pool, nil
+}
+
+// newClientTLSConfig returns the TLS settings of an outgoing client. It
+// trusts the CA bundle in caFile in addition to the system roots and
+// presents the certificate in certFile when a server requires one.
+func newClientTLSConfig(caFile, certFile, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if tlsConfig.RootCAs, err = appendCABundle(pool, caFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.ErrIncompleteTLSKeyPair
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
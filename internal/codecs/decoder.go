@@ -0,0 +1,199 @@
+package codecs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const maxNDJSONLineSize = 1 << 20
+
+// MetricsDecoder reads metrics one at a time. Decode returns io.EOF once the
+// input is exhausted. A *RowError means a single row was malformed and
+// decoding may continue; any other error is fatal.
+type MetricsDecoder interface {
+	Decode() (types.Metrics, error)
+}
+
+// RowError reports a row that could not be decoded. Rows are numbered from 1
+// and do not count the CSV header.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+func NewMetricsDecoder(r io.Reader, format string) (MetricsDecoder, error) {
+	switch format {
+	case types.ExportFormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return &csvDecoder{r: reader}, nil
+	case types.ExportFormatJSON:
+		return &jsonArrayDecoder{dec: json.NewDecoder(r)}, nil
+	case types.ExportFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+		return &ndjsonDecoder{scanner: scanner}, nil
+	default:
+		return nil, internalErrors.ErrInvalidExportFormat
+	}
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func (d *csvDecoder) Decode() (types.Metrics, error) {
+	if d.columns == nil {
+		if err := d.readHeader(); err != nil {
+			return types.Metrics{}, err
+		}
+	}
+
+	record, err := d.r.Read()
+	if err != nil {
+		return types.Metrics{}, err
+	}
+	d.row++
+
+	field := func(name string) string {
+		i, ok := d.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	metric := types.Metrics{
+		ID:    field("id"),
+		MType: field("type"),
+	}
+
+	if s := field("delta"); s != "" {
+		delta, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return metric, &RowError{Row: d.row, Err: fmt.Errorf("delta: %w", err)}
+		}
+		metric.Delta = &delta
+	}
+	if s := field("value"); s != "" {
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return metric, &RowError{Row: d.row, Err: fmt.Errorf("value: %w", err)}
+		}
+		metric.Value = &value
+	}
+	if s := field("updated_at"); s != "" {
+		updatedAt, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return metric, &RowError{Row: d.row, Err: fmt.Errorf("updated_at: %w", err)}
+		}
+		metric.UpdatedAt = updatedAt
+	}
+
+	return metric, nil
+}
+
+func (d *csvDecoder) readHeader() error {
+	header, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	d.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		d.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{"id", "type"} {
+		if _, ok := d.columns[required]; !ok {
+			return fmt.Errorf("csv header: missing %q column", required)
+		}
+	}
+
+	return nil
+}
+
+type jsonArrayDecoder struct {
+	dec     *json.Decoder
+	started bool
+	row     int
+}
+
+func (d *jsonArrayDecoder) Decode() (types.Metrics, error) {
+	if !d.started {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return types.Metrics{}, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return types.Metrics{}, fmt.Errorf("json: expected array, got %v", tok)
+		}
+		d.started = true
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return types.Metrics{}, err
+		}
+		return types.Metrics{}, io.EOF
+	}
+	d.row++
+
+	var metric types.Metrics
+	if err := d.dec.Decode(&metric); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return metric, &RowError{Row: d.row, Err: err}
+		}
+		return types.Metrics{}, err
+	}
+
+	return metric, nil
+}
+
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (d *ndjsonDecoder) Decode() (types.Metrics, error) {
+	for d.scanner.Scan() {
+		line := strings.TrimSpace(d.scanner.Text())
+		if line == "" {
+			continue
+		}
+		d.row++
+
+		var metric types.Metrics
+		if err := json.Unmarshal([]byte(line), &metric); err != nil {
+			return metric, &RowError{Row: d.row, Err: err}
+		}
+		return metric, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return types.Metrics{}, err
+	}
+	return types.Metrics{}, io.EOF
+}
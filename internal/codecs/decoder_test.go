@@ -0,0 +1,169 @@
+package codecs
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type decoded struct {
+	metrics   []types.Metrics
+	rowErrors []int
+}
+
+func decodeAll(t *testing.T, input string, format string) (decoded, error) {
+	t.Helper()
+
+	dec, err := NewMetricsDecoder(strings.NewReader(input), format)
+	require.NoError(t, err)
+
+	var out decoded
+	for {
+		m, err := dec.Decode()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			rowErr, ok := err.(*RowError)
+			if !ok {
+				return out, err
+			}
+			out.rowErrors = append(out.rowErrors, rowErr.Row)
+			continue
+		}
+		out.metrics = append(out.metrics, m)
+	}
+}
+
+func TestMetricsDecoder(t *testing.T) {
+	delta := int64(5)
+	value := 1.25
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	want := []types.Metrics{
+		{ID: "hits", MType: types.Counter, Delta: &delta, UpdatedAt: updatedAt},
+		{ID: "load, avg", MType: types.Gauge, Value: &value},
+	}
+
+	tests := []struct {
+		name          string
+		format        string
+		input         string
+		wantMetrics   []types.Metrics
+		wantRowErrors []int
+		wantErr       bool
+	}{
+		{
+			name:   "csv",
+			format: types.ExportFormatCSV,
+			input: "id,type,delta,value,updated_at\n" +
+				"hits,counter,5,,2024-01-02T03:04:05Z\n" +
+				"\"load, avg\",gauge,,1.25,\n",
+			wantMetrics: want,
+		},
+		{
+			name:   "csv with reordered columns and bad rows",
+			format: types.ExportFormatCSV,
+			input: "type,id,value,delta\n" +
+				"counter,hits,,5x\n" +
+				"gauge,\"load, avg\",1.25\n" +
+				"gauge,bad,NaN?,\n",
+			wantMetrics:   []types.Metrics{{ID: "load, avg", MType: types.Gauge, Value: &value}},
+			wantRowErrors: []int{1, 3},
+		},
+		{
+			name:    "csv without id column",
+			format:  types.ExportFormatCSV,
+			input:   "name,type\nhits,counter\n",
+			wantErr: true,
+		},
+		{
+			name:        "json",
+			format:      types.ExportFormatJSON,
+			input:       `[{"id":"hits","type":"counter","delta":5,"updated_at":"2024-01-02T03:04:05Z"},{"id":"load, avg","type":"gauge","value":1.25}]`,
+			wantMetrics: want,
+		},
+		{
+			name:          "json with mistyped row",
+			format:        types.ExportFormatJSON,
+			input:         `[{"id":"hits","type":"counter","delta":"5"},{"id":"load, avg","type":"gauge","value":1.25}]`,
+			wantMetrics:   []types.Metrics{{ID: "load, avg", MType: types.Gauge, Value: &value}},
+			wantRowErrors: []int{1},
+		},
+		{
+			name:    "json not an array",
+			format:  types.ExportFormatJSON,
+			input:   `{"id":"hits"}`,
+			wantErr: true,
+		},
+		{
+			name:    "json truncated",
+			format:  types.ExportFormatJSON,
+			input:   `[{"id":"hits",`,
+			wantErr: true,
+		},
+		{
+			name:   "ndjson skips blank lines and reports bad ones",
+			format: types.ExportFormatNDJSON,
+			input: `{"id":"hits","type":"counter","delta":5,"updated_at":"2024-01-02T03:04:05Z"}` + "\n\n" +
+				"not json\n" +
+				`{"id":"load, avg","type":"gauge","value":1.25}` + "\n",
+			wantMetrics:   want,
+			wantRowErrors: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeAll(t, tt.input, tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMetrics, got.metrics)
+			assert.Equal(t, tt.wantRowErrors, got.rowErrors)
+		})
+	}
+}
+
+func TestMetricsDecoder_RoundTrip(t *testing.T) {
+	delta := int64(42)
+	value := -3.5
+	metrics := []types.Metrics{
+		{ID: "a", MType: types.Counter, Delta: &delta},
+		{ID: "b", MType: types.Gauge, Value: &value, UpdatedAt: time.Date(2024, 6, 1, 0, 0, 0, 123, time.UTC)},
+	}
+
+	for _, format := range []string{types.ExportFormatCSV, types.ExportFormatJSON, types.ExportFormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := NewMetricsEncoder(&buf, format)
+			require.NoError(t, err)
+			for _, m := range metrics {
+				require.NoError(t, enc.Encode(m))
+			}
+			require.NoError(t, enc.Close())
+
+			got, err := decodeAll(t, buf.String(), format)
+			require.NoError(t, err)
+			assert.Empty(t, got.rowErrors)
+			assert.Equal(t, metrics, got.metrics)
+		})
+	}
+}
+
+func TestNewMetricsDecoder_InvalidFormat(t *testing.T) {
+	dec, err := NewMetricsDecoder(strings.NewReader(""), "xml")
+	assert.Nil(t, dec)
+	assert.ErrorIs(t, err, errors.ErrInvalidExportFormat)
+}
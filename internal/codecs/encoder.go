@@ -0,0 +1,136 @@
+package codecs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+var csvHeader = []string{"id", "type", "delta", "value", "updated_at"}
+
+// MetricsEncoder writes metrics one at a time. Close must be called to
+// terminate the document and flush buffered output; it does not close the
+// underlying writer.
+type MetricsEncoder interface {
+	Encode(metric types.Metrics) error
+	Close() error
+}
+
+func NewMetricsEncoder(w io.Writer, format string) (MetricsEncoder, error) {
+	switch format {
+	case types.ExportFormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case types.ExportFormatJSON:
+		return &jsonArrayEncoder{w: bufio.NewWriter(w)}, nil
+	case types.ExportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, errors.ErrInvalidExportFormat
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case types.ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case types.ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(metric types.Metrics) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	record := []string{metric.ID, metric.MType, "", "", ""}
+	if metric.Delta != nil {
+		record[2] = strconv.FormatInt(*metric.Delta, 10)
+	}
+	if metric.Value != nil {
+		record[3] = strconv.FormatFloat(*metric.Value, 'f', -1, 64)
+	}
+	if !metric.UpdatedAt.IsZero() {
+		record[4] = metric.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.w.Write(csvHeader)
+}
+
+type jsonArrayEncoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (e *jsonArrayEncoder) Encode(metric types.Metrics) error {
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	e.count++
+
+	if _, err := e.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(metric)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonArrayEncoder) Close() error {
+	end := "]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	if _, err := e.w.WriteString(end); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(metric types.Metrics) error {
+	return e.enc.Encode(metric)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return e.w.Flush()
+}
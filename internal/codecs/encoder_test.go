@@ -0,0 +1,94 @@
+package codecs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricsEncoder(t *testing.T) {
+	delta := int64(5)
+	value := 1.25
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	metrics := []types.Metrics{
+		{ID: "hits", MType: types.Counter, Delta: &delta, UpdatedAt: updatedAt},
+		{ID: "load, avg", MType: types.Gauge, Value: &value},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		metrics []types.Metrics
+		want    string
+	}{
+		{
+			name:    "csv",
+			format:  types.ExportFormatCSV,
+			metrics: metrics,
+			want: "id,type,delta,value,updated_at\n" +
+				"hits,counter,5,,2024-01-02T03:04:05Z\n" +
+				"\"load, avg\",gauge,,1.25,\n",
+		},
+		{
+			name:    "csv empty has header",
+			format:  types.ExportFormatCSV,
+			metrics: nil,
+			want:    "id,type,delta,value,updated_at\n",
+		},
+		{
+			name:    "json",
+			format:  types.ExportFormatJSON,
+			metrics: metrics,
+			want: `[{"id":"hits","type":"counter","delta":5,"updated_at":"2024-01-02T03:04:05Z"},` +
+				`{"id":"load, avg","type":"gauge","value":1.25}]` + "\n",
+		},
+		{
+			name:    "json empty",
+			format:  types.ExportFormatJSON,
+			metrics: nil,
+			want:    "[]\n",
+		},
+		{
+			name:    "ndjson",
+			format:  types.ExportFormatNDJSON,
+			metrics: metrics,
+			want: `{"id":"hits","type":"counter","delta":5,"updated_at":"2024-01-02T03:04:05Z"}` + "\n" +
+				`{"id":"load, avg","type":"gauge","value":1.25}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			enc, err := NewMetricsEncoder(&buf, tt.format)
+			require.NoError(t, err)
+
+			for _, m := range tt.metrics {
+				require.NoError(t, enc.Encode(m))
+			}
+			require.NoError(t, enc.Close())
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestNewMetricsEncoder_InvalidFormat(t *testing.T) {
+	enc, err := NewMetricsEncoder(&bytes.Buffer{}, "xml")
+	assert.Nil(t, enc)
+	assert.ErrorIs(t, err, errors.ErrInvalidExportFormat)
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "text/csv; charset=utf-8", ContentType(types.ExportFormatCSV))
+	assert.Equal(t, "application/json", ContentType(types.ExportFormatJSON))
+	assert.Equal(t, "application/x-ndjson", ContentType(types.ExportFormatNDJSON))
+}
@@ -0,0 +1,107 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// Collector is a source of agent metrics. A zero Interval means the agent's
+// poll interval.
+type Collector interface {
+	Name() string
+	Interval() time.Duration
+	Collect(ctx context.Context) ([]types.MetricsUpdatePathRequest, error)
+}
+
+// Factory builds a collector from the options given for it in the agent
+// configuration. The interval option is handled by the registry and is not
+// passed on.
+type Factory func(interval time.Duration, options map[string]string) (Collector, error)
+
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// NewDefaultRegistry returns a registry with the built-in collectors.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(RuntimeCollectorName, NewRuntimeCollector)
+	r.Register(PollCountCollectorName, NewPollCountCollector)
+	return r
+}
+
+// Register adds or replaces the factory for name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// Build creates the collectors enabled by specs, in order. A spec is a
+// collector name optionally followed by colon-separated key=value options,
+// e.g. "runtime" or "poll_count:interval=5:name=Polls"; interval is in
+// seconds. Collectors without a spec are disabled.
+func (r *Registry) Build(specs []string) ([]Collector, error) {
+	collectors := make([]Collector, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		parts := strings.Split(spec, ":")
+		name := parts[0]
+
+		factory, ok := r.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errors.ErrUnknownCollector, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %q", errors.ErrDuplicateCollector, name)
+		}
+		seen[name] = true
+
+		var interval time.Duration
+		options := make(map[string]string, len(parts)-1)
+		for _, part := range parts[1:] {
+			key, value, ok := strings.Cut(part, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("%w: %s: %q", errors.ErrInvalidCollectorOption, name, part)
+			}
+			if key != "interval" {
+				options[key] = value
+				continue
+			}
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return nil, fmt.Errorf("%w: %s: %q", errors.ErrInvalidCollectorOption, name, part)
+			}
+			interval = time.Duration(seconds) * time.Second
+		}
+
+		collector, err := factory(interval, options)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		collectors = append(collectors, collector)
+	}
+
+	return collectors, nil
+}
+
+func unknownOption(options map[string]string) error {
+	for key := range options {
+		return fmt.Errorf("%w: %q", errors.ErrInvalidCollectorOption, key)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestRegistry_Build(t *testing.T) {
+	tests := []struct {
+		name          string
+		specs         []string
+		wantNames     []string
+		wantIntervals []time.Duration
+		wantErr       error
+	}{
+		{
+			name:          "defaults",
+			specs:         []string{"runtime", "poll_count"},
+			wantNames:     []string{RuntimeCollectorName, PollCountCollectorName},
+			wantIntervals: []time.Duration{0, 0},
+		},
+		{
+			name:          "interval and options",
+			specs:         []string{" poll_count:interval=5:name=Polls", "runtime:random=false"},
+			wantNames:     []string{PollCountCollectorName, RuntimeCollectorName},
+			wantIntervals: []time.Duration{5 * time.Second, 0},
+		},
+		{
+			name:          "disabled by omission",
+			specs:         []string{"poll_count", ""},
+			wantNames:     []string{PollCountCollectorName},
+			wantIntervals: []time.Duration{0},
+		},
+		{name: "none", specs: nil, wantNames: []string{}, wantIntervals: []time.Duration{}},
+		{name: "unknown collector", specs: []string{"disk"}, wantErr: errors.ErrUnknownCollector},
+		{name: "duplicate collector", specs: []string{"runtime", "runtime:random=false"}, wantErr: errors.ErrDuplicateCollector},
+		{name: "malformed option", specs: []string{"runtime:random"}, wantErr: errors.ErrInvalidCollectorOption},
+		{name: "invalid interval", specs: []string{"runtime:interval=0"}, wantErr: errors.ErrInvalidCollectorOption},
+		{name: "invalid option value", specs: []string{"runtime:random=maybe"}, wantErr: errors.ErrInvalidCollectorOption},
+		{name: "unknown option", specs: []string{"poll_count:unit=s"}, wantErr: errors.ErrInvalidCollectorOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collectors, err := NewDefaultRegistry().Build(tt.specs)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, collectors)
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(collectors))
+			intervals := make([]time.Duration, 0, len(collectors))
+			for _, c := range collectors {
+				names = append(names, c.Name())
+				intervals = append(intervals, c.Interval())
+			}
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantIntervals, intervals)
+		})
+	}
+}
+
+type staticCollector struct{}
+
+func (staticCollector) Name() string            { return "static" }
+func (staticCollector) Interval() time.Duration { return time.Minute }
+func (staticCollector) Collect(context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	return []types.MetricsUpdatePathRequest{{MType: types.Gauge, Name: "Static", Value: "1"}}, nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	r.Register("static", func(time.Duration, map[string]string) (Collector, error) {
+		return staticCollector{}, nil
+	})
+
+	collectors, err := r.Build([]string{"static"})
+	require.NoError(t, err)
+	require.Len(t, collectors, 1)
+
+	metrics, err := collectors[0].Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Static", metrics[0].Name)
+
+	_, err = r.Build([]string{"runtime"})
+	assert.ErrorIs(t, err, errors.ErrUnknownCollector)
+}
@@ -0,0 +1,91 @@
+package collectors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const RelayCollectorName = "relay"
+
+// RelayCollector holds metrics pushed by local applications through the
+// agent sidecar listener until the next poll. Between polls a gauge keeps
+// only its latest value and a counter the sum of its deltas, so memory is
+// bounded by the number of distinct metrics rather than the request rate.
+type RelayCollector struct {
+	mu      sync.Mutex
+	order   []types.MetricID
+	metrics map[types.MetricID]types.Metrics
+}
+
+func NewRelayCollector() *RelayCollector {
+	return &RelayCollector{metrics: make(map[types.MetricID]types.Metrics)}
+}
+
+func (c *RelayCollector) Name() string            { return RelayCollectorName }
+func (c *RelayCollector) Interval() time.Duration { return 0 }
+
+// Update buffers already validated metrics for the next Collect.
+func (c *RelayCollector) Update(_ context.Context, metrics []types.Metrics) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, metric := range metrics {
+		id := types.MetricID{ID: metric.ID, MType: metric.MType}
+
+		prev, ok := c.metrics[id]
+
+		next := types.Metrics{ID: metric.ID, MType: metric.MType}
+		switch {
+		case metric.MType == types.Counter && metric.Delta != nil:
+			delta := *metric.Delta
+			if ok {
+				delta += *prev.Delta
+			}
+			next.Delta = &delta
+		case metric.MType == types.Gauge && metric.Value != nil:
+			value := *metric.Value
+			next.Value = &value
+		default:
+			continue
+		}
+
+		if !ok {
+			c.order = append(c.order, id)
+		}
+		c.metrics[id] = next
+	}
+	return nil
+}
+
+// Collect returns the buffered metrics in first-seen order and empties the
+// buffer.
+func (c *RelayCollector) Collect(_ context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := make([]types.MetricsUpdatePathRequest, 0, len(c.order))
+	for _, id := range c.order {
+		metric := c.metrics[id]
+
+		var value string
+		switch metric.MType {
+		case types.Counter:
+			value = intToString(*metric.Delta)
+		case types.Gauge:
+			value = floatToString(*metric.Value)
+		}
+		metrics = append(metrics, types.MetricsUpdatePathRequest{
+			Name:  metric.ID,
+			MType: metric.MType,
+			Value: value,
+		})
+	}
+
+	c.order = c.order[:0]
+	clear(c.metrics)
+
+	return metrics, nil
+}
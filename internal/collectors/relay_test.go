@@ -0,0 +1,43 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestRelayCollector(t *testing.T) {
+	ctx := context.Background()
+	c := NewRelayCollector()
+
+	assert.Equal(t, RelayCollectorName, c.Name())
+	assert.Zero(t, c.Interval())
+
+	delta := func(v int64) *int64 { return &v }
+	value := func(v float64) *float64 { return &v }
+
+	require.NoError(t, c.Update(ctx, []types.Metrics{
+		{ID: "Requests", MType: types.Counter, Delta: delta(2)},
+		{ID: "Temp", MType: types.Gauge, Value: value(1.5)},
+		{ID: "Empty", MType: types.Gauge},
+	}))
+	require.NoError(t, c.Update(ctx, []types.Metrics{
+		{ID: "Requests", MType: types.Counter, Delta: delta(3)},
+		{ID: "Temp", MType: types.Gauge, Value: value(2.25)},
+	}))
+
+	metrics, err := c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []types.MetricsUpdatePathRequest{
+		{Name: "Requests", MType: types.Counter, Value: "5"},
+		{Name: "Temp", MType: types.Gauge, Value: "2.25"},
+	}, metrics)
+
+	metrics, err = c.Collect(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+}
@@ -0,0 +1,130 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const (
+	RuntimeCollectorName   = "runtime"
+	PollCountCollectorName = "poll_count"
+)
+
+// RuntimeCollector reports runtime.MemStats as gauges, plus RandomValue
+// unless the random option is false.
+type RuntimeCollector struct {
+	interval time.Duration
+	random   bool
+}
+
+func NewRuntimeCollector(interval time.Duration, options map[string]string) (Collector, error) {
+	c := &RuntimeCollector{interval: interval, random: true}
+
+	if v, ok := options["random"]; ok {
+		random, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: random=%q", errors.ErrInvalidCollectorOption, v)
+		}
+		c.random = random
+		delete(options, "random")
+	}
+
+	if err := unknownOption(options); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *RuntimeCollector) Name() string            { return RuntimeCollectorName }
+func (c *RuntimeCollector) Interval() time.Duration { return c.interval }
+
+func (c *RuntimeCollector) Collect(_ context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	metrics := []types.MetricsUpdatePathRequest{
+		{MType: types.Gauge, Name: "Alloc", Value: floatToString(float64(memStats.Alloc))},
+		{MType: types.Gauge, Name: "BuckHashSys", Value: floatToString(float64(memStats.BuckHashSys))},
+		{MType: types.Gauge, Name: "Frees", Value: floatToString(float64(memStats.Frees))},
+		{MType: types.Gauge, Name: "GCCPUFraction", Value: floatToString(memStats.GCCPUFraction)},
+		{MType: types.Gauge, Name: "GCSys", Value: floatToString(float64(memStats.GCSys))},
+		{MType: types.Gauge, Name: "HeapAlloc", Value: floatToString(float64(memStats.HeapAlloc))},
+		{MType: types.Gauge, Name: "HeapIdle", Value: floatToString(float64(memStats.HeapIdle))},
+		{MType: types.Gauge, Name: "HeapInuse", Value: floatToString(float64(memStats.HeapInuse))},
+		{MType: types.Gauge, Name: "HeapObjects", Value: floatToString(float64(memStats.HeapObjects))},
+		{MType: types.Gauge, Name: "HeapReleased", Value: floatToString(float64(memStats.HeapReleased))},
+		{MType: types.Gauge, Name: "HeapSys", Value: floatToString(float64(memStats.HeapSys))},
+		{MType: types.Gauge, Name: "LastGC", Value: floatToString(float64(memStats.LastGC))},
+		{MType: types.Gauge, Name: "Lookups", Value: floatToString(float64(memStats.Lookups))},
+		{MType: types.Gauge, Name: "MCacheInuse", Value: floatToString(float64(memStats.MCacheInuse))},
+		{MType: types.Gauge, Name: "MCacheSys", Value: floatToString(float64(memStats.MCacheSys))},
+		{MType: types.Gauge, Name: "MSpanInuse", Value: floatToString(float64(memStats.MSpanInuse))},
+		{MType: types.Gauge, Name: "MSpanSys", Value: floatToString(float64(memStats.MSpanSys))},
+		{MType: types.Gauge, Name: "Mallocs", Value: floatToString(float64(memStats.Mallocs))},
+		{MType: types.Gauge, Name: "NextGC", Value: floatToString(float64(memStats.NextGC))},
+		{MType: types.Gauge, Name: "NumForcedGC", Value: floatToString(float64(memStats.NumForcedGC))},
+		{MType: types.Gauge, Name: "NumGC", Value: floatToString(float64(memStats.NumGC))},
+		{MType: types.Gauge, Name: "OtherSys", Value: floatToString(float64(memStats.OtherSys))},
+		{MType: types.Gauge, Name: "PauseTotalNs", Value: floatToString(float64(memStats.PauseTotalNs))},
+		{MType: types.Gauge, Name: "StackInuse", Value: floatToString(float64(memStats.StackInuse))},
+		{MType: types.Gauge, Name: "StackSys", Value: floatToString(float64(memStats.StackSys))},
+		{MType: types.Gauge, Name: "Sys", Value: floatToString(float64(memStats.Sys))},
+		{MType: types.Gauge, Name: "TotalAlloc", Value: floatToString(float64(memStats.TotalAlloc))},
+	}
+
+	if c.random {
+		metrics = append(metrics, types.MetricsUpdatePathRequest{
+			MType: types.Gauge, Name: "RandomValue", Value: floatToString(rand.Float64() * 100),
+		})
+	}
+
+	return metrics, nil
+}
+
+// PollCountCollector increments a counter, PollCount unless the name option
+// says otherwise, on every poll.
+type PollCountCollector struct {
+	interval time.Duration
+	name     string
+}
+
+func NewPollCountCollector(interval time.Duration, options map[string]string) (Collector, error) {
+	c := &PollCountCollector{interval: interval, name: "PollCount"}
+
+	if v, ok := options["name"]; ok {
+		if v == "" {
+			return nil, fmt.Errorf("%w: name is empty", errors.ErrInvalidCollectorOption)
+		}
+		c.name = v
+		delete(options, "name")
+	}
+
+	if err := unknownOption(options); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *PollCountCollector) Name() string            { return PollCountCollectorName }
+func (c *PollCountCollector) Interval() time.Duration { return c.interval }
+
+func (c *PollCountCollector) Collect(_ context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	return []types.MetricsUpdatePathRequest{
+		{MType: types.Counter, Name: c.name, Value: intToString(1)},
+	}, nil
+}
+
+func intToString(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+func floatToString(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
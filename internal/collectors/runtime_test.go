@@ -0,0 +1,58 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestRuntimeCollector_Collect(t *testing.T) {
+	c, err := NewRuntimeCollector(0, map[string]string{})
+	require.NoError(t, err)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, metrics, 28)
+	for _, m := range metrics {
+		assert.Equal(t, types.Gauge, m.MType)
+		assert.NotEmpty(t, m.Name)
+		assert.NotEmpty(t, m.Value)
+	}
+	assert.Equal(t, "RandomValue", metrics[len(metrics)-1].Name)
+}
+
+func TestRuntimeCollector_WithoutRandom(t *testing.T) {
+	c, err := NewRuntimeCollector(0, map[string]string{"random": "false"})
+	require.NoError(t, err)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, metrics, 27)
+	for _, m := range metrics {
+		assert.NotEqual(t, "RandomValue", m.Name)
+	}
+}
+
+func TestPollCountCollector_Collect(t *testing.T) {
+	c, err := NewPollCountCollector(0, map[string]string{})
+	require.NoError(t, err)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, metrics, 1)
+	assert.Equal(t, types.MetricsUpdatePathRequest{MType: types.Counter, Name: "PollCount", Value: "1"}, metrics[0])
+
+	c, err = NewPollCountCollector(0, map[string]string{"name": "Polls"})
+	require.NoError(t, err)
+
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Polls", metrics[0].Name)
+}
@@ -0,0 +1,111 @@
+package collectors
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const (
+	TelemetryCollectorName = "telemetry"
+
+	defaultTelemetryPrefix = "agent_"
+)
+
+// AgentTelemetry records how the agent itself is doing. The report worker
+// updates it and a telemetry collector reports it through the same pipeline
+// as every other metric.
+type AgentTelemetry struct {
+	sendsAttempted atomic.Int64
+	sendsFailed    atomic.Int64
+	retries        atomic.Int64
+	dropped        atomic.Int64
+	bufferDepth    atomic.Int64
+	lastReport     atomic.Int64
+
+	mu               sync.Mutex
+	collectDurations map[string]time.Duration
+}
+
+func NewAgentTelemetry() *AgentTelemetry {
+	return &AgentTelemetry{collectDurations: make(map[string]time.Duration)}
+}
+
+func (t *AgentTelemetry) SendAttempted()               { t.sendsAttempted.Add(1) }
+func (t *AgentTelemetry) SendFailed()                  { t.sendsFailed.Add(1) }
+func (t *AgentTelemetry) Retried()                     { t.retries.Add(1) }
+func (t *AgentTelemetry) Dropped(n int)                { t.dropped.Add(int64(n)) }
+func (t *AgentTelemetry) SetBufferDepth(n int)         { t.bufferDepth.Store(int64(n)) }
+func (t *AgentTelemetry) ReportSucceeded(at time.Time) { t.lastReport.Store(at.UnixNano()) }
+
+// CollectFinished records how long the last Collect of collector took.
+func (t *AgentTelemetry) CollectFinished(collector string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.collectDurations[collector] = d
+}
+
+// NewCollector is the Factory for the telemetry collector. Its only option
+// is prefix, prepended to every metric name and agent_ by default.
+func (t *AgentTelemetry) NewCollector(interval time.Duration, options map[string]string) (Collector, error) {
+	c := &TelemetryCollector{telemetry: t, interval: interval, prefix: defaultTelemetryPrefix}
+
+	if v, ok := options["prefix"]; ok {
+		c.prefix = v
+		delete(options, "prefix")
+	}
+
+	if err := unknownOption(options); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TelemetryCollector reports AgentTelemetry. Counters carry the change
+// since the previous Collect, so the server sums them into totals.
+type TelemetryCollector struct {
+	telemetry *AgentTelemetry
+	interval  time.Duration
+	prefix    string
+}
+
+func (c *TelemetryCollector) Name() string            { return TelemetryCollectorName }
+func (c *TelemetryCollector) Interval() time.Duration { return c.interval }
+
+func (c *TelemetryCollector) Collect(_ context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	t := c.telemetry
+
+	metrics := []types.MetricsUpdatePathRequest{
+		{MType: types.Counter, Name: c.prefix + "sends_attempted", Value: intToString(t.sendsAttempted.Swap(0))},
+		{MType: types.Counter, Name: c.prefix + "sends_failed", Value: intToString(t.sendsFailed.Swap(0))},
+		{MType: types.Counter, Name: c.prefix + "retries", Value: intToString(t.retries.Swap(0))},
+		{MType: types.Counter, Name: c.prefix + "dropped_metrics", Value: intToString(t.dropped.Swap(0))},
+		{MType: types.Gauge, Name: c.prefix + "buffer_depth", Value: intToString(t.bufferDepth.Load())},
+	}
+
+	if lastReport := t.lastReport.Load(); lastReport != 0 {
+		metrics = append(metrics, types.MetricsUpdatePathRequest{
+			MType: types.Gauge,
+			Name:  c.prefix + "last_report_timestamp_seconds",
+			Value: floatToString(float64(lastReport) / float64(time.Second)),
+		})
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, collector := range slices.Sorted(maps.Keys(t.collectDurations)) {
+		metrics = append(metrics, types.MetricsUpdatePathRequest{
+			MType: types.Gauge,
+			Name:  types.FormatMetricName(c.prefix+"collect_duration_seconds", map[string]string{"collector": collector}),
+			Value: floatToString(t.collectDurations[collector].Seconds()),
+		})
+	}
+
+	return metrics, nil
+}
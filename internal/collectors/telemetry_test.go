@@ -0,0 +1,65 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestTelemetryCollector_Collect(t *testing.T) {
+	telemetry := NewAgentTelemetry()
+
+	c, err := telemetry.NewCollector(0, map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, TelemetryCollectorName, c.Name())
+
+	telemetry.SendAttempted()
+	telemetry.SendAttempted()
+	telemetry.SendAttempted()
+	telemetry.SendFailed()
+	telemetry.Retried()
+	telemetry.Dropped(2)
+	telemetry.SetBufferDepth(7)
+	telemetry.ReportSucceeded(time.Unix(1700000000, 500000000))
+	telemetry.CollectFinished("runtime", 250*time.Millisecond)
+	telemetry.CollectFinished("poll_count", time.Millisecond)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []types.MetricsUpdatePathRequest{
+		{MType: types.Counter, Name: "agent_sends_attempted", Value: "3"},
+		{MType: types.Counter, Name: "agent_sends_failed", Value: "1"},
+		{MType: types.Counter, Name: "agent_retries", Value: "1"},
+		{MType: types.Counter, Name: "agent_dropped_metrics", Value: "2"},
+		{MType: types.Gauge, Name: "agent_buffer_depth", Value: "7"},
+		{MType: types.Gauge, Name: "agent_last_report_timestamp_seconds", Value: "1700000000.5"},
+		{MType: types.Gauge, Name: "agent_collect_duration_seconds{collector=poll_count}", Value: "0.001"},
+		{MType: types.Gauge, Name: "agent_collect_duration_seconds{collector=runtime}", Value: "0.25"},
+	}, metrics)
+
+	metrics, err = c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0", metrics[0].Value, "counters report deltas since the last collect")
+	assert.Equal(t, "7", metrics[4].Value, "gauges keep their value")
+}
+
+func TestTelemetryCollector_Options(t *testing.T) {
+	telemetry := NewAgentTelemetry()
+
+	c, err := telemetry.NewCollector(0, map[string]string{"prefix": "sidecar_"})
+	require.NoError(t, err)
+
+	metrics, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, metrics, 5)
+	assert.Equal(t, "sidecar_sends_attempted", metrics[0].Name)
+
+	_, err = telemetry.NewCollector(0, map[string]string{"unknown": "1"})
+	assert.ErrorIs(t, err, errors.ErrInvalidCollectorOption)
+}
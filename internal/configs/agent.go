@@ -1,12 +1,18 @@
 package configs
 
 type AgentConfig struct {
-	ServerAddress  string
-	ServerEndpoint string
-	LogLevel       string
-	PollInterval   int
-	ReportInterval int
-	NumWorkers     int
+	ServerAddress         string
+	LogLevel              string
+	PollInterval          int
+	ReportInterval        int
+	NumWorkers            int
+	Collectors            []string
+	SidecarAddress        string
+	TLSCAFile             string
+	TLSInsecureSkipVerify bool
+	TLSCertFile           string
+	TLSKeyFile            string
+	APIToken              string
 }
 
 type AgentOption func(*AgentConfig)
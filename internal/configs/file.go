@@ -0,0 +1,77 @@
+package configs
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ApplyFile fills flags of fs from the JSON object in the file at path.
+// keys maps each accepted JSON key to the flag it sets; flags given on the
+// command line keep their values, so the precedence is environment, then
+// command line, then file, then flag default. Arrays are joined with commas
+// for list flags.
+func ApplyFile(fs *flag.FlagSet, path string, keys map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for key, raw := range values {
+		name, ok := keys[key]
+		if !ok {
+			return fmt.Errorf("config file %s: unknown key %q", path, key)
+		}
+		if explicit[name] {
+			continue
+		}
+
+		value, err := fileValue(raw)
+		if err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+	}
+
+	return nil
+}
+
+func fileValue(raw json.RawMessage) (string, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool, float64:
+		return string(raw), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list items must be strings")
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %s", raw)
+	}
+}
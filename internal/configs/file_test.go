@@ -0,0 +1,76 @@
+package configs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFile(t *testing.T) {
+	keys := map[string]string{
+		"address":  "a",
+		"interval": "i",
+		"restore":  "r",
+		"items":    "items",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "file fills flags not set on the command line",
+			content: `{"address":"file:1","interval":0,"restore":false,"items":["x","y"]}`,
+			args:    []string{"-a=cli:2"},
+			want:    map[string]string{"a": "cli:2", "i": "0", "r": "false", "items": "x,y"},
+		},
+		{
+			name:    "missing keys keep defaults",
+			content: `{}`,
+			want:    map[string]string{"a": "default", "i": "300", "r": "true", "items": ""},
+		},
+		{name: "unknown key", content: `{"port":1}`, wantErr: `unknown key "port"`},
+		{name: "invalid value", content: `{"interval":"soon"}`, wantErr: "interval"},
+		{name: "non-string list item", content: `{"items":[1]}`, wantErr: "list items must be strings"},
+		{name: "malformed JSON", content: `{`, wantErr: "parse config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("a", "default", "")
+			fs.Int("i", 300, "")
+			fs.Bool("r", true, "")
+			fs.String("items", "", "")
+			require.NoError(t, fs.Parse(tt.args))
+
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			err := ApplyFile(fs, path, keys)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for name, value := range tt.want {
+				assert.Equal(t, value, fs.Lookup(name).Value.String(), name)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		err := ApplyFile(fs, filepath.Join(t.TempDir(), "absent.json"), keys)
+		assert.ErrorContains(t, err, "read config file")
+	})
+}
@@ -1,8 +1,39 @@
 package configs
 
 type ServerConfig struct {
-	Address  string
-	LogLevel string
+	Address                   string
+	LogLevel                  string
+	FileStoragePath           string
+	StoreInterval             int
+	Restore                   bool
+	WALFsync                  string
+	WALFsyncInterval          int
+	AdminToken                string
+	StorageShards             int
+	StreamBuffer              int
+	StreamSlowPolicy          string
+	StatsDAddress             string
+	GraphiteAddress           string
+	GraphiteCounterRules      []string
+	GraphiteReadTimeout       int
+	InfluxCounterSuffixes     []string
+	ForwardUpstreams          []string
+	ForwardInterval           int
+	ForwardQueueSize          int
+	ReplicateFrom             string
+	ReplicationResyncInterval int
+	UpstreamToken             string
+	UpstreamTLSCAFile         string
+	UpstreamTLSCertFile       string
+	UpstreamTLSKeyFile        string
+	TLSCertFile               string
+	TLSKeyFile                string
+	TLSClientCAFile           string
+	IdentityPrefixes          []string
+	TokensFile                string
+	RateLimit                 int
+	RateBurst                 int
+	MaxInFlight               int
 }
 
 type ServerOption func(*ServerConfig)
@@ -22,3 +22,20 @@ func TestNewServerConfig_WithAddress(t *testing.T) {
 	assert.NotNil(t, cfg)
 	assert.Equal(t, address, cfg.Address)
 }
+
+func TestNewServerConfig_WithStorage(t *testing.T) {
+	opt := func(cfg *ServerConfig) {
+		cfg.FileStoragePath = "/tmp/metrics-db.json"
+		cfg.StoreInterval = 300
+		cfg.Restore = true
+		cfg.WALFsync = "interval"
+		cfg.WALFsyncInterval = 1
+	}
+
+	cfg := NewServerConfig(opt)
+	assert.Equal(t, "/tmp/metrics-db.json", cfg.FileStoragePath)
+	assert.Equal(t, 300, cfg.StoreInterval)
+	assert.True(t, cfg.Restore)
+	assert.Equal(t, "interval", cfg.WALFsync)
+	assert.Equal(t, 1, cfg.WALFsyncInterval)
+}
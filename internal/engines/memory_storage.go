@@ -1,6 +1,7 @@
 package engines
 
 import (
+	"maps"
 	"sync"
 )
 
@@ -15,3 +16,51 @@ func NewMemoryStorage[K comparable, V any]() *MemoryStorage[K, V] {
 		Mu:   &sync.RWMutex{},
 	}
 }
+
+func (s *MemoryStorage[K, V]) Get(key K) (V, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	value, ok := s.Data[key]
+	return value, ok
+}
+
+func (s *MemoryStorage[K, V]) Set(key K, value V, hook func() error) error {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	if err := runHook(hook); err != nil {
+		return err
+	}
+
+	s.Data[key] = value
+	return nil
+}
+
+func (s *MemoryStorage[K, V]) Snapshot(hook func() error) (map[K]V, error) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	data := maps.Clone(s.Data)
+	if err := runHook(hook); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *MemoryStorage[K, V]) Load(data map[K]V, replace bool, hook func() error) error {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	if err := runHook(hook); err != nil {
+		return err
+	}
+
+	if replace {
+		clear(s.Data)
+	}
+	maps.Copy(s.Data, data)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package engines
+
+import (
+	"hash/maphash"
+	"maps"
+)
+
+// ShardedMemoryStorage spreads keys over independently locked MemoryStorage
+// shards, so that writers to different keys do not contend on one mutex.
+// Operations spanning the whole store lock every shard in index order.
+type ShardedMemoryStorage[K comparable, V any] struct {
+	shards []*MemoryStorage[K, V]
+	seed   maphash.Seed
+}
+
+func NewShardedMemoryStorage[K comparable, V any](shards int) *ShardedMemoryStorage[K, V] {
+	shards = max(shards, 1)
+
+	s := &ShardedMemoryStorage[K, V]{
+		shards: make([]*MemoryStorage[K, V], shards),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range s.shards {
+		s.shards[i] = NewMemoryStorage[K, V]()
+	}
+
+	return s
+}
+
+func (s *ShardedMemoryStorage[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+func (s *ShardedMemoryStorage[K, V]) Set(key K, value V, hook func() error) error {
+	return s.shard(key).Set(key, value, hook)
+}
+
+func (s *ShardedMemoryStorage[K, V]) Snapshot(hook func() error) (map[K]V, error) {
+	for _, shard := range s.shards {
+		shard.Mu.RLock()
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.Mu.RUnlock()
+		}
+	}()
+
+	size := 0
+	for _, shard := range s.shards {
+		size += len(shard.Data)
+	}
+
+	data := make(map[K]V, size)
+	for _, shard := range s.shards {
+		maps.Copy(data, shard.Data)
+	}
+
+	if err := runHook(hook); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *ShardedMemoryStorage[K, V]) Load(data map[K]V, replace bool, hook func() error) error {
+	for _, shard := range s.shards {
+		shard.Mu.Lock()
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.Mu.Unlock()
+		}
+	}()
+
+	if err := runHook(hook); err != nil {
+		return err
+	}
+
+	if replace {
+		for _, shard := range s.shards {
+			clear(shard.Data)
+		}
+	}
+
+	for key, value := range data {
+		s.shard(key).Data[key] = value
+	}
+
+	return nil
+}
+
+func (s *ShardedMemoryStorage[K, V]) shard(key K) *MemoryStorage[K, V] {
+	return s.shards[maphash.Comparable(s.seed, key)%uint64(len(s.shards))]
+}
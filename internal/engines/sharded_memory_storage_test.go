@@ -0,0 +1,163 @@
+package engines
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storageEngines() map[string]func() Storage[string, int] {
+	return map[string]func() Storage[string, int]{
+		"memory": func() Storage[string, int] {
+			return NewMemoryStorage[string, int]()
+		},
+		"sharded": func() Storage[string, int] {
+			return NewShardedMemoryStorage[string, int](8)
+		},
+	}
+}
+
+func TestStorage_SetGetSnapshotLoad(t *testing.T) {
+	for name, newStorage := range storageEngines() {
+		t.Run(name, func(t *testing.T) {
+			storage := newStorage()
+
+			require.NoError(t, storage.Set("a", 1, nil))
+			require.NoError(t, storage.Set("b", 2, nil))
+
+			value, ok := storage.Get("a")
+			assert.True(t, ok)
+			assert.Equal(t, 1, value)
+
+			_, ok = storage.Get("missing")
+			assert.False(t, ok)
+
+			snapshot, err := storage.Snapshot(nil)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{"a": 1, "b": 2}, snapshot)
+
+			require.NoError(t, storage.Load(map[string]int{"c": 3}, false, nil))
+			snapshot, err = storage.Snapshot(nil)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, snapshot)
+
+			require.NoError(t, storage.Load(map[string]int{"d": 4}, true, nil))
+			snapshot, err = storage.Snapshot(nil)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{"d": 4}, snapshot)
+		})
+	}
+}
+
+func TestStorage_HookErrorAbortsChange(t *testing.T) {
+	hookErr := errors.New("hook failed")
+
+	for name, newStorage := range storageEngines() {
+		t.Run(name, func(t *testing.T) {
+			storage := newStorage()
+			require.NoError(t, storage.Set("a", 1, nil))
+
+			err := storage.Set("a", 2, func() error { return hookErr })
+			assert.ErrorIs(t, err, hookErr)
+
+			err = storage.Load(map[string]int{"b": 2}, true, func() error { return hookErr })
+			assert.ErrorIs(t, err, hookErr)
+
+			_, err = storage.Snapshot(func() error { return hookErr })
+			assert.ErrorIs(t, err, hookErr)
+
+			snapshot, err := storage.Snapshot(nil)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{"a": 1}, snapshot)
+		})
+	}
+}
+
+func TestShardedMemoryStorage_ConcurrentAccess(t *testing.T) {
+	storage := NewShardedMemoryStorage[string, int](16)
+	var wg sync.WaitGroup
+
+	numGoroutines := 100
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, storage.Set(fmt.Sprintf("key%d", i), i, nil))
+			storage.Get(fmt.Sprintf("key%d", i/2))
+		}(i)
+	}
+	wg.Wait()
+
+	snapshot, err := storage.Snapshot(nil)
+	require.NoError(t, err)
+	assert.Len(t, snapshot, numGoroutines)
+}
+
+func TestNewShardedMemoryStorage_AtLeastOneShard(t *testing.T) {
+	storage := NewShardedMemoryStorage[string, int](0)
+	assert.Len(t, storage.shards, 1)
+	require.NoError(t, storage.Set("a", 1, nil))
+}
+
+// BenchmarkStorage_MixedReadWrite mimics many agents pushing updates while
+// dashboards list the whole store. Run with -cpu to see the effect of contention.
+func BenchmarkStorage_MixedReadWrite(b *testing.B) {
+	const keys = 1024
+
+	engines := []struct {
+		name       string
+		newStorage func() Storage[string, int]
+	}{
+		{"memory", func() Storage[string, int] { return NewMemoryStorage[string, int]() }},
+		{"sharded-16", func() Storage[string, int] { return NewShardedMemoryStorage[string, int](16) }},
+		{"sharded-64", func() Storage[string, int] { return NewShardedMemoryStorage[string, int](64) }},
+	}
+
+	// Percentages of writes and full snapshots; the rest are point reads.
+	workloads := []struct {
+		name      string
+		writes    int
+		snapshots int
+	}{
+		{"writes80-reads20", 80, 0},
+		{"writes80-reads19-lists1", 80, 1},
+	}
+
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = fmt.Sprintf("metric%d", i)
+	}
+
+	for _, workload := range workloads {
+		for _, engine := range engines {
+			b.Run(workload.name+"/"+engine.name, func(b *testing.B) {
+				storage := engine.newStorage()
+				for i, name := range names {
+					storage.Set(name, i, nil)
+				}
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						name := names[i%keys]
+						switch op := i % 100; {
+						case op < workload.snapshots:
+							storage.Snapshot(nil)
+						case op < workload.snapshots+workload.writes:
+							storage.Set(name, i, nil)
+						default:
+							storage.Get(name)
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
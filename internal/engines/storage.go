@@ -0,0 +1,22 @@
+package engines
+
+// Storage is a concurrency-safe in-memory key-value engine.
+//
+// Mutating methods accept an optional hook that runs while the affected
+// keys are write-locked, right before the change is applied; a hook error
+// aborts the change. Snapshot runs its hook while the copied state is still
+// read-locked. Hooks are how callers keep a write-ahead log in step with
+// the data without knowing the engine's locking scheme.
+type Storage[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V, hook func() error) error
+	Snapshot(hook func() error) (map[K]V, error)
+	Load(data map[K]V, replace bool, hook func() error) error
+}
+
+func runHook(hook func() error) error {
+	if hook == nil {
+		return nil
+	}
+	return hook()
+}
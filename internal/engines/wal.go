@@ -0,0 +1,248 @@
+package engines
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+)
+
+const (
+	FsyncAlways   = "always"
+	FsyncInterval = "interval"
+	FsyncNone     = "none"
+)
+
+const walSegmentExt = ".wal"
+
+// WAL is an append-only write-ahead log split into numbered segment files.
+// Every record is a single line; a trailing line without a newline is
+// treated as a torn write and skipped on replay.
+type WAL struct {
+	dir     string
+	fsync   string
+	mu      sync.Mutex
+	file    *os.File
+	segment int
+	dirty   bool
+}
+
+// NewWAL opens the log in dir and starts a fresh segment after the last
+// existing one, so that a torn tail of a previous run is never appended to.
+func NewWAL(dir string, fsync string) (*WAL, error) {
+	switch fsync {
+	case FsyncAlways, FsyncInterval, FsyncNone:
+	default:
+		return nil, errors.ErrInvalidFsyncPolicy
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	segments, err := listWALSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAL{dir: dir, fsync: fsync}
+	if len(segments) > 0 {
+		w.segment = segments[len(segments)-1]
+	}
+
+	if err := w.openSegment(w.segment + 1); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Append writes a record and, with the "always" policy, fsyncs it before returning.
+func (w *WAL) Append(record []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.file.Write(append(record, '\n')); err != nil {
+		return err
+	}
+
+	if w.fsync == FsyncAlways {
+		return w.file.Sync()
+	}
+
+	w.dirty = true
+	return nil
+}
+
+// Sync flushes records appended since the last sync to disk.
+func (w *WAL) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.sync()
+}
+
+// Rotate closes the current segment and starts a new one, returning its number.
+// Records appended after Rotate land in the returned segment or later.
+func (w *WAL) Rotate() (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.sync(); err != nil {
+		return 0, err
+	}
+
+	if err := w.file.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := w.openSegment(w.segment + 1); err != nil {
+		return 0, err
+	}
+
+	return w.segment, nil
+}
+
+// Truncate removes all segments numbered below before.
+func (w *WAL) Truncate(before int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	segments, err := listWALSegments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment >= before || segment == w.segment {
+			continue
+		}
+		if err := os.Remove(w.segmentPath(segment)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replay calls fn for every complete record in segments numbered from and above, in order.
+func (w *WAL) Replay(from int, fn func(record []byte) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	segments, err := listWALSegments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment < from {
+			continue
+		}
+		if err := w.replaySegment(segment, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.sync(); err != nil {
+		return err
+	}
+
+	return w.file.Close()
+}
+
+func (w *WAL) sync() error {
+	if !w.dirty {
+		return nil
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	w.dirty = false
+	return nil
+}
+
+func (w *WAL) openSegment(segment int) error {
+	file, err := os.OpenFile(w.segmentPath(segment), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.segment = segment
+	w.dirty = false
+	return nil
+}
+
+func (w *WAL) replaySegment(segment int, fn func(record []byte) error) error {
+	file, err := os.Open(w.segmentPath(segment))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		record := bytes.TrimSuffix(line, []byte{'\n'})
+		if len(record) == 0 {
+			continue
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *WAL) segmentPath(segment int) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%010d%s", segment, walSegmentExt))
+}
+
+func listWALSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, walSegmentExt) {
+			continue
+		}
+
+		segment, err := strconv.Atoi(strings.TrimSuffix(name, walSegmentExt))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	sort.Ints(segments)
+	return segments, nil
+}
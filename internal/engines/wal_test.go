@@ -0,0 +1,96 @@
+package engines
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replayAll(t *testing.T, w *WAL, from int) []string {
+	t.Helper()
+
+	var records []string
+	err := w.Replay(from, func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	require.NoError(t, err)
+	return records
+}
+
+func TestWAL_AppendAndReplay(t *testing.T) {
+	for _, policy := range []string{FsyncAlways, FsyncInterval, FsyncNone} {
+		t.Run(policy, func(t *testing.T) {
+			w, err := NewWAL(t.TempDir(), policy)
+			require.NoError(t, err)
+			defer w.Close()
+
+			require.NoError(t, w.Append([]byte("first")))
+			require.NoError(t, w.Append([]byte("second")))
+			require.NoError(t, w.Sync())
+
+			assert.Equal(t, []string{"first", "second"}, replayAll(t, w, 0))
+		})
+	}
+}
+
+func TestWAL_InvalidPolicy(t *testing.T) {
+	_, err := NewWAL(t.TempDir(), "sometimes")
+	assert.ErrorIs(t, err, errors.ErrInvalidFsyncPolicy)
+}
+
+func TestWAL_RotateAndTruncate(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWAL(dir, FsyncAlways)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, w.Append([]byte("before")))
+
+	segment, err := w.Rotate()
+	require.NoError(t, err)
+
+	require.NoError(t, w.Append([]byte("after")))
+
+	assert.Equal(t, []string{"before", "after"}, replayAll(t, w, 0))
+	assert.Equal(t, []string{"after"}, replayAll(t, w, segment))
+
+	require.NoError(t, w.Truncate(segment))
+
+	segments, err := listWALSegments(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []int{segment}, segments)
+	assert.Equal(t, []string{"after"}, replayAll(t, w, 0))
+}
+
+func TestWAL_ReopenStartsNewSegmentAndSkipsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWAL(dir, FsyncAlways)
+	require.NoError(t, err)
+	require.NoError(t, w.Append([]byte("complete")))
+	require.NoError(t, w.Close())
+
+	// Simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(filepath.Join(dir, "0000000001.wal"), os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("torn"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	w, err = NewWAL(dir, FsyncAlways)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, w.Append([]byte("next")))
+
+	segments, err := listWALSegments(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, segments)
+	assert.Equal(t, []string{"complete", "next"}, replayAll(t, w, 0))
+}
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrServerUnavailable    = errors.New("server unavailable")
+	ErrInvalidAgentInterval = errors.New("poll and report intervals must be at least 1 second")
+	ErrInvalidAgentWorkers  = errors.New("number of workers must be at least 1")
+)
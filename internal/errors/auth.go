@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidTokenFile = errors.New("invalid token file")
+)
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrUnknownCollector       = errors.New("unknown collector")
+	ErrDuplicateCollector     = errors.New("collector enabled more than once")
+	ErrInvalidCollectorOption = errors.New("invalid collector option")
+)
@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidLogLevel = errors.New("invalid log level")
+)
@@ -0,0 +1,5 @@
+package errors
+
+import "errors"
+
+var ErrInvalidRefreshInterval = errors.New("invalid refresh interval")
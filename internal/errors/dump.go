@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidDumpFormat  = errors.New("invalid dump format")
+	ErrInvalidRestoreMode = errors.New("invalid restore mode")
+	ErrInvalidDump        = errors.New("invalid dump")
+)
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidExportFormat = errors.New("invalid export format")
+	ErrInvalidImportMode   = errors.New("invalid import mode")
+	ErrInvalidImport       = errors.New("invalid import")
+)
@@ -0,0 +1,17 @@
+package errors
+
+import "errors"
+
+var (
+	ErrMalformedLine       = errors.New("malformed line")
+	ErrUnsupportedStatType = errors.New("unsupported stat type")
+	ErrInvalidGraphiteRule = errors.New("invalid graphite counter rule")
+)
+
+var ErrInvalidInfluxPrecision = errors.New("invalid precision")
+
+var (
+	ErrUnsupportedTemporality = errors.New("unsupported aggregation temporality")
+	ErrMissingDataPointValue  = errors.New("missing data point value")
+	ErrUnsupportedMetricData  = errors.New("unsupported metric data type")
+)
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidQueryMatch   = errors.New("invalid query match pattern")
+	ErrInvalidQueryAgg     = errors.New("invalid query aggregation")
+	ErrInvalidQueryGroupBy = errors.New("invalid query group by")
+)
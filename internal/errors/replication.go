@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrReplicationStreamClosed = errors.New("replication stream closed by leader")
+	ErrReplicaListeners        = errors.New("statsd and graphite listeners cannot run on a read-only replica")
+	ErrReplicaIdentityPrefixes = errors.New("identity prefixes cannot be enforced on a read-only replica, set them on the leader")
+)
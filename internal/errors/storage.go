@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidFsyncPolicy = errors.New("invalid fsync policy")
+)
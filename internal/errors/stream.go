@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidSlowConsumerPolicy = errors.New("invalid slow consumer policy")
+	ErrStreamClosed              = errors.New("stream closed")
+)
@@ -0,0 +1,12 @@
+package errors
+
+import "errors"
+
+var (
+	ErrIncompleteTLSKeyPair          = errors.New("TLS certificate and key must be set together")
+	ErrInvalidCABundle               = errors.New("no certificates found in CA bundle")
+	ErrClientCAWithoutTLS            = errors.New("client CA requires a TLS certificate")
+	ErrIdentityPrefixesWithoutClient = errors.New("identity prefixes require a client CA")
+	ErrInvalidIdentityPrefix         = errors.New("invalid identity prefix")
+	ErrMetricForbidden               = errors.New("metric not allowed for client identity")
+)
@@ -0,0 +1,124 @@
+package facades
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const replicationSnapshotTimeout = 30 * time.Second
+
+// MetricReplicationFacade reads a leader server: a full snapshot from its
+// /export endpoint and the change stream from /stream.
+type MetricReplicationFacade struct {
+	client     *resty.Client
+	leaderAddr string
+}
+
+func NewMetricReplicationFacade(client *resty.Client, leaderAddr string) *MetricReplicationFacade {
+	addr := strings.TrimRight(leaderAddr, "/")
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return &MetricReplicationFacade{client: client, leaderAddr: addr}
+}
+
+// Snapshot downloads every metric the leader holds.
+func (f *MetricReplicationFacade) Snapshot(ctx context.Context) ([]types.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, replicationSnapshotTimeout)
+	defer cancel()
+
+	var metrics []types.Metrics
+
+	resp, err := f.client.R().
+		SetContext(ctx).
+		SetQueryParam("format", types.ExportFormatJSON).
+		SetResult(&metrics).
+		Get(f.leaderAddr + "/export")
+
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	if resp.StatusCode() >= http.StatusBadRequest {
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	return metrics, nil
+}
+
+// Subscribe opens the leader's change stream. It returns once the leader has
+// registered the subscription, so a snapshot taken afterwards cannot miss an
+// update. The channel is closed when the stream ends or ctx is cancelled.
+func (f *MetricReplicationFacade) Subscribe(ctx context.Context) (<-chan types.MetricsReplicationMessage, error) {
+	resp, err := f.client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "text/event-stream").
+		SetDoNotParseResponse(true).
+		Get(f.leaderAddr + "/stream")
+
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	body := resp.RawBody()
+	if resp.StatusCode() != http.StatusOK {
+		body.Close()
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode())
+	}
+
+	messages := make(chan types.MetricsReplicationMessage)
+
+	go func() {
+		defer close(messages)
+		defer body.Close()
+
+		send := func(msg types.MetricsReplicationMessage) bool {
+			select {
+			case messages <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var data string
+
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case strings.HasPrefix(line, ": ping "):
+				ts, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(line, ": ping "))
+				if err != nil {
+					continue
+				}
+				if !send(types.MetricsReplicationMessage{Timestamp: ts}) {
+					return
+				}
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && data != "":
+				var event types.MetricsUpdateEvent
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					logger.Log.Warnw("Skipping malformed replication event", "error", err)
+				} else if !send(types.MetricsReplicationMessage{Event: &event, Timestamp: event.Timestamp}) {
+					return
+				}
+				data = ""
+			}
+		}
+	}()
+
+	return messages, nil
+}
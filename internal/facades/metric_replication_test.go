@@ -0,0 +1,82 @@
+package facades
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricReplicationFacade_Snapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/export", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":"hits","type":"counter","delta":3,"updated_at":"2026-01-02T03:04:05Z"}]`)
+	}))
+	defer server.Close()
+
+	facade := NewMetricReplicationFacade(resty.New(), server.URL)
+
+	metrics, err := facade.Snapshot(context.Background())
+	require.NoError(t, err)
+
+	delta := int64(3)
+	assert.Equal(t, []types.Metrics{{
+		ID:        "hits",
+		MType:     types.Counter,
+		Delta:     &delta,
+		UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}, metrics)
+}
+
+func TestMetricReplicationFacade_Subscribe(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stream", r.URL.Path)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\n")
+		fmt.Fprint(w, "event: gauge\ndata: {\"update\":{\"id\":\"temp\",\"type\":\"gauge\",\"value\":1.5},"+
+			"\"saved\":{\"id\":\"temp\",\"type\":\"gauge\",\"value\":1.5},\"timestamp\":\"2026-01-02T03:04:05Z\"}\n\n")
+		fmt.Fprint(w, "event: gauge\ndata: not json\n\n")
+		fmt.Fprint(w, ": ping 2026-01-02T03:04:20Z\n\n")
+	}))
+	defer server.Close()
+
+	facade := NewMetricReplicationFacade(resty.New(), server.URL)
+
+	messages, err := facade.Subscribe(context.Background())
+	require.NoError(t, err)
+
+	var got []types.MetricsReplicationMessage
+	for msg := range messages {
+		got = append(got, msg)
+	}
+
+	require.Len(t, got, 2)
+	require.NotNil(t, got[0].Event)
+	assert.Equal(t, "temp", got[0].Event.Saved.ID)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), got[0].Timestamp)
+	assert.Nil(t, got[1].Event)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 20, 0, time.UTC), got[1].Timestamp)
+}
+
+func TestMetricReplicationFacade_Subscribe_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "stream closed", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	facade := NewMetricReplicationFacade(resty.New(), server.URL)
+
+	_, err := facade.Subscribe(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server returned status 503")
+}
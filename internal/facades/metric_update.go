@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
@@ -60,7 +61,11 @@ func (f *MetricUpdateFacade) Update(ctx context.Context, req types.MetricsUpdate
 		Post(url)
 
 	if err != nil {
-		return fmt.Errorf("request error: %w", err)
+		return fmt.Errorf("%w: request error: %w", errors.ErrServerUnavailable, err)
+	}
+
+	if resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: server returned status %d: %s", errors.ErrServerUnavailable, resp.StatusCode(), resp.String())
 	}
 
 	if resp.StatusCode() >= http.StatusBadRequest {
@@ -0,0 +1,63 @@
+package facades
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MetricUpdateBatchFacade sends metric batches to the /updates/ endpoint of
+// a metrics server as gzip-encoded JSON.
+type MetricUpdateBatchFacade struct {
+	client     *resty.Client
+	serverAddr string
+}
+
+func NewMetricUpdateBatchFacade(client *resty.Client, serverAddr string) *MetricUpdateBatchFacade {
+	addr := strings.TrimRight(serverAddr, "/")
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return &MetricUpdateBatchFacade{client: client, serverAddr: addr}
+}
+
+// Update posts the batch in a single request.
+func (f *MetricUpdateBatchFacade) Update(ctx context.Context, metrics []types.Metrics) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(metrics); err != nil {
+		return fmt.Errorf("encode batch: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("encode batch: %w", err)
+	}
+
+	resp, err := f.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Content-Encoding", "gzip").
+		SetBody(buf.Bytes()).
+		Post(f.serverAddr + "/updates/")
+
+	if err != nil {
+		return fmt.Errorf("%w: request error: %w", errors.ErrServerUnavailable, err)
+	}
+
+	if resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: server returned status %d: %s", errors.ErrServerUnavailable, resp.StatusCode(), resp.String())
+	}
+
+	if resp.StatusCode() >= http.StatusBadRequest {
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	return nil
+}
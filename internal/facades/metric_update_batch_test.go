@@ -0,0 +1,59 @@
+package facades
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricUpdateBatchFacade_Update_Success(t *testing.T) {
+	var (
+		receivedPath string
+		received     []types.Metrics
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(gz).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	facade := NewMetricUpdateBatchFacade(resty.New(), server.URL+"/")
+
+	delta := int64(3)
+	batch := []types.Metrics{{ID: "hits", MType: types.Counter, Delta: &delta}}
+
+	err := facade.Update(context.Background(), batch)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/updates/", receivedPath)
+	assert.Equal(t, batch, received)
+}
+
+func TestMetricUpdateBatchFacade_Update_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	facade := NewMetricUpdateBatchFacade(resty.New(), strings.TrimPrefix(server.URL, "http://"))
+
+	err := facade.Update(context.Background(), nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server returned status 500")
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +63,37 @@ func TestMetricUpdateFacade_Update_ServerError(t *testing.T) {
 	// Assert
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "server returned status 500")
+	assert.ErrorIs(t, err, errors.ErrServerUnavailable)
+}
+
+func TestMetricUpdateFacade_Update_ClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	facade := NewMetricUpdateFacade(resty.New(), server.URL, "update")
+
+	err := facade.Update(context.Background(), types.MetricsUpdatePathRequest{Name: "Heap", MType: "gauge", Value: "x"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server returned status 400")
+	assert.NotErrorIs(t, err, errors.ErrServerUnavailable)
+}
+
+func TestMetricUpdateFacade_Update_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	facade := NewMetricUpdateFacade(resty.New(), server.URL, "update")
+
+	err := facade.Update(context.Background(), types.MetricsUpdatePathRequest{Name: "Heap", MType: "gauge", Value: "1"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errors.ErrServerUnavailable, "rate limited reports are retried")
 }
 
 func TestMetricUpdateFacade_Update_InvalidScheme(t *testing.T) {
@@ -102,4 +134,5 @@ func TestMetricUpdateFacade_Update_RequestError_NoMock(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "request error")
+	assert.ErrorIs(t, err, errors.ErrServerUnavailable)
 }
@@ -2,13 +2,26 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 )
 
+// getURLParam returns the decoded path parameter. chi matches against the raw
+// path when the request contains escaped characters that the decoded path
+// cannot represent, so names such as "cpu{host=a,core=0}" may arrive
+// percent-encoded and are unescaped here. Otherwise chi already routed on
+// the decoded path and the parameter is returned as is.
 func getURLParam(r *http.Request, key string) string {
-	return chi.URLParam(r, key)
+	param := chi.URLParam(r, key)
+	if r.URL.RawPath == "" {
+		return param
+	}
+	if unescaped, err := url.PathUnescape(param); err == nil {
+		return unescaped
+	}
+	return param
 }
 
 func handleError(w http.ResponseWriter, message string, code int) {
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetURLParam(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{"plain name", "/value/gauge/Alloc", "Alloc"},
+		{"labels", "/value/gauge/cpu%7Bhost=a,core=0%7D", "cpu{host=a,core=0}"},
+		{"escaped slash routed on the raw path", "/value/gauge/a%2Fb", "a/b"},
+		{"escaped percent is decoded once", "/value/gauge/a%2541", "a%41"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := chi.NewRouter()
+			r.Get("/value/{type}/{name}", func(w http.ResponseWriter, r *http.Request) {
+				got = getURLParam(r, "name")
+			})
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricRestorer interface {
+	Restore(ctx context.Context, metrics []types.Metrics, mode string) error
+}
+
+func NewMetricRestoreHandler(
+	valModeFunc func(mode string) error,
+	valMetricFunc func(metric types.Metrics) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricRestorer,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mode := r.URL.Query().Get("mode")
+		if mode == "" {
+			mode = types.RestoreModeReplace
+		}
+
+		err := valModeFunc(mode)
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		var metrics []types.Metrics
+		if err := decodeDump(r.Body, &metrics); err != nil {
+			apiErr = errHandlerFunc(errors.ErrInvalidDump)
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		for _, metric := range metrics {
+			if err := valMetricFunc(metric); err != nil {
+				apiErr = errHandlerFunc(errors.ErrInvalidDump)
+				handleError(w, fmt.Sprintf("%s: metric %q: %s", apiErr.Message, metric.ID, err), apiErr.Code)
+				return
+			}
+		}
+
+		err = svc.Restore(r.Context(), metrics, mode)
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// decodeDump reads a JSON dump, transparently unpacking it when it is gzipped.
+func decodeDump(body io.Reader, v any) error {
+	reader := bufio.NewReader(body)
+
+	var src io.Reader = reader
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	return json.NewDecoder(src).Decode(v)
+}
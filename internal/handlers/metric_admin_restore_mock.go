@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_admin_restore.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricRestorer is a mock of MetricRestorer interface.
+type MockMetricRestorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricRestorerMockRecorder
+}
+
+// MockMetricRestorerMockRecorder is the mock recorder for MockMetricRestorer.
+type MockMetricRestorerMockRecorder struct {
+	mock *MockMetricRestorer
+}
+
+// NewMockMetricRestorer creates a new mock instance.
+func NewMockMetricRestorer(ctrl *gomock.Controller) *MockMetricRestorer {
+	mock := &MockMetricRestorer{ctrl: ctrl}
+	mock.recorder = &MockMetricRestorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricRestorer) EXPECT() *MockMetricRestorerMockRecorder {
+	return m.recorder
+}
+
+// Restore mocks base method.
+func (m *MockMetricRestorer) Restore(ctx context.Context, metrics []types.Metrics, mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, metrics, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockMetricRestorerMockRecorder) Restore(ctx, metrics, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockMetricRestorer)(nil).Restore), ctx, metrics, mode)
+}
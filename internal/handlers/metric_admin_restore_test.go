@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricRestoreHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricRestorer(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+	valModeFunc := func(mode string) error {
+		if mode != types.RestoreModeReplace && mode != types.RestoreModeMerge {
+			return errors.New("invalid restore mode")
+		}
+		return nil
+	}
+	valMetricFunc := func(metric types.Metrics) error {
+		if metric.ID == "" {
+			return errors.New("invalid metric id")
+		}
+		return nil
+	}
+
+	value := 1.5
+	body := `[{"id":"g","type":"gauge","value":1.5}]`
+	metrics := []types.Metrics{{ID: "g", MType: types.Gauge, Value: &value}}
+
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(s))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		body           []byte
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "replace from json",
+			url:  "/admin/restore",
+			body: []byte(body),
+			mockSetup: func() {
+				mockSvc.EXPECT().Restore(gomock.Any(), metrics, types.RestoreModeReplace).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "merge from gzip",
+			url:  "/admin/restore?mode=merge",
+			body: gzipped(body),
+			mockSetup: func() {
+				mockSvc.EXPECT().Restore(gomock.Any(), metrics, types.RestoreModeMerge).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid mode",
+			url:            "/admin/restore?mode=append",
+			body:           []byte(body),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid restore mode",
+		},
+		{
+			name:           "malformed dump",
+			url:            "/admin/restore",
+			body:           []byte("{not json"),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid dump",
+		},
+		{
+			name:           "invalid metric in dump",
+			url:            "/admin/restore",
+			body:           []byte(`[{"type":"gauge","value":1}]`),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid metric id",
+		},
+		{
+			name: "service error",
+			url:  "/admin/restore",
+			body: []byte(body),
+			mockSetup: func() {
+				mockSvc.EXPECT().Restore(gomock.Any(), metrics, types.RestoreModeReplace).Return(errors.New("restore failure"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "restore failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewReader(tt.body))
+
+			NewMetricRestoreHandler(valModeFunc, valMetricFunc, errHandlerFunc, mockSvc).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/codecs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricDumper interface {
+	Dump(ctx context.Context) ([]types.Metrics, error)
+}
+
+func NewMetricSnapshotHandler(
+	valFunc func(format string) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricDumper,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = types.DumpFormatJSON
+		}
+
+		err := valFunc(format)
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		metrics, err := svc.Dump(r.Context())
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		var out io.Writer = w
+		switch format {
+		case types.DumpFormatGzip:
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Header().Set("Content-Disposition", `attachment; filename="metrics-snapshot.json.gz"`)
+
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", `attachment; filename="metrics-snapshot.json"`)
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		if err := writeMetrics(out, types.ExportFormatJSON, metrics); err != nil {
+			logger.Log.Errorw("Failed to stream metrics snapshot", "error", err)
+		}
+	}
+}
+
+// writeMetrics streams metrics in the given export format one row at a time.
+func writeMetrics(w io.Writer, format string, metrics []types.Metrics) error {
+	enc, err := codecs.NewMetricsEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	for _, metric := range metrics {
+		if err := enc.Encode(metric); err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
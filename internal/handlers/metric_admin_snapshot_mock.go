@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_admin_snapshot.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricDumper is a mock of MetricDumper interface.
+type MockMetricDumper struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricDumperMockRecorder
+}
+
+// MockMetricDumperMockRecorder is the mock recorder for MockMetricDumper.
+type MockMetricDumperMockRecorder struct {
+	mock *MockMetricDumper
+}
+
+// NewMockMetricDumper creates a new mock instance.
+func NewMockMetricDumper(ctrl *gomock.Controller) *MockMetricDumper {
+	mock := &MockMetricDumper{ctrl: ctrl}
+	mock.recorder = &MockMetricDumperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricDumper) EXPECT() *MockMetricDumperMockRecorder {
+	return m.recorder
+}
+
+// Dump mocks base method.
+func (m *MockMetricDumper) Dump(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dump", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dump indicates an expected call of Dump.
+func (mr *MockMetricDumperMockRecorder) Dump(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dump", reflect.TypeOf((*MockMetricDumper)(nil).Dump), ctx)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricSnapshotHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricDumper(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+	valFunc := func(format string) error {
+		if format != types.DumpFormatJSON && format != types.DumpFormatGzip {
+			return errors.New("invalid dump format")
+		}
+		return nil
+	}
+
+	value := 1.5
+	delta := int64(3)
+	metrics := []types.Metrics{
+		{ID: "c", MType: types.Counter, Delta: &delta},
+		{ID: "g", MType: types.Gauge, Value: &value},
+	}
+
+	t.Run("json snapshot", func(t *testing.T) {
+		mockSvc.EXPECT().Dump(gomock.Any()).Return(metrics, nil)
+
+		rec := httptest.NewRecorder()
+		NewMetricSnapshotHandler(valFunc, errHandlerFunc, mockSvc).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var got []types.Metrics
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, metrics, got)
+	})
+
+	t.Run("gzip snapshot", func(t *testing.T) {
+		mockSvc.EXPECT().Dump(gomock.Any()).Return(metrics, nil)
+
+		rec := httptest.NewRecorder()
+		NewMetricSnapshotHandler(valFunc, errHandlerFunc, mockSvc).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot?format=gzip", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+
+		var got []types.Metrics
+		require.NoError(t, json.NewDecoder(gz).Decode(&got))
+		assert.Equal(t, metrics, got)
+	})
+
+	t.Run("empty store", func(t *testing.T) {
+		mockSvc.EXPECT().Dump(gomock.Any()).Return([]types.Metrics{}, nil)
+
+		rec := httptest.NewRecorder()
+		NewMetricSnapshotHandler(valFunc, errHandlerFunc, mockSvc).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, "[]", rec.Body.String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewMetricSnapshotHandler(valFunc, errHandlerFunc, mockSvc).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot?format=xml", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid dump format")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockSvc.EXPECT().Dump(gomock.Any()).Return(nil, errors.New("dump failure"))
+
+		rec := httptest.NewRecorder()
+		NewMetricSnapshotHandler(valFunc, errHandlerFunc, mockSvc).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "dump failure")
+	})
+}
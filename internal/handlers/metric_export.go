@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/codecs"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricExportLister interface {
+	List(ctx context.Context) ([]types.Metrics, error)
+}
+
+// NewMetricExportHandler streams every stored metric. The format query
+// parameter wins over the Accept header; JSON is the fallback.
+func NewMetricExportHandler(
+	valFunc func(format string) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricExportLister,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = negotiateMetricsFormat(r.Header.Get("Accept"))
+		}
+
+		err := valFunc(format)
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		metrics, err := svc.List(r.Context())
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		w.Header().Set("Content-Type", codecs.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="metrics.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		if err := writeMetrics(w, format, metrics); err != nil {
+			logger.Log.Errorw("Failed to stream metrics export", "format", format, "error", err)
+		}
+	}
+}
+
+// negotiateMetricsFormat maps an Accept or Content-Type header to an export
+// format, falling back to JSON.
+func negotiateMetricsFormat(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return types.ExportFormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			return types.ExportFormatNDJSON
+		case "application/json":
+			return types.ExportFormatJSON
+		}
+	}
+
+	return types.ExportFormatJSON
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_export.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricExportLister is a mock of MetricExportLister interface.
+type MockMetricExportLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricExportListerMockRecorder
+}
+
+// MockMetricExportListerMockRecorder is the mock recorder for MockMetricExportLister.
+type MockMetricExportListerMockRecorder struct {
+	mock *MockMetricExportLister
+}
+
+// NewMockMetricExportLister creates a new mock instance.
+func NewMockMetricExportLister(ctrl *gomock.Controller) *MockMetricExportLister {
+	mock := &MockMetricExportLister{ctrl: ctrl}
+	mock.recorder = &MockMetricExportListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricExportLister) EXPECT() *MockMetricExportListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricExportLister) List(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricExportListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricExportLister)(nil).List), ctx)
+}
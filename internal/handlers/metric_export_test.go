@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricExportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricExportLister(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	valFuncSuccess := func(format string) error { return nil }
+	valFuncFail := func(format string) error { return errors.New("invalid export format") }
+
+	delta := int64(3)
+	value := 0.5
+	metrics := []types.Metrics{
+		{ID: "hits", MType: types.Counter, Delta: &delta},
+		{ID: "load", MType: types.Gauge, Value: &value},
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		accept         string
+		valFunc        func(string) error
+		mockSetup      func()
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+		expectedAttach string
+	}{
+		{
+			name:           "csv via query parameter",
+			url:            "/export?format=csv",
+			valFunc:        valFuncSuccess,
+			mockSetup:      func() { mockSvc.EXPECT().List(gomock.Any()).Return(metrics, nil) },
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedBody:   "id,type,delta,value,updated_at\nhits,counter,3,,\nload,gauge,,0.5,\n",
+			expectedAttach: `attachment; filename="metrics.csv"`,
+		},
+		{
+			name:           "ndjson via Accept header",
+			url:            "/export",
+			accept:         "application/x-ndjson",
+			valFunc:        valFuncSuccess,
+			mockSetup:      func() { mockSvc.EXPECT().List(gomock.Any()).Return(metrics, nil) },
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/x-ndjson",
+			expectedBody:   `{"id":"hits","type":"counter","delta":3}` + "\n" + `{"id":"load","type":"gauge","value":0.5}` + "\n",
+			expectedAttach: `attachment; filename="metrics.ndjson"`,
+		},
+		{
+			name:           "query parameter wins over Accept",
+			url:            "/export?format=json",
+			accept:         "text/csv",
+			valFunc:        valFuncSuccess,
+			mockSetup:      func() { mockSvc.EXPECT().List(gomock.Any()).Return(metrics, nil) },
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json",
+			expectedBody:   `[{"id":"hits","type":"counter","delta":3},{"id":"load","type":"gauge","value":0.5}]` + "\n",
+			expectedAttach: `attachment; filename="metrics.json"`,
+		},
+		{
+			name:           "defaults to json",
+			url:            "/export",
+			accept:         "*/*",
+			valFunc:        valFuncSuccess,
+			mockSetup:      func() { mockSvc.EXPECT().List(gomock.Any()).Return(nil, nil) },
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json",
+			expectedBody:   "[]\n",
+			expectedAttach: `attachment; filename="metrics.json"`,
+		},
+		{
+			name:           "validation error",
+			url:            "/export?format=xml",
+			valFunc:        valFuncFail,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid export format\n",
+		},
+		{
+			name:           "service error",
+			url:            "/export?format=csv",
+			valFunc:        valFuncSuccess,
+			mockSetup:      func() { mockSvc.EXPECT().List(gomock.Any()).Return(nil, errors.New("list failure")) },
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "list failure\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricExportHandler(tt.valFunc, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, rec.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedAttach, rec.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
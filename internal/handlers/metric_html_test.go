@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -26,60 +27,117 @@ func TestNewMetricListHTMLHandler(t *testing.T) {
 		return nil
 	}
 
-	metricValue := 42.0
+	valFuncSuccess := func(mType, refresh string) error { return nil }
+
+	gaugeValue := 42.5
+	counterDelta := int64(7)
+	updatedAt := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
 	metrics := []types.Metrics{
-		{
-			ID:    "metric1",
-			MType: types.Gauge,
-			Value: &metricValue,
-		},
-		{
-			ID:    "metric2",
-			MType: types.Counter,
-			Value: &metricValue,
-		},
+		{ID: "Alloc", MType: types.Counter, Delta: &counterDelta},
+		{ID: "Alloc", MType: types.Gauge, Value: &gaugeValue, UpdatedAt: updatedAt},
+		{ID: "HeapInuse", MType: types.Gauge, Value: &gaugeValue},
+		{ID: "<script>", MType: types.Gauge, Value: &gaugeValue},
 	}
 
-	t.Run("success returns HTML with metrics", func(t *testing.T) {
-		mockSvc.EXPECT().
-			List(gomock.Any()).
-			Return(metrics, nil)
-
-		handler := NewMetricListHTMLHandler(errHandlerFunc, mockSvc)
-		req := httptest.NewRequest(http.MethodGet, "/", nil)
+	render := func(url string) *httptest.ResponseRecorder {
+		handler := NewMetricListHTMLHandler(valFuncSuccess, errHandlerFunc, mockSvc)
+		req := httptest.NewRequest(http.MethodGet, url, nil)
 		rec := httptest.NewRecorder()
-
 		handler.ServeHTTP(rec, req)
+		return rec
+	}
 
-		res := rec.Result()
-		defer res.Body.Close()
+	t.Run("renders type, value and last updated columns", func(t *testing.T) {
+		mockSvc.EXPECT().List(gomock.Any()).Return(metrics, nil)
 
-		assert.Equal(t, http.StatusOK, res.StatusCode)
-		assert.Equal(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
+		rec := render("/")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
 
 		body := rec.Body.String()
+		assert.Contains(t, body, "<html>")
 		assert.Contains(t, body, "<h1>Metrics</h1>")
-		// Проверяем динамически отформатированные значения
-		assert.Contains(t, body, "metric1: "+types.GetMetricStringValue(&metrics[0]))
-		assert.Contains(t, body, "metric2: "+types.GetMetricStringValue(&metrics[1]))
+		assert.Contains(t, body, `data-refresh="10"`)
+		assert.Contains(t, body, `<tr data-name="Alloc" data-type="counter" data-value="7" data-updated="0">`)
+		assert.Contains(t, body, `<tr data-name="Alloc" data-type="gauge" data-value="42.5" data-updated="1714979289">`)
+		assert.Contains(t, body, `<time datetime="2024-05-06T07:08:09Z">2024-05-06 07:08:09</time>`)
+		assert.Contains(t, body, "&lt;script&gt;")
+		assert.NotContains(t, body, "<td class=\"name\"><script>")
+		assert.Contains(t, body, `<span id="metrics-count">4</span>`)
 	})
 
-	t.Run("service error returns handled error", func(t *testing.T) {
-		mockSvc.EXPECT().
-			List(gomock.Any()).
-			Return(nil, errors.New("some error"))
+	t.Run("query string filters by type and name", func(t *testing.T) {
+		mockSvc.EXPECT().List(gomock.Any()).Return(metrics, nil)
+
+		rec := render("/?type=gauge&q=heap&refresh=0")
 
-		handler := NewMetricListHTMLHandler(errHandlerFunc, mockSvc)
-		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		body := rec.Body.String()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, body, `data-name="HeapInuse"`)
+		assert.NotContains(t, body, `data-name="Alloc"`)
+		assert.Contains(t, body, `<option value="gauge" selected>gauge</option>`)
+		assert.Contains(t, body, `value="heap"`)
+		assert.Contains(t, body, `data-refresh="0"`)
+		assert.Contains(t, body, `<span id="metrics-count">1</span>`)
+	})
+
+	t.Run("empty list renders placeholder row", func(t *testing.T) {
+		mockSvc.EXPECT().List(gomock.Any()).Return(nil, nil)
+
+		rec := render("/")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "No metrics")
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		handler := NewMetricListHTMLHandler(
+			func(mType, refresh string) error { return errors.New("invalid metric type") },
+			errHandlerFunc,
+			mockSvc,
+		)
+		req := httptest.NewRequest(http.MethodGet, "/?type=histogram", nil)
 		rec := httptest.NewRecorder()
 
 		handler.ServeHTTP(rec, req)
 
-		res := rec.Result()
-		defer res.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid metric type")
+	})
 
-		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
-		body := rec.Body.String()
-		assert.True(t, strings.Contains(body, "some error"))
+	t.Run("service error returns handled error", func(t *testing.T) {
+		mockSvc.EXPECT().List(gomock.Any()).Return(nil, errors.New("some error"))
+
+		rec := render("/")
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.True(t, strings.Contains(rec.Body.String(), "some error"))
 	})
 }
+
+func TestNewStaticHandler(t *testing.T) {
+	handler := NewStaticHandler()
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		contentType string
+	}{
+		{"/static/dashboard.js", http.StatusOK, "text/javascript; charset=utf-8"},
+		{"/static/dashboard.css", http.StatusOK, "text/css; charset=utf-8"},
+		{"/static/missing.js", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/codecs"
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricImporter interface {
+	Import(ctx context.Context, metrics []types.Metrics, mode string) error
+}
+
+// NewMetricImportHandler accepts the formats produced by /export. Malformed or
+// invalid rows are skipped and listed in the report; the remaining rows are
+// applied in a single call to the service.
+func NewMetricImportHandler(
+	valFormatFunc func(format string) error,
+	valModeFunc func(mode string) error,
+	valMetricFunc func(metric types.Metrics) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricImporter,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+
+		format := values.Get("format")
+		if format == "" {
+			format = negotiateMetricsFormat(r.Header.Get("Content-Type"))
+		}
+
+		mode := values.Get("mode")
+		if mode == "" {
+			mode = types.ImportModeMerge
+		}
+
+		err := valFormatFunc(format)
+		if err == nil {
+			err = valModeFunc(mode)
+		}
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		dec, err := codecs.NewMetricsDecoder(r.Body, format)
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		report := types.MetricsImportReport{
+			Mode:     mode,
+			Format:   format,
+			Rejected: []types.MetricsImportRejection{},
+		}
+
+		var metrics []types.Metrics
+		for row := 1; ; row++ {
+			metric, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+
+			var rowErr *codecs.RowError
+			if errors.As(err, &rowErr) {
+				report.Rejected = append(report.Rejected, types.MetricsImportRejection{
+					Row:    rowErr.Row,
+					ID:     metric.ID,
+					Reason: rowErr.Err.Error(),
+				})
+				continue
+			}
+			if err != nil {
+				apiErr = errHandlerFunc(internalErrors.ErrInvalidImport)
+				handleError(w, fmt.Sprintf("%s: %s", apiErr.Message, err), apiErr.Code)
+				return
+			}
+
+			if err := valMetricFunc(metric); err != nil {
+				report.Rejected = append(report.Rejected, types.MetricsImportRejection{
+					Row:    row,
+					ID:     metric.ID,
+					Reason: err.Error(),
+				})
+				continue
+			}
+
+			metrics = append(metrics, metric)
+		}
+
+		if len(metrics) > 0 {
+			err = svc.Import(r.Context(), metrics, mode)
+
+			apiErr = errHandlerFunc(err)
+			if apiErr != nil {
+				handleError(w, apiErr.Message, apiErr.Code)
+				return
+			}
+		}
+		report.Accepted = len(metrics)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_import.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricImporter is a mock of MetricImporter interface.
+type MockMetricImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricImporterMockRecorder
+}
+
+// MockMetricImporterMockRecorder is the mock recorder for MockMetricImporter.
+type MockMetricImporterMockRecorder struct {
+	mock *MockMetricImporter
+}
+
+// NewMockMetricImporter creates a new mock instance.
+func NewMockMetricImporter(ctrl *gomock.Controller) *MockMetricImporter {
+	mock := &MockMetricImporter{ctrl: ctrl}
+	mock.recorder = &MockMetricImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricImporter) EXPECT() *MockMetricImporterMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockMetricImporter) Import(ctx context.Context, metrics []types.Metrics, mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, metrics, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockMetricImporterMockRecorder) Import(ctx, metrics, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockMetricImporter)(nil).Import), ctx, metrics, mode)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricImportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricImporter(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	valOK := func(string) error { return nil }
+	valFail := func(string) error { return errors.New("invalid import mode") }
+	valMetric := func(m types.Metrics) error {
+		if m.ID == "" {
+			return errors.New("invalid metric id")
+		}
+		return nil
+	}
+
+	delta := int64(5)
+	value := 1.5
+
+	tests := []struct {
+		name           string
+		url            string
+		contentType    string
+		body           string
+		valModeFunc    func(string) error
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "csv via content type in replace mode",
+			url:         "/import?mode=replace",
+			contentType: "text/csv",
+			body:        "id,type,delta,value\nhits,counter,5,\nload,gauge,,1.5\n",
+			valModeFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Import(gomock.Any(), []types.Metrics{
+					{ID: "hits", MType: types.Counter, Delta: &delta},
+					{ID: "load", MType: types.Gauge, Value: &value},
+				}, types.ImportModeReplace).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"mode":"replace","format":"csv","accepted":2,"rejected":[]}` + "\n",
+		},
+		{
+			name:        "ndjson rejects malformed and invalid rows, defaults to merge",
+			url:         "/import?format=ndjson",
+			body:        `{"id":"hits","type":"counter","delta":5}` + "\n" + "{oops\n" + `{"type":"gauge","value":1}` + "\n",
+			valModeFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Import(gomock.Any(), []types.Metrics{
+					{ID: "hits", MType: types.Counter, Delta: &delta},
+				}, types.ImportModeMerge).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"mode":"merge","format":"ndjson","accepted":1,"rejected":[` +
+				`{"row":2,"reason":"invalid character 'o' looking for beginning of object key string"},` +
+				`{"row":3,"reason":"invalid metric id"}]}` + "\n",
+		},
+		{
+			name:           "nothing accepted skips service",
+			url:            "/import",
+			contentType:    "application/json",
+			body:           `[{"type":"gauge"}]`,
+			valModeFunc:    valOK,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"mode":"merge","format":"json","accepted":0,"rejected":[{"row":1,"reason":"invalid metric id"}]}` + "\n",
+		},
+		{
+			name:           "malformed document",
+			url:            "/import?format=json",
+			body:           `{"id":"x"}`,
+			valModeFunc:    valOK,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid import: json: expected array, got {\n",
+		},
+		{
+			name:           "invalid mode",
+			url:            "/import?mode=append",
+			body:           `[]`,
+			valModeFunc:    valFail,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid import mode\n",
+		},
+		{
+			name:        "service error",
+			url:         "/import?format=json",
+			body:        `[{"id":"hits","type":"counter","delta":5}]`,
+			valModeFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Import(gomock.Any(), gomock.Any(), types.ImportModeMerge).Return(errors.New("import failure"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "import failure\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricImportHandler(valOK, tt.valModeFunc, valMetric, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
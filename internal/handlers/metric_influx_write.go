@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const maxInfluxLineSize = 1 << 20
+
+type MetricInfluxUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// NewMetricInfluxWriteHandler accepts InfluxDB line protocol. As in InfluxDB,
+// lines that parse are written even when others fail, and the failures are
+// reported per line with a 400 response.
+func NewMetricInfluxWriteHandler(
+	valFunc func(precision string) error,
+	parseFunc func(line string) ([]types.Metrics, error),
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricInfluxUpdater,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := valFunc(r.URL.Query().Get("precision"))
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				handleError(w, "invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+
+		var (
+			metrics    []types.Metrics
+			lineErrors []types.InfluxWriteLineError
+			lines      int
+		)
+
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxInfluxLineSize)
+		for scanner.Scan() {
+			lines++
+
+			parsed, err := parseFunc(scanner.Text())
+			if err != nil {
+				lineErrors = append(lineErrors, types.InfluxWriteLineError{
+					Line:    lines,
+					Message: fmt.Sprintf("unable to parse '%s': %s", scanner.Text(), err),
+				})
+				continue
+			}
+			metrics = append(metrics, parsed...)
+		}
+		if err := scanner.Err(); err != nil {
+			handleError(w, fmt.Sprintf("unable to read body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		if len(metrics) > 0 {
+			err = svc.Update(r.Context(), metrics)
+
+			apiErr = errHandlerFunc(err)
+			if apiErr != nil {
+				handleError(w, apiErr.Message, apiErr.Code)
+				return
+			}
+		}
+
+		if len(lineErrors) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(types.InfluxWriteError{
+				Code:    "invalid",
+				Message: fmt.Sprintf("partial write: %d of %d lines rejected", len(lineErrors), lines),
+				Errors:  lineErrors,
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
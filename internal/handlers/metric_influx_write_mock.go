@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_influx_write.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricInfluxUpdater is a mock of MetricInfluxUpdater interface.
+type MockMetricInfluxUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricInfluxUpdaterMockRecorder
+}
+
+// MockMetricInfluxUpdaterMockRecorder is the mock recorder for MockMetricInfluxUpdater.
+type MockMetricInfluxUpdaterMockRecorder struct {
+	mock *MockMetricInfluxUpdater
+}
+
+// NewMockMetricInfluxUpdater creates a new mock instance.
+func NewMockMetricInfluxUpdater(ctrl *gomock.Controller) *MockMetricInfluxUpdater {
+	mock := &MockMetricInfluxUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricInfluxUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricInfluxUpdater) EXPECT() *MockMetricInfluxUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricInfluxUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricInfluxUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricInfluxUpdater)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricInfluxWriteHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricInfluxUpdater(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	valOK := func(string) error { return nil }
+	valFail := func(string) error { return errors.New("invalid precision") }
+
+	value := 1.0
+	parseFunc := func(line string) ([]types.Metrics, error) {
+		switch {
+		case line == "":
+			return nil, nil
+		case strings.HasPrefix(line, "bad"):
+			return nil, errors.New("malformed line")
+		default:
+			id, _, _ := strings.Cut(line, " ")
+			return []types.Metrics{{ID: id, MType: types.Gauge, Value: &value}}, nil
+		}
+	}
+
+	gzipped := func(s string) string {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(s))
+		gz.Close()
+		return buf.String()
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		gzip           bool
+		valFunc        func(string) error
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "all lines written",
+			url:     "/write?precision=s",
+			body:    "cpu value=1\n\nmem value=1\n",
+			valFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), []types.Metrics{
+					{ID: "cpu", MType: types.Gauge, Value: &value},
+					{ID: "mem", MType: types.Gauge, Value: &value},
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "gzip body",
+			url:     "/write",
+			body:    gzipped("cpu value=1"),
+			gzip:    true,
+			valFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), []types.Metrics{
+					{ID: "cpu", MType: types.Gauge, Value: &value},
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "partial write reports failing lines",
+			url:     "/write",
+			body:    "cpu value=1\nbad line\nmem value=1",
+			valFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Len(2)).Return(nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"code":"invalid","message":"partial write: 1 of 3 lines rejected",` +
+				`"errors":[{"line":2,"message":"unable to parse 'bad line': malformed line"}]}` + "\n",
+		},
+		{
+			name:           "nothing valid skips service",
+			url:            "/write",
+			body:           "bad",
+			valFunc:        valOK,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"code":"invalid","message":"partial write: 1 of 1 lines rejected",` +
+				`"errors":[{"line":1,"message":"unable to parse 'bad': malformed line"}]}` + "\n",
+		},
+		{
+			name:           "invalid precision",
+			url:            "/write?precision=h",
+			body:           "cpu value=1",
+			valFunc:        valFail,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid precision\n",
+		},
+		{
+			name:    "service error",
+			url:     "/write",
+			body:    "cpu value=1",
+			valFunc: valOK,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("update failure"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "update failure\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricInfluxWriteHandler(tt.valFunc, parseFunc, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -2,21 +2,61 @@ package handlers
 
 import (
 	"context"
+	"embed"
+	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
+const defaultMetricsRefreshInterval = 10
+
+//go:embed templates/metrics.html
+var metricsTemplateFS embed.FS
+
+var metricsTemplate = template.Must(template.ParseFS(metricsTemplateFS, "templates/metrics.html"))
+
 type MetricHTMLLister interface {
 	List(ctx context.Context) ([]types.Metrics, error)
 }
 
+type metricsPage struct {
+	Type       string
+	Query      string
+	Refresh    int
+	Types      []string
+	Rows       []metricsPageRow
+	RenderedAt time.Time
+}
+
+type metricsPageRow struct {
+	ID            string
+	MType         string
+	Value         string
+	UpdatedAt     time.Time
+	UpdatedAtUnix int64
+}
+
+// NewMetricListHTMLHandler renders the metrics dashboard. The type and q query
+// parameters narrow the list on the server, refresh sets the auto-refresh
+// period in seconds (0 disables it).
 func NewMetricListHTMLHandler(
+	valFunc func(mType string, refresh string) error,
 	errHandlerFunc func(err error) *types.APIError,
 	svc MetricHTMLLister,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		metrics, err := svc.List(r.Context())
+		values := r.URL.Query()
+
+		mType := values.Get("type")
+		query := values.Get("q")
+		refresh := values.Get("refresh")
+
+		err := valFunc(mType, refresh)
 
 		apiErr := errHandlerFunc(err)
 		if apiErr != nil {
@@ -24,8 +64,51 @@ func NewMetricListHTMLHandler(
 			return
 		}
 
+		metrics, err := svc.List(r.Context())
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		page := metricsPage{
+			Type:       mType,
+			Query:      query,
+			Refresh:    defaultMetricsRefreshInterval,
+			Types:      []string{types.Counter, types.Gauge},
+			Rows:       make([]metricsPageRow, 0, len(metrics)),
+			RenderedAt: time.Now(),
+		}
+		if refresh != "" {
+			page.Refresh, _ = strconv.Atoi(refresh)
+		}
+
+		needle := strings.ToLower(query)
+		for _, m := range metrics {
+			if mType != "" && m.MType != mType {
+				continue
+			}
+			if needle != "" && !strings.Contains(strings.ToLower(m.ID), needle) {
+				continue
+			}
+
+			row := metricsPageRow{
+				ID:        m.ID,
+				MType:     m.MType,
+				Value:     types.GetMetricStringValue(&m),
+				UpdatedAt: m.UpdatedAt,
+			}
+			if !m.UpdatedAt.IsZero() {
+				row.UpdatedAtUnix = m.UpdatedAt.Unix()
+			}
+			page.Rows = append(page.Rows, row)
+		}
+
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(types.GetMetricsHTML(metrics)))
+		if err := metricsTemplate.Execute(w, page); err != nil {
+			logger.Log.Errorw("Failed to render metrics page", "error", err)
+		}
 	}
 }
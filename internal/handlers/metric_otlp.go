@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricOTLPUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// NewMetricOTLPHandler is an OTLP/HTTP receiver for the JSON encoding.
+// Protobuf payloads are refused with 415. Data points the converter rejects
+// are reported through partialSuccess while the rest are still stored, as
+// the OTLP specification requires.
+func NewMetricOTLPHandler(
+	convertFunc func(req types.OTLPExportMetricsRequest) ([]types.Metrics, int, []error),
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricOTLPUpdater,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			mediaType, _, _ := mime.ParseMediaType(ct)
+			if mediaType != "application/json" {
+				handleError(w, "unsupported content type, only application/json is accepted", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+
+		var body io.Reader = r.Body
+		if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				handleError(w, "invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+
+		var req types.OTLPExportMetricsRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			handleError(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		metrics, rejected, errs := convertFunc(req)
+
+		if len(metrics) > 0 {
+			err := svc.Update(r.Context(), metrics)
+
+			apiErr := errHandlerFunc(err)
+			if apiErr != nil {
+				handleError(w, apiErr.Message, apiErr.Code)
+				return
+			}
+		}
+
+		var resp types.OTLPExportMetricsResponse
+		if rejected > 0 {
+			resp.PartialSuccess = &types.OTLPPartialSuccess{
+				RejectedDataPoints: types.OTLPInt64(rejected),
+				ErrorMessage:       errors.Join(errs...).Error(),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_otlp.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricOTLPUpdater is a mock of MetricOTLPUpdater interface.
+type MockMetricOTLPUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricOTLPUpdaterMockRecorder
+}
+
+// MockMetricOTLPUpdaterMockRecorder is the mock recorder for MockMetricOTLPUpdater.
+type MockMetricOTLPUpdaterMockRecorder struct {
+	mock *MockMetricOTLPUpdater
+}
+
+// NewMockMetricOTLPUpdater creates a new mock instance.
+func NewMockMetricOTLPUpdater(ctrl *gomock.Controller) *MockMetricOTLPUpdater {
+	mock := &MockMetricOTLPUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricOTLPUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricOTLPUpdater) EXPECT() *MockMetricOTLPUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricOTLPUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricOTLPUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricOTLPUpdater)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricOTLPHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricOTLPUpdater(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	value := 1.0
+	convertFunc := func(req types.OTLPExportMetricsRequest) ([]types.Metrics, int, []error) {
+		var (
+			metrics  []types.Metrics
+			rejected int
+			errs     []error
+		)
+		for _, rm := range req.ResourceMetrics {
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					if m.Gauge == nil {
+						rejected++
+						errs = append(errs, errors.New(m.Name+": unsupported"))
+						continue
+					}
+					metrics = append(metrics, types.Metrics{ID: m.Name, MType: types.Gauge, Value: &value})
+				}
+			}
+		}
+		return metrics, rejected, errs
+	}
+
+	body := func(names ...string) string {
+		var metrics []string
+		for _, name := range names {
+			if strings.HasPrefix(name, "bad") {
+				metrics = append(metrics, `{"name":"`+name+`"}`)
+				continue
+			}
+			metrics = append(metrics, `{"name":"`+name+`","gauge":{"dataPoints":[{"asDouble":1}]}}`)
+		}
+		return `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[` + strings.Join(metrics, ",") + `]}]}]}`
+	}
+
+	gzipped := func(s string) string {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(s))
+		gz.Close()
+		return buf.String()
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		contentType    string
+		gzip           bool
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "all data points accepted",
+			body:        body("cpu", "mem"),
+			contentType: "application/json",
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), []types.Metrics{
+					{ID: "cpu", MType: types.Gauge, Value: &value},
+					{ID: "mem", MType: types.Gauge, Value: &value},
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+		},
+		{
+			name:        "gzip body",
+			body:        gzipped(body("cpu")),
+			contentType: "application/json; charset=utf-8",
+			gzip:        true,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Len(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+		},
+		{
+			name: "partial success",
+			body: body("cpu", "bad1", "bad2"),
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Len(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"partialSuccess":{"rejectedDataPoints":"2","errorMessage":"bad1: unsupported\nbad2: unsupported"}}` + "\n",
+		},
+		{
+			name:           "nothing accepted skips service",
+			body:           body("bad"),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"partialSuccess":{"rejectedDataPoints":"1","errorMessage":"bad: unsupported"}}` + "\n",
+		},
+		{
+			name:           "protobuf refused",
+			body:           "\x0a\x00",
+			contentType:    "application/x-protobuf",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "unsupported content type, only application/json is accepted\n",
+		},
+		{
+			name:           "invalid json",
+			body:           "{",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body: unexpected EOF\n",
+		},
+		{
+			name: "service error",
+			body: body("cpu"),
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("update failure"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "update failure\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricOTLPHandler(convertFunc, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodPost, "/v1/metrics", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricQuerier interface {
+	Query(ctx context.Context, query types.MetricsQuery) ([]types.MetricsAggregate, error)
+}
+
+func NewMetricQueryHandler(
+	valFunc func(match string, mType string, agg string, by string) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricQuerier,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+
+		query := types.MetricsQuery{
+			Match: values.Get("match"),
+			MType: values.Get("type"),
+			Agg:   values.Get("agg"),
+			By:    values.Get("by"),
+		}
+
+		err := valFunc(query.Match, query.MType, query.Agg, query.By)
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		result, err := svc.Query(r.Context(), query)
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(result)
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_query.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricQuerier is a mock of MetricQuerier interface.
+type MockMetricQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricQuerierMockRecorder
+}
+
+// MockMetricQuerierMockRecorder is the mock recorder for MockMetricQuerier.
+type MockMetricQuerierMockRecorder struct {
+	mock *MockMetricQuerier
+}
+
+// NewMockMetricQuerier creates a new mock instance.
+func NewMockMetricQuerier(ctrl *gomock.Controller) *MockMetricQuerier {
+	mock := &MockMetricQuerier{ctrl: ctrl}
+	mock.recorder = &MockMetricQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricQuerier) EXPECT() *MockMetricQuerierMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *MockMetricQuerier) Query(ctx context.Context, query types.MetricsQuery) ([]types.MetricsAggregate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, query)
+	ret0, _ := ret[0].([]types.MetricsAggregate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockMetricQuerierMockRecorder) Query(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMetricQuerier)(nil).Query), ctx, query)
+}
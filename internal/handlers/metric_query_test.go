@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricQueryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricQuerier(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	valFuncSuccess := func(match, mType, agg, by string) error { return nil }
+	valFuncFail := func(match, mType, agg, by string) error { return errors.New("validation error") }
+
+	tests := []struct {
+		name           string
+		url            string
+		valFunc        func(string, string, string, string) error
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "success returns JSON aggregates",
+			url:     "/query?match=Heap*&agg=sum&by=type",
+			valFunc: valFuncSuccess,
+			mockSetup: func() {
+				mockSvc.EXPECT().
+					Query(gomock.Any(), types.MetricsQuery{Match: "Heap*", Agg: "sum", By: "type"}).
+					Return([]types.MetricsAggregate{{Group: "gauge", Agg: "sum", Value: 42, Count: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"group":"gauge","agg":"sum","value":42,"count":2}]`,
+		},
+		{
+			name:           "validation error",
+			url:            "/query?agg=median",
+			valFunc:        valFuncFail,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "validation error",
+		},
+		{
+			name:    "service error",
+			url:     "/query?agg=sum",
+			valFunc: valFuncSuccess,
+			mockSetup: func() {
+				mockSvc.EXPECT().
+					Query(gomock.Any(), types.MetricsQuery{Agg: "sum"}).
+					Return(nil, errors.New("list failure"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "list failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricQueryHandler(tt.valFunc, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
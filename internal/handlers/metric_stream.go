@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const metricStreamHeartbeatInterval = 15 * time.Second
+
+type MetricStreamSubscriber interface {
+	Subscribe(ctx context.Context, filter types.MetricsStreamFilter) (<-chan types.MetricsUpdateEvent, func(), error)
+}
+
+// NewMetricStreamHandler streams accepted updates as Server-Sent Events until
+// the client goes away or the subscription is closed by the server. Heartbeat
+// comments carry the server time so replicas can measure their lag while the
+// stream is idle.
+func NewMetricStreamHandler(
+	valFunc func(match string, mType string) error,
+	errHandlerFunc func(err error) *types.APIError,
+	svc MetricStreamSubscriber,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+
+		filter := types.MetricsStreamFilter{
+			Match: values.Get("match"),
+			MType: values.Get("type"),
+		}
+
+		err := valFunc(filter.Match, filter.MType)
+
+		apiErr := errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+
+		events, cancel, err := svc.Subscribe(r.Context(), filter)
+
+		apiErr = errHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
+			return
+		}
+		defer cancel()
+
+		rc := http.NewResponseController(w)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprint(w, ": connected\n\n")
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(metricStreamHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case now := <-heartbeat.C:
+				fmt.Fprintf(w, ": ping %s\n\n", now.UTC().Format(time.RFC3339Nano))
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Saved.MType, data)
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_stream.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricStreamSubscriber is a mock of MetricStreamSubscriber interface.
+type MockMetricStreamSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricStreamSubscriberMockRecorder
+}
+
+// MockMetricStreamSubscriberMockRecorder is the mock recorder for MockMetricStreamSubscriber.
+type MockMetricStreamSubscriberMockRecorder struct {
+	mock *MockMetricStreamSubscriber
+}
+
+// NewMockMetricStreamSubscriber creates a new mock instance.
+func NewMockMetricStreamSubscriber(ctrl *gomock.Controller) *MockMetricStreamSubscriber {
+	mock := &MockMetricStreamSubscriber{ctrl: ctrl}
+	mock.recorder = &MockMetricStreamSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricStreamSubscriber) EXPECT() *MockMetricStreamSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockMetricStreamSubscriber) Subscribe(ctx context.Context, filter types.MetricsStreamFilter) (<-chan types.MetricsUpdateEvent, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, filter)
+	ret0, _ := ret[0].(<-chan types.MetricsUpdateEvent)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockMetricStreamSubscriberMockRecorder) Subscribe(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockMetricStreamSubscriber)(nil).Subscribe), ctx, filter)
+}
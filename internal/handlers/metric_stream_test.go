@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricStreamHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricStreamSubscriber(ctrl)
+
+	errHandlerFunc := func(err error) *types.APIError {
+		if err != nil {
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+		return nil
+	}
+
+	valFuncSuccess := func(match, mType string) error { return nil }
+	valFuncFail := func(match, mType string) error { return errors.New("validation error") }
+
+	value := 1.5
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	gauge := types.Metrics{ID: "cpu_user", MType: types.Gauge, Value: &value}
+
+	tests := []struct {
+		name           string
+		url            string
+		valFunc        func(string, string) error
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "streams events until subscription closes",
+			url:     "/stream?match=cpu_*&type=gauge",
+			valFunc: valFuncSuccess,
+			mockSetup: func() {
+				events := make(chan types.MetricsUpdateEvent, 1)
+				events <- types.MetricsUpdateEvent{Update: gauge, Saved: gauge, Timestamp: ts}
+				close(events)
+
+				mockSvc.EXPECT().
+					Subscribe(gomock.Any(), types.MetricsStreamFilter{Match: "cpu_*", MType: types.Gauge}).
+					Return((<-chan types.MetricsUpdateEvent)(events), func() {}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: ": connected\n\n" +
+				"event: gauge\n" +
+				`data: {"update":{"id":"cpu_user","type":"gauge","value":1.5},"saved":{"id":"cpu_user","type":"gauge","value":1.5},"timestamp":"2024-01-02T03:04:05Z"}` +
+				"\n\n",
+		},
+		{
+			name:           "validation error",
+			url:            "/stream?type=histogram",
+			valFunc:        valFuncFail,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "validation error\n",
+		},
+		{
+			name:    "subscribe error",
+			url:     "/stream",
+			valFunc: valFuncSuccess,
+			mockSetup: func() {
+				mockSvc.EXPECT().
+					Subscribe(gomock.Any(), types.MetricsStreamFilter{}).
+					Return(nil, nil, errors.New("stream closed"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "stream closed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricStreamHandler(tt.valFunc, errHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+				assert.True(t, rec.Flushed)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricBatchUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// NewMetricUpdateBatchHandler accepts a JSON array of metrics, optionally
+// gzip-encoded. The batch is applied only if every metric is valid.
+func NewMetricUpdateBatchHandler(
+	valFunc func(metric types.Metrics) error,
+	errValHandlerFunc func(err error) *types.APIError,
+	svc MetricBatchUpdater,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				handleError(w, "invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+
+		var metrics []types.Metrics
+		if err := json.NewDecoder(body).Decode(&metrics); err != nil {
+			handleError(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		for _, m := range metrics {
+			apiErr := errValHandlerFunc(valFunc(m))
+			if apiErr != nil {
+				handleError(w, apiErr.Message, apiErr.Code)
+				return
+			}
+		}
+
+		if len(metrics) > 0 {
+			apiErr := errValHandlerFunc(svc.Update(r.Context(), metrics))
+			if apiErr != nil {
+				handleError(w, apiErr.Message, apiErr.Code)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
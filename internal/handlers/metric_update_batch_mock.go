@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/handlers/metric_update_batch.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricBatchUpdater is a mock of MetricBatchUpdater interface.
+type MockMetricBatchUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricBatchUpdaterMockRecorder
+}
+
+// MockMetricBatchUpdaterMockRecorder is the mock recorder for MockMetricBatchUpdater.
+type MockMetricBatchUpdaterMockRecorder struct {
+	mock *MockMetricBatchUpdater
+}
+
+// NewMockMetricBatchUpdater creates a new mock instance.
+func NewMockMetricBatchUpdater(ctrl *gomock.Controller) *MockMetricBatchUpdater {
+	mock := &MockMetricBatchUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricBatchUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricBatchUpdater) EXPECT() *MockMetricBatchUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricBatchUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricBatchUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricBatchUpdater)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestNewMetricUpdateBatchHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := NewMockMetricBatchUpdater(ctrl)
+
+	updateFailure := errors.New("update failure")
+
+	errValHandlerFunc := func(err error) *types.APIError {
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, updateFailure):
+			return &types.APIError{Message: internalErrors.ErrInternalServerError.Error(), Code: http.StatusInternalServerError}
+		case errors.Is(err, internalErrors.ErrMetricForbidden):
+			return &types.APIError{Message: err.Error(), Code: http.StatusForbidden}
+		default:
+			return &types.APIError{Message: err.Error(), Code: http.StatusBadRequest}
+		}
+	}
+
+	valFunc := func(m types.Metrics) error {
+		if m.MType != types.Counter && m.MType != types.Gauge {
+			return errors.New("invalid metric type")
+		}
+		return nil
+	}
+
+	gzipped := func(s string) string {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(s))
+		gz.Close()
+		return buf.String()
+	}
+
+	delta := int64(5)
+	value := 1.5
+
+	tests := []struct {
+		name           string
+		body           string
+		gzip           bool
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "batch applied",
+			body: `[{"id":"hits","type":"counter","delta":5},{"id":"temp","type":"gauge","value":1.5}]`,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), []types.Metrics{
+					{ID: "hits", MType: types.Counter, Delta: &delta},
+					{ID: "temp", MType: types.Gauge, Value: &value},
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "gzip body",
+			body: gzipped(`[{"id":"hits","type":"counter","delta":5}]`),
+			gzip: true,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Len(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty batch",
+			body:           `[]`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid metric rejects whole batch",
+			body:           `[{"id":"hits","type":"counter","delta":5},{"id":"x","type":"histogram"}]`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid metric type\n",
+		},
+		{
+			name:           "invalid json",
+			body:           `{"id":"hits"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body: json: cannot unmarshal object into Go value of type []types.Metrics\n",
+		},
+		{
+			name: "service error",
+			body: `[{"id":"hits","type":"counter","delta":5}]`,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(updateFailure)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "internal server error\n",
+		},
+		{
+			name: "forbidden for client identity",
+			body: `[{"id":"hits","type":"counter","delta":5}]`,
+			mockSetup: func() {
+				mockSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(internalErrors.ErrMetricForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   internalErrors.ErrMetricForbidden.Error() + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			handler := NewMetricUpdateBatchHandler(valFunc, errValHandlerFunc, mockSvc)
+			req := httptest.NewRequest(http.MethodPost, "/updates/", strings.NewReader(tt.body))
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -32,8 +32,11 @@ func NewMetricUpdatePathHandler(
 
 		metric := newMetrics(metricType, metricName, metricValue)
 
-		if err := svc.Update(r.Context(), []types.Metrics{*metric}); err != nil {
-			handleInternalServerError(w)
+		err = svc.Update(r.Context(), []types.Metrics{*metric})
+
+		apiErr = errValHandlerFunc(err)
+		if apiErr != nil {
+			handleError(w, apiErr.Message, apiErr.Code)
 			return
 		}
 
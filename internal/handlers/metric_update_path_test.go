@@ -12,6 +12,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
@@ -74,6 +75,9 @@ func TestMetricUpdatePathHandler_TableDriven(t *testing.T) {
 				return nil
 			},
 			errValHandler: func(err error) *types.APIError {
+				if err != nil {
+					return &types.APIError{Code: http.StatusInternalServerError, Message: internalErrors.ErrInternalServerError.Error()}
+				}
 				return nil
 			},
 			mockSvcBehavior: func(m *MockMetricPathUpdater, metrics []types.Metrics) {
@@ -82,6 +86,24 @@ func TestMetricUpdatePathHandler_TableDriven(t *testing.T) {
 			wantStatusCode:  http.StatusInternalServerError,
 			wantBodyContain: "internal server error",
 		},
+		{
+			name: "Forbidden for client identity",
+			args: args{"counter", "hits", "42"},
+			valFunc: func(mt, mn, mv string) error {
+				return nil
+			},
+			errValHandler: func(err error) *types.APIError {
+				if err != nil {
+					return &types.APIError{Code: http.StatusForbidden, Message: err.Error()}
+				}
+				return nil
+			},
+			mockSvcBehavior: func(m *MockMetricPathUpdater, metrics []types.Metrics) {
+				m.EXPECT().Update(gomock.Any(), gomock.Len(1)).Return(internalErrors.ErrMetricForbidden)
+			},
+			wantStatusCode:  http.StatusForbidden,
+			wantBodyContain: internalErrors.ErrMetricForbidden.Error(),
+		},
 	}
 
 	for _, tt := range tests {
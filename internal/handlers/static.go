@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// NewStaticHandler serves the dashboard assets embedded into the binary. It is
+// expected to be mounted under /static/.
+func NewStaticHandler() http.Handler {
+	assets, _ := fs.Sub(staticFS, "static")
+	return http.StripPrefix("/static/", http.FileServerFS(assets))
+}
@@ -0,0 +1,97 @@
+// Client-side sorting, filtering and auto-refresh for the metrics dashboard.
+(function () {
+  "use strict";
+
+  var table = document.getElementById("metrics");
+  var filter = document.getElementById("metrics-filter");
+  var count = document.getElementById("metrics-count");
+  var rendered = document.getElementById("metrics-rendered");
+  var sortKey = null;
+  var sortDir = 1;
+
+  function rows() {
+    return Array.prototype.slice.call(table.tBodies[0].querySelectorAll("tr[data-name]"));
+  }
+
+  function applyFilter() {
+    var needle = filter.value.trim().toLowerCase();
+    var visible = 0;
+    rows().forEach(function (row) {
+      var match = row.dataset.name.toLowerCase().indexOf(needle) !== -1;
+      row.hidden = !match;
+      if (match) {
+        visible++;
+      }
+    });
+    count.textContent = visible;
+  }
+
+  function applySort() {
+    if (!sortKey) {
+      return;
+    }
+    var header = table.querySelector('th[data-sort="' + sortKey + '"]');
+    var numeric = header.hasAttribute("data-numeric");
+    var body = table.tBodies[0];
+
+    rows()
+      .sort(function (a, b) {
+        var x = a.dataset[sortKey];
+        var y = b.dataset[sortKey];
+        if (numeric) {
+          return (parseFloat(x || "0") - parseFloat(y || "0")) * sortDir;
+        }
+        return x.localeCompare(y) * sortDir;
+      })
+      .forEach(function (row) {
+        body.appendChild(row);
+      });
+
+    table.querySelectorAll("th[data-sort]").forEach(function (th) {
+      th.removeAttribute("aria-sort");
+    });
+    header.setAttribute("aria-sort", sortDir > 0 ? "ascending" : "descending");
+  }
+
+  table.querySelectorAll("th[data-sort]").forEach(function (th) {
+    th.addEventListener("click", function () {
+      sortDir = sortKey === th.dataset.sort ? -sortDir : 1;
+      sortKey = th.dataset.sort;
+      applySort();
+    });
+  });
+
+  filter.addEventListener("input", applyFilter);
+
+  function refresh() {
+    fetch(window.location.href, { headers: { Accept: "text/html" } })
+      .then(function (resp) {
+        if (!resp.ok) {
+          throw new Error(resp.statusText);
+        }
+        return resp.text();
+      })
+      .then(function (text) {
+        var doc = new DOMParser().parseFromString(text, "text/html");
+        var fresh = doc.querySelector("#metrics tbody");
+        var freshRendered = doc.getElementById("metrics-rendered");
+        if (fresh) {
+          table.replaceChild(document.importNode(fresh, true), table.tBodies[0]);
+        }
+        if (freshRendered) {
+          rendered.replaceWith(document.importNode(freshRendered, true));
+          rendered = document.getElementById("metrics-rendered");
+        }
+        applySort();
+        applyFilter();
+      })
+      .catch(function () {
+        // Keep showing the last good data; the next tick will retry.
+      });
+  }
+
+  var interval = parseInt(document.body.dataset.refresh, 10);
+  if (interval > 0) {
+    window.setInterval(refresh, interval * 1000);
+  }
+})();
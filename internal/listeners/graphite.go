@@ -0,0 +1,267 @@
+package listeners
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/parsers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const (
+	graphiteMaxLineSize  = 64 * 1024
+	graphiteDrainTimeout = time.Second
+)
+
+type GraphiteUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// GraphiteListener accepts plaintext protocol connections over TCP. Each
+// connection is served by its own goroutine and closed after readTimeout of
+// inactivity. Malformed lines are counted and skipped.
+type GraphiteListener struct {
+	addr        string
+	updater     GraphiteUpdater
+	rules       *parsers.GraphiteCounterRules
+	readTimeout time.Duration
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	handlers sync.WaitGroup
+
+	malformed atomic.Int64
+}
+
+func NewGraphiteListener(
+	addr string,
+	updater GraphiteUpdater,
+	rules *parsers.GraphiteCounterRules,
+	readTimeout int,
+) *GraphiteListener {
+	return &GraphiteListener{
+		addr:        addr,
+		updater:     updater,
+		rules:       rules,
+		readTimeout: time.Duration(max(readTimeout, 1)) * time.Second,
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+func (l *GraphiteListener) ListenAndServe() error {
+	if err := l.Listen(); err != nil {
+		return err
+	}
+	return l.Serve()
+}
+
+// Listen binds the TCP socket. It is split from Serve so callers can learn
+// the bound address before connections are accepted.
+func (l *GraphiteListener) Listen() error {
+	listener, err := net.Listen("tcp", l.addr)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		listener.Close()
+		return nil
+	}
+	l.listener = listener
+
+	logger.Log.Infow("Graphite listener started", "address", listener.Addr().String())
+	return nil
+}
+
+func (l *GraphiteListener) Serve() error {
+	l.mu.Lock()
+	listener := l.listener
+	l.mu.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if l.isClosed() {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		if !l.track(conn) {
+			conn.Close()
+			return nil
+		}
+
+		go l.handleConn(conn)
+	}
+}
+
+// Shutdown stops accepting connections and lets open ones drain: each
+// connection may keep reading for a short grace period, after which it is
+// closed once its already received lines have been applied.
+func (l *GraphiteListener) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	if l.listener != nil {
+		l.listener.Close()
+	}
+
+	drainDeadline := time.Now().Add(graphiteDrainTimeout)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(drainDeadline) {
+		drainDeadline = deadline
+	}
+	for conn := range l.conns {
+		conn.SetReadDeadline(drainDeadline)
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Log.Infow("Graphite listener stopped", "malformed", l.malformed.Load())
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for conn := range l.conns {
+			conn.Close()
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *GraphiteListener) Addr() net.Addr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listener == nil {
+		return nil
+	}
+	return l.listener.Addr()
+}
+
+// Malformed returns the number of lines rejected so far.
+func (l *GraphiteListener) Malformed() int64 {
+	return l.malformed.Load()
+}
+
+func (l *GraphiteListener) handleConn(conn net.Conn) {
+	defer l.untrack(conn)
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), graphiteMaxLineSize)
+
+	for {
+		l.extendDeadline(conn)
+
+		if !scanner.Scan() {
+			break
+		}
+
+		line := scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+
+		metric, err := parsers.ParseGraphiteLine(line, l.rules)
+		if err != nil {
+			total := l.malformed.Add(1)
+			logger.Log.Debugw("Malformed Graphite line",
+				"remote", conn.RemoteAddr().String(),
+				"total", total,
+				"error", err,
+			)
+			continue
+		}
+
+		if err := l.updater.Update(context.Background(), []types.Metrics{metric}); err != nil {
+			logger.Log.Errorw("Failed to apply Graphite metric",
+				"id", metric.ID,
+				"error", err,
+			)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, bufio.ErrTooLong):
+			l.malformed.Add(1)
+			logger.Log.Warnw("Graphite line too long, closing connection",
+				"remote", conn.RemoteAddr().String(),
+			)
+		case errors.As(err, &netErr) && netErr.Timeout():
+			logger.Log.Debugw("Graphite connection idle, closing",
+				"remote", conn.RemoteAddr().String(),
+			)
+		case !l.isClosed():
+			logger.Log.Warnw("Graphite connection read error",
+				"remote", conn.RemoteAddr().String(),
+				"error", err,
+			)
+		}
+	}
+}
+
+func (l *GraphiteListener) track(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return false
+	}
+	l.conns[conn] = struct{}{}
+	l.handlers.Add(1)
+	return true
+}
+
+func (l *GraphiteListener) untrack(conn net.Conn) {
+	conn.Close()
+
+	l.mu.Lock()
+	delete(l.conns, conn)
+	l.mu.Unlock()
+
+	l.handlers.Done()
+}
+
+// extendDeadline pushes the idle deadline forward unless a shutdown already
+// set the final drain deadline.
+func (l *GraphiteListener) extendDeadline(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		conn.SetReadDeadline(time.Now().Add(l.readTimeout))
+	}
+}
+
+func (l *GraphiteListener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
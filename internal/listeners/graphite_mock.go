@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/listeners/graphite.go
+
+// Package listeners is a generated GoMock package.
+package listeners
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockGraphiteUpdater is a mock of GraphiteUpdater interface.
+type MockGraphiteUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockGraphiteUpdaterMockRecorder
+}
+
+// MockGraphiteUpdaterMockRecorder is the mock recorder for MockGraphiteUpdater.
+type MockGraphiteUpdaterMockRecorder struct {
+	mock *MockGraphiteUpdater
+}
+
+// NewMockGraphiteUpdater creates a new mock instance.
+func NewMockGraphiteUpdater(ctrl *gomock.Controller) *MockGraphiteUpdater {
+	mock := &MockGraphiteUpdater{ctrl: ctrl}
+	mock.recorder = &MockGraphiteUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGraphiteUpdater) EXPECT() *MockGraphiteUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockGraphiteUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockGraphiteUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGraphiteUpdater)(nil).Update), ctx, metrics)
+}
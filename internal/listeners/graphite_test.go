@@ -0,0 +1,199 @@
+package listeners
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/parsers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type recordingUpdater struct {
+	mu      sync.Mutex
+	metrics []types.Metrics
+}
+
+func (u *recordingUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.metrics = append(u.metrics, metrics...)
+	return nil
+}
+
+func (u *recordingUpdater) count() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.metrics)
+}
+
+func startGraphiteListener(t *testing.T, updater GraphiteUpdater, opts ...func(*GraphiteListener)) (*GraphiteListener, chan error) {
+	t.Helper()
+
+	rules, err := parsers.NewGraphiteCounterRules([]string{"*.requests"})
+	require.NoError(t, err)
+
+	listener := NewGraphiteListener("127.0.0.1:0", updater, rules, 60)
+	for _, opt := range opts {
+		opt(listener)
+	}
+	require.NoError(t, listener.Listen())
+
+	served := make(chan error, 1)
+	go func() { served <- listener.Serve() }()
+
+	return listener, served
+}
+
+func TestGraphiteListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockGraphiteUpdater(ctrl)
+
+	received := make(chan types.Metrics, 2)
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, metrics []types.Metrics) error {
+			received <- metrics[0]
+			return nil
+		}).
+		Times(2)
+
+	listener, served := startGraphiteListener(t, mockUpdater)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprint(conn, "web.load 0.5 1700000000\nbroken line\nweb.requests 3 1700000000\n")
+	require.NoError(t, err)
+
+	for _, want := range []types.Metrics{
+		{ID: "web.load", MType: types.Gauge},
+		{ID: "web.requests", MType: types.Counter},
+	} {
+		select {
+		case got := <-received:
+			assert.Equal(t, want.ID, got.ID)
+			assert.Equal(t, want.MType, got.MType)
+		case <-time.After(time.Second):
+			t.Fatal("line was not delivered")
+		}
+	}
+
+	assert.Equal(t, int64(1), listener.Malformed())
+
+	conn.Close()
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.NoError(t, <-served)
+}
+
+func TestGraphiteListener_ConcurrentConnections(t *testing.T) {
+	updater := &recordingUpdater{}
+	listener, served := startGraphiteListener(t, updater)
+
+	const conns, lines = 8, 50
+
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			for j := range lines {
+				fmt.Fprintf(conn, "host%d.metric%d %d 1700000000\n", i, j, j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return updater.count() == conns*lines }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.NoError(t, <-served)
+}
+
+func TestGraphiteListener_ShutdownDrainsOpenConnections(t *testing.T) {
+	updater := &recordingUpdater{}
+	listener, served := startGraphiteListener(t, updater)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprint(conn, "a.b 1 1700000000\nc.d 2 1700000000\n")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return updater.count() == 2 }, time.Second, 10*time.Millisecond)
+
+	// The client keeps its connection open; shutdown must still complete
+	// once the drain period is over.
+	start := time.Now()
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.Less(t, time.Since(start), 2*graphiteDrainTimeout)
+	assert.NoError(t, <-served)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "server side should have closed the connection")
+
+	_, err = net.Dial("tcp", listener.Addr().String())
+	assert.Error(t, err, "listener should no longer accept connections")
+}
+
+func TestGraphiteListener_IdleConnectionTimesOut(t *testing.T) {
+	updater := &recordingUpdater{}
+	listener, served := startGraphiteListener(t, updater, func(l *GraphiteListener) {
+		l.readTimeout = 50 * time.Millisecond
+	})
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.NoError(t, <-served)
+}
+
+func TestGraphiteListener_ShutdownDeadlineBoundsDrain(t *testing.T) {
+	updater := &recordingUpdater{}
+	listener, served := startGraphiteListener(t, updater)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		listener.mu.Lock()
+		defer listener.mu.Unlock()
+		return len(listener.conns) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = listener.Shutdown(ctx)
+	if err != nil {
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	assert.NoError(t, <-served)
+}
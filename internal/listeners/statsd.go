@@ -0,0 +1,167 @@
+package listeners
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/parsers"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const statsDMaxPacketSize = 65535
+
+type StatsDUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// StatsDListener receives StatsD packets over UDP and feeds the parsed metrics
+// to the updater. Malformed lines are counted and skipped.
+type StatsDListener struct {
+	addr    string
+	updater StatsDUpdater
+
+	mu      sync.Mutex
+	conn    net.PacketConn
+	closed  bool
+	serving sync.WaitGroup
+
+	malformed atomic.Int64
+}
+
+func NewStatsDListener(
+	addr string,
+	updater StatsDUpdater,
+) *StatsDListener {
+	return &StatsDListener{
+		addr:    addr,
+		updater: updater,
+	}
+}
+
+func (l *StatsDListener) ListenAndServe() error {
+	if err := l.Listen(); err != nil {
+		return err
+	}
+	return l.Serve()
+}
+
+// Listen binds the UDP socket. It is split from Serve so callers can learn
+// the bound address before packets start flowing.
+func (l *StatsDListener) Listen() error {
+	conn, err := net.ListenPacket("udp", l.addr)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		conn.Close()
+		return nil
+	}
+	l.conn = conn
+
+	logger.Log.Infow("StatsD listener started", "address", conn.LocalAddr().String())
+	return nil
+}
+
+func (l *StatsDListener) Serve() error {
+	l.mu.Lock()
+	conn := l.conn
+	if l.closed || conn == nil {
+		l.mu.Unlock()
+		return nil
+	}
+	l.serving.Add(1)
+	l.mu.Unlock()
+
+	defer l.serving.Done()
+
+	buf := make([]byte, statsDMaxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if l.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		l.handlePacket(buf[:n])
+	}
+}
+
+// Shutdown closes the socket and waits for the packet being processed, if
+// any, to be handed to the updater.
+func (l *StatsDListener) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	conn := l.conn
+	l.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		l.serving.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Log.Infow("StatsD listener stopped", "malformed", l.malformed.Load())
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *StatsDListener) Addr() net.Addr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	return l.conn.LocalAddr()
+}
+
+// Malformed returns the number of lines rejected so far.
+func (l *StatsDListener) Malformed() int64 {
+	return l.malformed.Load()
+}
+
+func (l *StatsDListener) handlePacket(packet []byte) {
+	metrics, errs := parsers.ParseStatsDPacket(packet)
+	if len(errs) > 0 {
+		total := l.malformed.Add(int64(len(errs)))
+		logger.Log.Debugw("Malformed StatsD lines",
+			"count", len(errs),
+			"total", total,
+			"error", errs[0],
+		)
+	}
+
+	if len(metrics) == 0 {
+		return
+	}
+
+	if err := l.updater.Update(context.Background(), metrics); err != nil {
+		logger.Log.Errorw("Failed to apply StatsD metrics",
+			"count", len(metrics),
+			"error", err,
+		)
+	}
+}
+
+func (l *StatsDListener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
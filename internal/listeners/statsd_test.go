@@ -0,0 +1,66 @@
+package listeners
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestStatsDListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockStatsDUpdater(ctrl)
+
+	received := make(chan []types.Metrics, 1)
+	mockUpdater.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, metrics []types.Metrics) error {
+			received <- metrics
+			return nil
+		})
+
+	listener := NewStatsDListener("127.0.0.1:0", mockUpdater)
+	require.NoError(t, listener.Listen())
+
+	served := make(chan error, 1)
+	go func() { served <- listener.Serve() }()
+
+	conn, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hits:2|c|@0.5\nload:1.5|g\ngarbage\nusers:1|s"))
+	require.NoError(t, err)
+
+	select {
+	case metrics := <-received:
+		require.Len(t, metrics, 2)
+		assert.Equal(t, "hits", metrics[0].ID)
+		assert.Equal(t, int64(4), *metrics[0].Delta)
+		assert.Equal(t, "load", metrics[1].ID)
+		assert.Equal(t, 1.5, *metrics[1].Value)
+	case <-time.After(time.Second):
+		t.Fatal("packet was not delivered")
+	}
+
+	assert.Equal(t, int64(2), listener.Malformed())
+
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.NoError(t, <-served)
+}
+
+func TestStatsDListener_ShutdownBeforeListen(t *testing.T) {
+	listener := NewStatsDListener("127.0.0.1:0", nil)
+
+	require.NoError(t, listener.Shutdown(context.Background()))
+	assert.NoError(t, listener.ListenAndServe())
+	assert.Nil(t, listener.Addr())
+}
@@ -2,18 +2,34 @@ package logger
 
 import (
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var Log *zap.SugaredLogger = zap.NewNop().Sugar()
 
-func Initialize(level string) error {
-	lvl, err := zap.ParseAtomicLevel(level)
+var level = zap.NewAtomicLevel()
+
+func Initialize(lvl string) error {
+	parsed, err := zapcore.ParseLevel(lvl)
 	if err != nil {
 		return err
 	}
+	level.SetLevel(parsed)
+
 	cfg := zap.NewProductionConfig()
-	cfg.Level = lvl
+	cfg.Level = level
 	baseLogger, _ := cfg.Build()
 	Log = baseLogger.Sugar()
 	return nil
 }
+
+// SetLevel changes the level of the logger built by Initialize in place.
+// An invalid level leaves the current one unchanged.
+func SetLevel(lvl string) error {
+	parsed, err := zapcore.ParseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(parsed)
+	return nil
+}
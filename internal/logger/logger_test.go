@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 func TestInitialize_Initialize(t *testing.T) {
@@ -52,3 +53,16 @@ func TestInitialize_Initialize(t *testing.T) {
 func resetLogger() {
 	Log = nil
 }
+
+func TestSetLevel(t *testing.T) {
+	assert.NoError(t, Initialize("info"))
+	assert.False(t, Log.Desugar().Core().Enabled(zap.DebugLevel))
+
+	assert.NoError(t, SetLevel("debug"))
+	assert.True(t, Log.Desugar().Core().Enabled(zap.DebugLevel))
+
+	assert.Error(t, SetLevel("notalevel"))
+	assert.True(t, Log.Desugar().Core().Enabled(zap.DebugLevel), "an invalid level keeps the current one")
+
+	assert.NoError(t, SetLevel("info"))
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// ClientIdentityMiddleware attaches the identity of the client certificate
+// verified during the TLS handshake to the request context. Requests without
+// a verified certificate pass through unchanged.
+func ClientIdentityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			if identity := certificateIdentity(r.TLS.VerifiedChains[0][0]); identity != "" {
+				r = r.WithContext(types.WithClientIdentity(r.Context(), identity))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// certificateIdentity is the subject common name, falling back to the first
+// DNS or URI SAN for certificates issued without one.
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	default:
+		return ""
+	}
+}
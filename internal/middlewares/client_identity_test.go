@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestClientIdentityMiddleware(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://metrics/agent-c")
+
+	tests := []struct {
+		name         string
+		state        *tls.ConnectionState
+		wantIdentity string
+		wantOK       bool
+	}{
+		{name: "plain HTTP"},
+		{name: "TLS without client certificate", state: &tls.ConnectionState{}},
+		{
+			name:         "common name",
+			state:        verifiedState(&x509.Certificate{Subject: pkix.Name{CommonName: "agent-a"}, DNSNames: []string{"a.example"}}),
+			wantIdentity: "agent-a",
+			wantOK:       true,
+		},
+		{
+			name:         "DNS SAN",
+			state:        verifiedState(&x509.Certificate{DNSNames: []string{"agent-b.example"}}),
+			wantIdentity: "agent-b.example",
+			wantOK:       true,
+		},
+		{
+			name:         "URI SAN",
+			state:        verifiedState(&x509.Certificate{URIs: []*url.URL{spiffe}}),
+			wantIdentity: "spiffe://metrics/agent-c",
+			wantOK:       true,
+		},
+		{name: "no usable name", state: verifiedState(&x509.Certificate{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIdentity string
+			var gotOK bool
+			handler := ClientIdentityMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIdentity, gotOK = types.ClientIdentityFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/update/counter/hits/1", nil)
+			req.TLS = tt.state
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantOK, gotOK)
+			assert.Equal(t, tt.wantIdentity, gotIdentity)
+		})
+	}
+}
+
+func verifiedState(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 	"go.uber.org/zap"
 )
 
@@ -17,11 +18,16 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		logger.Log.Desugar().Info("Request",
+		fields := []zap.Field{
 			zap.String("method", r.Method),
 			zap.String("uri", r.RequestURI),
 			zap.Duration("duration", duration),
-		)
+		}
+		if identity, ok := types.ClientIdentityFromContext(r.Context()); ok {
+			fields = append(fields, zap.String("identity", identity))
+		}
+
+		logger.Log.Desugar().Info("Request", fields...)
 
 		logger.Log.Desugar().Info("Response",
 			zap.Int("status", rw.statusCode),
@@ -53,3 +59,9 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.writtenSize += n
 	return n, err
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController so that
+// streaming handlers can flush through the logging middleware.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
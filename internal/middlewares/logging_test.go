@@ -7,6 +7,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -32,3 +37,32 @@ func TestLoggingMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	assert.Equal(t, "test response", body.String())
 }
+
+func TestLoggingMiddleware_Flush(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("chunk"))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})
+
+	w := httptest.NewRecorder()
+	LoggingMiddleware(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "chunk", w.Body.String())
+}
+
+func TestLoggingMiddleware_ClientIdentity(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core).Sugar()
+	defer func() { logger.Log = previous }()
+
+	handler := LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/update/counter/hits/1", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(types.WithClientIdentity(req.Context(), "agent-a")))
+
+	requests := logs.FilterMessage("Request").All()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "agent-a", requests[0].ContextMap()["identity"])
+}
@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// rateLimitSweepInterval is how often buckets that have refilled are
+// dropped, so clients that went away do not accumulate.
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitTopClients caps the per-client rate limited series reported per
+// interval to the most limited clients, so a flood of distinct clients
+// cannot create unbounded metrics. The overall counter still counts all.
+const rateLimitTopClients = 10
+
+// RateLimiter sheds load on the server. Each client, keyed by its
+// certificate identity or else its IP, gets a token bucket refilled at rate
+// requests per second holding up to burst tokens; a client with an empty
+// bucket gets 429. Independently, at most maxInFlight requests are served
+// at once and the rest get 503. A zero rate or maxInFlight disables that
+// limit. Both responses carry Retry-After.
+type RateLimiter struct {
+	longLived func(r *http.Request) bool
+	now       func() time.Time
+
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	limitedBy map[string]int64
+	reported  bool
+
+	maxInFlight atomic.Int64
+	inFlight    atomic.Int64
+	limited     atomic.Int64
+	shed        atomic.Int64
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter with the given limits. Requests for
+// which longLived reports true, such as streams, are rate limited when they
+// start but do not hold one of the maxInFlight slots.
+func NewRateLimiter(rate, burst, maxInFlight int, longLived func(r *http.Request) bool) *RateLimiter {
+	l := &RateLimiter{
+		longLived: longLived,
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+		limitedBy: make(map[string]int64),
+	}
+	l.SetLimits(rate, burst, maxInFlight)
+	return l
+}
+
+// SetLimits changes the limits of a running limiter. A burst smaller than
+// rate is raised to rate.
+func (l *RateLimiter) SetLimits(rate, burst, maxInFlight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = float64(max(rate, 0))
+	l.burst = float64(max(burst, rate, 1))
+	for _, b := range l.buckets {
+		b.tokens = min(b.tokens, l.burst)
+	}
+	l.maxInFlight.Store(int64(max(maxInFlight, 0)))
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := clientKey(r)
+
+		if wait, ok := l.allow(client); !ok {
+			l.limited.Add(1)
+			logger.Log.Warnw("Client rate limited", "client", client, "uri", r.RequestURI)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		if l.longLived == nil || !l.longLived(r) {
+			limit := l.maxInFlight.Load()
+			if n := l.inFlight.Add(1); limit > 0 && n > limit {
+				l.inFlight.Add(-1)
+				l.shed.Add(1)
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			defer l.inFlight.Add(-1)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the client's bucket. When the bucket is empty
+// it returns how long until the next token.
+func (l *RateLimiter) allow(client string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		return 0, true
+	}
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		l.limitedBy[client]++
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Metrics reports the limiter state as server metrics: requests rejected
+// since the previous call, overall and for the rateLimitTopClients most
+// limited clients, as counters, and the current load and limits as gauges.
+// Once both limits are disabled it reports the gauges as zero one last time
+// and then returns nothing.
+func (l *RateLimiter) Metrics() []types.Metrics {
+	l.mu.Lock()
+	rate, burst, clients := l.rate, l.burst, len(l.buckets)
+	maxInFlight := l.maxInFlight.Load()
+	limitedBy := l.limitedBy
+	l.limitedBy = make(map[string]int64)
+	enabled := rate > 0 || maxInFlight > 0
+	wasReported := l.reported
+	l.reported = enabled
+	l.mu.Unlock()
+
+	inFlight := float64(l.inFlight.Load())
+	if !enabled {
+		if !wasReported {
+			return nil
+		}
+		burst, clients, inFlight = 0, 0, 0
+	}
+
+	counter := func(name string, delta int64) types.Metrics {
+		return types.Metrics{ID: name, MType: types.Counter, Delta: &delta}
+	}
+	gauge := func(name string, value float64) types.Metrics {
+		return types.Metrics{ID: name, MType: types.Gauge, Value: &value}
+	}
+
+	metrics := []types.Metrics{
+		counter("server_rate_limited_requests", l.limited.Swap(0)),
+		counter("server_shed_requests", l.shed.Swap(0)),
+		gauge("server_in_flight_requests", inFlight),
+		gauge("server_max_in_flight_requests", float64(maxInFlight)),
+		gauge("server_rate_limit_rps", rate),
+		gauge("server_rate_limit_burst", burst),
+		gauge("server_rate_limit_clients", float64(clients)),
+	}
+
+	perClient := make([]string, 0, len(limitedBy))
+	for client := range limitedBy {
+		perClient = append(perClient, client)
+	}
+	sort.Slice(perClient, func(i, j int) bool {
+		a, b := perClient[i], perClient[j]
+		if limitedBy[a] != limitedBy[b] {
+			return limitedBy[a] > limitedBy[b]
+		}
+		return a < b
+	})
+	if len(perClient) > rateLimitTopClients {
+		perClient = perClient[:rateLimitTopClients]
+	}
+	for _, client := range perClient {
+		name := types.FormatMetricName("server_rate_limited_requests", map[string]string{"client": client})
+		metrics = append(metrics, counter(name, limitedBy[client]))
+	}
+
+	return metrics
+}
+
+// clientKey identifies the client for rate limiting: the identity of its
+// certificate when it presented one, else its IP address.
+func clientKey(r *http.Request) string {
+	if identity, ok := types.ClientIdentityFromContext(r.Context()); ok {
+		return identity
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func retryAfterSeconds(wait time.Duration) int {
+	return max(int(math.Ceil(wait.Seconds())), 1)
+}
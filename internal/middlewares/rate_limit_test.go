@@ -0,0 +1,168 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestRateLimiter_PerClient(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, 3, 0, nil)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	send := func(remoteAddr, identity string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/update/counter/hits/1", nil)
+		req.RemoteAddr = remoteAddr
+		if identity != "" {
+			req = req.WithContext(types.WithClientIdentity(req.Context(), identity))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, send("10.0.0.1:5000", "").Code, "burst is served")
+	}
+	rec := send("10.0.0.1:5001", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "keyed by IP, not port")
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, send("10.0.0.2:5000", "").Code, "other clients keep their own bucket")
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5000", "agent-a").Code, "identity takes precedence over IP")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5000", "").Code, "bucket refills at rate")
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:5000", "").Code)
+
+	limiter.SetLimits(0, 0, 0)
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5000", "").Code, "zero rate disables the limit")
+}
+
+func TestRateLimiter_MaxInFlight(t *testing.T) {
+	limiter := NewRateLimiter(0, 0, 1, func(r *http.Request) bool { return r.URL.Path == "/stream" })
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	send := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/value/counter/hits", "/stream"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(path)
+		}()
+		<-started
+	}
+
+	rec := send("/query")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Zero(t, limiter.inFlight.Load())
+}
+
+func TestRateLimiter_Metrics(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0, 0, 0, nil).Metrics(), "nothing to report while disabled")
+
+	limiter := NewRateLimiter(1, 1, 10, nil)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	byID := func(metrics []types.Metrics) map[string]types.Metrics {
+		m := make(map[string]types.Metrics, len(metrics))
+		for _, metric := range metrics {
+			m[metric.ID] = metric
+		}
+		return m
+	}
+
+	got := byID(limiter.Metrics())
+	require.Contains(t, got, "server_rate_limited_requests{client=10.0.0.1}")
+	assert.Equal(t, int64(2), *got["server_rate_limited_requests"].Delta)
+	assert.Equal(t, int64(2), *got["server_rate_limited_requests{client=10.0.0.1}"].Delta)
+	assert.Equal(t, int64(0), *got["server_shed_requests"].Delta)
+	assert.Equal(t, 10.0, *got["server_max_in_flight_requests"].Value)
+	assert.Equal(t, 1.0, *got["server_rate_limit_clients"].Value)
+
+	got = byID(limiter.Metrics())
+	assert.Equal(t, int64(0), *got["server_rate_limited_requests"].Delta, "counters report deltas")
+	assert.NotContains(t, got, "server_rate_limited_requests{client=10.0.0.1}")
+}
+
+func TestRateLimiter_MetricsTopClients(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, 0, nil)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Client i is limited i times; the least limited fall outside the cap.
+	clients := rateLimitTopClients + 5
+	for i := 1; i <= clients; i++ {
+		for range i + 1 {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = fmt.Sprintf("10.0.0.%d:5000", i)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	}
+
+	var perClient []string
+	var total int64
+	for _, m := range limiter.Metrics() {
+		switch {
+		case m.ID == "server_rate_limited_requests":
+			total = *m.Delta
+		case strings.HasPrefix(m.ID, "server_rate_limited_requests{"):
+			perClient = append(perClient, m.ID)
+		}
+	}
+
+	assert.Equal(t, int64(clients*(clients+1)/2), total, "the overall counter counts every client")
+	require.Len(t, perClient, rateLimitTopClients)
+	assert.Equal(t, fmt.Sprintf("server_rate_limited_requests{client=10.0.0.%d}", clients), perClient[0])
+	assert.NotContains(t, perClient, "server_rate_limited_requests{client=10.0.0.1}")
+}
+
+func TestRateLimiter_MetricsAfterDisable(t *testing.T) {
+	limiter := NewRateLimiter(5, 10, 20, nil)
+	require.NotEmpty(t, limiter.Metrics())
+
+	limiter.SetLimits(0, 0, 0)
+
+	got := limiter.Metrics()
+	require.NotEmpty(t, got, "gauges are reset once after the limits are disabled")
+	for _, m := range got {
+		if m.MType == types.Gauge {
+			assert.Zero(t, *m.Value, m.ID)
+		}
+	}
+
+	assert.Nil(t, limiter.Metrics(), "then nothing is reported")
+
+	limiter.SetLimits(5, 10, 0)
+	assert.NotEmpty(t, limiter.Metrics(), "re-enabling reports again")
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+// NewReadOnlyMiddleware makes a replica read-only by proxying every request
+// that is not a GET, HEAD or OPTIONS to the leader. The replica picks the
+// change up from the leader's stream like any other update. Requests are
+// sent over transport, or the default one when it is nil, and keep the
+// client's Authorization header, so the leader authorizes the client itself.
+func NewReadOnlyMiddleware(leaderAddr string, transport http.RoundTripper) (func(next http.Handler) http.Handler, error) {
+	addr := strings.TrimRight(leaderAddr, "/")
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+
+	leader, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(leader)
+			r.SetXForwarded()
+		},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Log.Errorw("Failed to proxy write to leader",
+				"uri", r.RequestURI,
+				"error", err,
+			)
+			http.Error(w, "leader unavailable", http.StatusBadGateway)
+		},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+			default:
+				proxy.ServeHTTP(w, r)
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	leader := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("leader:" + r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	defer leader.Close()
+
+	readOnly, err := NewReadOnlyMiddleware(strings.TrimPrefix(leader.URL, "http://"), nil)
+	require.NoError(t, err)
+
+	handler := readOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("replica:" + r.Method))
+	}))
+
+	tests := []struct {
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "/value/gauge/temp", "", http.StatusOK, "replica:GET"},
+		{http.MethodHead, "/", "", http.StatusOK, "replica:HEAD"},
+		{http.MethodPost, "/update/gauge/temp/1", "", http.StatusAccepted, "leader:POST /update/gauge/temp/1 "},
+		{http.MethodPost, "/updates/", "[]", http.StatusAccepted, "leader:POST /updates/ []"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestReadOnlyMiddleware_LeaderDown(t *testing.T) {
+	readOnly, err := NewReadOnlyMiddleware("http://127.0.0.1:1", nil)
+	require.NoError(t, err)
+
+	handler := readOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/update/gauge/temp/1", nil))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, "leader unavailable\n", rec.Body.String())
+}
+
+func TestReadOnlyMiddleware_TLSLeaderKeepsClientToken(t *testing.T) {
+	var gotAuth string
+	leader := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer leader.Close()
+
+	readOnly, err := NewReadOnlyMiddleware(leader.URL, leader.Client().Transport)
+	require.NoError(t, err)
+
+	handler := readOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/update/gauge/temp/1", nil)
+	req.Header.Set("Authorization", "Bearer client-secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "Bearer client-secret", gotAuth)
+}
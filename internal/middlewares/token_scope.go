@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+// NewTokenScopeMiddleware requires a bearer token granting the scope that
+// scope returns for the request; an empty scope lets the request through.
+// scopes looks up the token on every request, so tokens can be rotated or
+// revoked at runtime. Unknown tokens get 401, tokens lacking the scope 403.
+func NewTokenScopeMiddleware(
+	scopes func(token string) (name string, granted []string, ok bool),
+	scope func(r *http.Request) string,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			required := scope(r)
+			if required == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			name, granted, ok := scopes(token)
+			if !found || !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			if !slices.Contains(granted, required) {
+				logger.Log.Warnw("Token lacks required scope",
+					"token", name,
+					"scope", required,
+					"uri", r.RequestURI,
+				)
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
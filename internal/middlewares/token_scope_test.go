@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTokenScopeMiddleware(t *testing.T) {
+	tokens := map[string][]string{
+		"writer": {"write"},
+		"admin":  {"read", "admin"},
+	}
+	scopes := func(token string) (string, []string, bool) {
+		granted, ok := tokens[token]
+		return token, granted, ok
+	}
+
+	tests := []struct {
+		name       string
+		scope      string
+		header     string
+		wantStatus int
+	}{
+		{"valid token", "admin", "Bearer admin", http.StatusOK},
+		{"token without the scope", "admin", "Bearer writer", http.StatusForbidden},
+		{"unknown token", "write", "Bearer other", http.StatusUnauthorized},
+		{"missing header", "read", "", http.StatusUnauthorized},
+		{"not a bearer token", "write", "writer", http.StatusUnauthorized},
+		{"no scope required", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewTokenScopeMiddleware(scopes, func(*http.Request) string { return tt.scope })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// GraphiteCounterRules decides which Graphite paths are stored as counters.
+// A rule is a dotted pattern matched segment by segment with path.Match
+// semantics, so "app.*.requests" matches "app.web1.requests" but not
+// "app.web1.eu.requests". Paths matching no rule are stored as gauges.
+type GraphiteCounterRules struct {
+	patterns [][]string
+}
+
+func NewGraphiteCounterRules(rules []string) (*GraphiteCounterRules, error) {
+	r := &GraphiteCounterRules{}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		segments := strings.Split(rule, ".")
+		for _, segment := range segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("%w: %q", errors.ErrInvalidGraphiteRule, rule)
+			}
+		}
+		r.patterns = append(r.patterns, segments)
+	}
+	return r, nil
+}
+
+func (r *GraphiteCounterRules) IsCounter(metricPath string) bool {
+	if r == nil || len(r.patterns) == 0 {
+		return false
+	}
+
+	segments := strings.Split(metricPath, ".")
+	for _, pattern := range r.patterns {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i := range pattern {
+		if ok, _ := path.Match(pattern[i], segments[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseGraphiteLine parses a plaintext protocol line "path value timestamp".
+// The timestamp is validated but not kept: stored metrics carry the time they
+// were accepted by the server. Counter values are rounded to integers.
+func ParseGraphiteLine(line string, rules *GraphiteCounterRules) (types.Metrics, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return types.Metrics{}, fmt.Errorf("%w: expected \"path value timestamp\", got %q", errors.ErrMalformedLine, line)
+	}
+
+	metricPath, rawValue, rawTimestamp := fields[0], fields[1], fields[2]
+
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return types.Metrics{}, fmt.Errorf("%w: invalid value %q", errors.ErrMalformedLine, rawValue)
+	}
+
+	if _, err := strconv.ParseFloat(rawTimestamp, 64); err != nil {
+		return types.Metrics{}, fmt.Errorf("%w: invalid timestamp %q", errors.ErrMalformedLine, rawTimestamp)
+	}
+
+	if rules.IsCounter(metricPath) {
+		delta := int64(math.Round(value))
+		return types.Metrics{ID: metricPath, MType: types.Counter, Delta: &delta}, nil
+	}
+
+	return types.Metrics{ID: metricPath, MType: types.Gauge, Value: &value}, nil
+}
@@ -0,0 +1,78 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestGraphiteCounterRules(t *testing.T) {
+	rules, err := NewGraphiteCounterRules([]string{"app.*.requests", " stats.* ", "", "*.errors"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"app.web1.requests", true},
+		{"app.web1.eu.requests", false},
+		{"db.errors", true},
+		{"db.errors.total", false},
+		{"app.web1.latency", false},
+		{"stats.hits", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.IsCounter(tt.path))
+		})
+	}
+
+	var none *GraphiteCounterRules
+	assert.False(t, none.IsCounter("app.web1.requests"))
+}
+
+func TestNewGraphiteCounterRules_Invalid(t *testing.T) {
+	_, err := NewGraphiteCounterRules([]string{"app.[.requests"})
+	assert.ErrorIs(t, err, errors.ErrInvalidGraphiteRule)
+}
+
+func TestParseGraphiteLine(t *testing.T) {
+	rules, err := NewGraphiteCounterRules([]string{"*.requests"})
+	require.NoError(t, err)
+
+	i64 := func(v int64) *int64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		line    string
+		want    types.Metrics
+		wantErr error
+	}{
+		{"gauge", "servers.web1.load 0.75 1700000000", types.Metrics{ID: "servers.web1.load", MType: types.Gauge, Value: f64(0.75)}, nil},
+		{"counter by rule", "web1.requests 12.4 1700000000", types.Metrics{ID: "web1.requests", MType: types.Counter, Delta: i64(12)}, nil},
+		{"tabs and negative timestamp", "disk.free\t10\t-1", types.Metrics{ID: "disk.free", MType: types.Gauge, Value: f64(10)}, nil},
+		{"missing timestamp", "disk.free 10", types.Metrics{}, errors.ErrMalformedLine},
+		{"extra field", "disk.free 10 1 2", types.Metrics{}, errors.ErrMalformedLine},
+		{"bad value", "disk.free ten 1700000000", types.Metrics{}, errors.ErrMalformedLine},
+		{"nan value", "disk.free NaN 1700000000", types.Metrics{}, errors.ErrMalformedLine},
+		{"bad timestamp", "disk.free 10 now", types.Metrics{}, errors.ErrMalformedLine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGraphiteLine(tt.line, rules)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
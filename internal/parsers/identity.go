@@ -0,0 +1,29 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+)
+
+// ParseIdentityPrefixes reads "identity=prefix" entries into the metric name
+// prefixes each client identity may write. An identity may be listed more
+// than once; an empty prefix allows every name.
+func ParseIdentityPrefixes(entries []string) (map[string][]string, error) {
+	prefixes := make(map[string][]string)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		identity, prefix, ok := strings.Cut(entry, "=")
+		identity = strings.TrimSpace(identity)
+		if !ok || identity == "" {
+			return nil, fmt.Errorf("%w: %q", errors.ErrInvalidIdentityPrefix, entry)
+		}
+		prefixes[identity] = append(prefixes[identity], strings.TrimSpace(prefix))
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,30 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+)
+
+func TestParseIdentityPrefixes(t *testing.T) {
+	prefixes, err := ParseIdentityPrefixes([]string{"agent-a=app.", " agent-a = db. ", "", "admin=", "agent-b=cpu{host=b"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"agent-a": {"app.", "db."},
+		"admin":   {""},
+		"agent-b": {"cpu{host=b"},
+	}, prefixes)
+}
+
+func TestParseIdentityPrefixes_Invalid(t *testing.T) {
+	for _, entry := range []string{"agent-a", "=app."} {
+		t.Run(entry, func(t *testing.T) {
+			_, err := ParseIdentityPrefixes([]string{entry})
+			assert.ErrorIs(t, err, errors.ErrInvalidIdentityPrefix)
+		})
+	}
+}
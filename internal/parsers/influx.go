@@ -0,0 +1,178 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// InfluxParser parses InfluxDB line protocol. Every numeric field becomes a
+// metric named measurement_field with the tags folded in as labels; fields
+// whose key ends with one of counterSuffixes are stored as counters, the rest
+// as gauges. String and boolean fields are skipped.
+type InfluxParser struct {
+	counterSuffixes []string
+}
+
+func NewInfluxParser(counterSuffixes []string) *InfluxParser {
+	suffixes := make([]string, 0, len(counterSuffixes))
+	for _, suffix := range counterSuffixes {
+		if suffix = strings.TrimSpace(suffix); suffix != "" {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	return &InfluxParser{counterSuffixes: suffixes}
+}
+
+// ParseLine parses one line. Blank lines and comments yield no metrics. The
+// timestamp is validated but not kept.
+func (p *InfluxParser) ParseLine(line string) ([]types.Metrics, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '#' {
+		return nil, nil
+	}
+
+	sections := splitInflux(line, ' ', true)
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, fmt.Errorf("%w: expected \"measurement[,tags] fields [timestamp]\"", errors.ErrMalformedLine)
+	}
+
+	key := splitInflux(sections[0], ',', false)
+	measurement := unescapeInflux(key[0])
+	if measurement == "" {
+		return nil, fmt.Errorf("%w: missing measurement", errors.ErrMalformedLine)
+	}
+
+	labels := make(map[string]string, len(key)-1)
+	for _, tag := range key[1:] {
+		k, v, ok := cutInflux(tag, '=')
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("%w: invalid tag %q", errors.ErrMalformedLine, tag)
+		}
+		labels[unescapeInflux(k)] = unescapeInflux(v)
+	}
+
+	if len(sections) == 3 {
+		if _, err := strconv.ParseInt(sections[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid timestamp %q", errors.ErrMalformedLine, sections[2])
+		}
+	}
+
+	var metrics []types.Metrics
+	for _, field := range splitInflux(sections[1], ',', true) {
+		k, v, ok := cutInflux(field, '=')
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("%w: invalid field %q", errors.ErrMalformedLine, field)
+		}
+
+		fieldKey := unescapeInflux(k)
+		value, numeric, err := parseInfluxFieldValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q: %s", errors.ErrMalformedLine, fieldKey, err)
+		}
+		if !numeric {
+			continue
+		}
+
+		id := types.FormatMetricName(measurement+"_"+fieldKey, labels)
+		if p.isCounter(fieldKey) {
+			delta := int64(math.Round(value))
+			metrics = append(metrics, types.Metrics{ID: id, MType: types.Counter, Delta: &delta})
+		} else {
+			metrics = append(metrics, types.Metrics{ID: id, MType: types.Gauge, Value: &value})
+		}
+	}
+
+	return metrics, nil
+}
+
+func (p *InfluxParser) isCounter(field string) bool {
+	for _, suffix := range p.counterSuffixes {
+		if strings.HasSuffix(field, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseInfluxFieldValue reports whether the value is numeric and returns it
+// as a float. Strings and booleans are valid but not numeric.
+func parseInfluxFieldValue(raw string) (float64, bool, error) {
+	switch {
+	case raw[0] == '"':
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return 0, false, fmt.Errorf("unterminated string")
+		}
+		return 0, false, nil
+	case raw == "t" || raw == "T" || raw == "true" || raw == "True" || raw == "TRUE",
+		raw == "f" || raw == "F" || raw == "false" || raw == "False" || raw == "FALSE":
+		return 0, false, nil
+	case strings.HasSuffix(raw, "i"):
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid integer %q", raw)
+		}
+		return float64(v), true, nil
+	case strings.HasSuffix(raw, "u"):
+		v, err := strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		return float64(v), true, nil
+	default:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, false, fmt.Errorf("invalid float %q", raw)
+		}
+		return v, true, nil
+	}
+}
+
+// splitInflux splits s on sep, ignoring backslash-escaped separators and,
+// when quotes is set, separators inside double-quoted strings.
+func splitInflux(s string, sep byte, quotes bool) []string {
+	var (
+		parts    []string
+		start    int
+		inQuotes bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case quotes && c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func cutInflux(s string, sep byte) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+var influxUnescaper = strings.NewReplacer(`\,`, `,`, `\ `, ` `, `\=`, `=`, `\"`, `"`, `\\`, `\`)
+
+func unescapeInflux(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return influxUnescaper.Replace(s)
+}
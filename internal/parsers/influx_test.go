@@ -0,0 +1,72 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestInfluxParser_ParseLine(t *testing.T) {
+	parser := NewInfluxParser([]string{"_total", " ", "errors"})
+
+	i64 := func(v int64) *int64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		line    string
+		want    []types.Metrics
+		wantErr error
+	}{
+		{
+			name: "tags, mixed fields and timestamp",
+			line: `cpu,host=web1,cpu=cpu0 usage_idle=97.5,usage_user=2i,requests_total=15u,note="busy, really" 1700000000000000000`,
+			want: []types.Metrics{
+				{ID: "cpu_usage_idle{cpu=cpu0,host=web1}", MType: types.Gauge, Value: f64(97.5)},
+				{ID: "cpu_usage_user{cpu=cpu0,host=web1}", MType: types.Gauge, Value: f64(2)},
+				{ID: "cpu_requests_total{cpu=cpu0,host=web1}", MType: types.Counter, Delta: i64(15)},
+			},
+		},
+		{
+			name: "no tags, no timestamp",
+			line: "mem free=1024i",
+			want: []types.Metrics{{ID: "mem_free", MType: types.Gauge, Value: f64(1024)}},
+		},
+		{
+			name: "escaped measurement and tag",
+			line: `disk\ io,path=C:\,data errors=3i`,
+			want: []types.Metrics{{ID: `disk io_errors{path=C:\,data}`, MType: types.Counter, Delta: i64(3)}},
+		},
+		{
+			name: "booleans and strings are skipped",
+			line: `status up=true,msg="ok"`,
+			want: nil,
+		},
+		{name: "comment", line: "# comment", want: nil},
+		{name: "blank", line: "   ", want: nil},
+		{name: "missing fields", line: "cpu,host=a", wantErr: errors.ErrMalformedLine},
+		{name: "bad tag", line: "cpu,host value=1", wantErr: errors.ErrMalformedLine},
+		{name: "bad field", line: "cpu value", wantErr: errors.ErrMalformedLine},
+		{name: "bad integer", line: "cpu value=1.5i", wantErr: errors.ErrMalformedLine},
+		{name: "bad float", line: "cpu value=abc", wantErr: errors.ErrMalformedLine},
+		{name: "unterminated string", line: `cpu msg="oops`, wantErr: errors.ErrMalformedLine},
+		{name: "bad timestamp", line: "cpu value=1 yesterday", wantErr: errors.ErrMalformedLine},
+		{name: "empty measurement", line: ",host=a value=1", wantErr: errors.ErrMalformedLine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseLine(tt.line)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// ConvertOTLPMetrics flattens an OTLP export request into metrics. Resource
+// and data point attributes become labels, with data point attributes taking
+// precedence. Monotonic delta sums are stored as counters, gauges and
+// non-monotonic sums as gauges, and delta histograms as a <name>_count
+// counter. Data points that cannot be stored are counted and described in
+// the returned errors, the rest are still converted.
+func ConvertOTLPMetrics(req types.OTLPExportMetricsRequest) ([]types.Metrics, int, []error) {
+	var (
+		metrics  []types.Metrics
+		rejected int
+		errs     []error
+	)
+
+	reject := func(name string, points int, err error) {
+		rejected += points
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	for _, rm := range req.ResourceMetrics {
+		resource := otlpLabels(nil, rm.Resource.Attributes)
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == "" {
+					reject("<unnamed>", otlpPointCount(m), errors.ErrInvalidMetricID)
+					continue
+				}
+
+				switch {
+				case m.Gauge != nil:
+					for _, dp := range m.Gauge.DataPoints {
+						metric, err := otlpGauge(m.Name, resource, dp)
+						if err != nil {
+							reject(m.Name, 1, err)
+							continue
+						}
+						metrics = append(metrics, metric)
+					}
+				case m.Sum != nil && !m.Sum.IsMonotonic:
+					for _, dp := range m.Sum.DataPoints {
+						metric, err := otlpGauge(m.Name, resource, dp)
+						if err != nil {
+							reject(m.Name, 1, err)
+							continue
+						}
+						metrics = append(metrics, metric)
+					}
+				case m.Sum != nil:
+					if m.Sum.AggregationTemporality != types.OTLPTemporalityDelta {
+						reject(m.Name, len(m.Sum.DataPoints), errors.ErrUnsupportedTemporality)
+						continue
+					}
+					for _, dp := range m.Sum.DataPoints {
+						metric, err := otlpCounter(m.Name, resource, dp)
+						if err != nil {
+							reject(m.Name, 1, err)
+							continue
+						}
+						metrics = append(metrics, metric)
+					}
+				case m.Histogram != nil:
+					if m.Histogram.AggregationTemporality != types.OTLPTemporalityDelta {
+						reject(m.Name, len(m.Histogram.DataPoints), errors.ErrUnsupportedTemporality)
+						continue
+					}
+					for _, dp := range m.Histogram.DataPoints {
+						delta := int64(dp.Count)
+						metrics = append(metrics, types.Metrics{
+							ID:    types.FormatMetricName(m.Name+"_count", otlpLabels(resource, dp.Attributes)),
+							MType: types.Counter,
+							Delta: &delta,
+						})
+					}
+				default:
+					reject(m.Name, otlpPointCount(m), errors.ErrUnsupportedMetricData)
+				}
+			}
+		}
+	}
+
+	return metrics, rejected, errs
+}
+
+func otlpGauge(name string, resource map[string]string, dp types.OTLPNumberDataPoint) (types.Metrics, error) {
+	var value float64
+	switch {
+	case dp.AsDouble != nil:
+		value = *dp.AsDouble
+	case dp.AsInt != nil:
+		value = float64(*dp.AsInt)
+	default:
+		return types.Metrics{}, errors.ErrMissingDataPointValue
+	}
+
+	return types.Metrics{
+		ID:    types.FormatMetricName(name, otlpLabels(resource, dp.Attributes)),
+		MType: types.Gauge,
+		Value: &value,
+	}, nil
+}
+
+func otlpCounter(name string, resource map[string]string, dp types.OTLPNumberDataPoint) (types.Metrics, error) {
+	var delta int64
+	switch {
+	case dp.AsInt != nil:
+		delta = int64(*dp.AsInt)
+	case dp.AsDouble != nil:
+		delta = int64(math.Round(*dp.AsDouble))
+	default:
+		return types.Metrics{}, errors.ErrMissingDataPointValue
+	}
+
+	return types.Metrics{
+		ID:    types.FormatMetricName(name, otlpLabels(resource, dp.Attributes)),
+		MType: types.Counter,
+		Delta: &delta,
+	}, nil
+}
+
+func otlpLabels(base map[string]string, attrs []types.OTLPKeyValue) map[string]string {
+	labels := make(map[string]string, len(base)+len(attrs))
+	for k, v := range base {
+		labels[k] = v
+	}
+	for _, attr := range attrs {
+		if value := attr.Value.String(); attr.Key != "" && value != "" {
+			labels[attr.Key] = value
+		}
+	}
+	return labels
+}
+
+func otlpPointCount(m types.OTLPMetric) int {
+	switch {
+	case m.Gauge != nil:
+		return len(m.Gauge.DataPoints)
+	case m.Sum != nil:
+		return len(m.Sum.DataPoints)
+	case m.Histogram != nil:
+		return len(m.Histogram.DataPoints)
+	default:
+		return 1
+	}
+}
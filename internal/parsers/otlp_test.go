@@ -0,0 +1,105 @@
+package parsers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestConvertOTLPMetrics(t *testing.T) {
+	i64 := func(v int64) *int64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		body         string
+		want         []types.Metrics
+		wantRejected int
+		wantErrs     []error
+	}{
+		{
+			name: "gauge with resource and point attributes",
+			body: `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}},{"key":"host","value":{"stringValue":"a"}}]},
+				"scopeMetrics":[{"metrics":[{"name":"temp","gauge":{"dataPoints":[
+					{"attributes":[{"key":"host","value":{"stringValue":"b"}},{"key":"core","value":{"intValue":"2"}}],"asDouble":36.6},
+					{"asInt":"7"}]}}]}]}]}`,
+			want: []types.Metrics{
+				{ID: "temp{core=2,host=b,service.name=api}", MType: types.Gauge, Value: f64(36.6)},
+				{ID: "temp{host=a,service.name=api}", MType: types.Gauge, Value: f64(7)},
+			},
+		},
+		{
+			name: "monotonic delta sum becomes counter",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"requests","sum":{"isMonotonic":true,"aggregationTemporality":1,"dataPoints":[
+				{"asInt":"5","attributes":[{"key":"ok","value":{"boolValue":true}}]},{"asDouble":2.6}]}}]}]}]}`,
+			want: []types.Metrics{
+				{ID: "requests{ok=true}", MType: types.Counter, Delta: i64(5)},
+				{ID: "requests", MType: types.Counter, Delta: i64(3)},
+			},
+		},
+		{
+			name: "temporality by name",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"hits","sum":{"isMonotonic":true,"aggregationTemporality":"AGGREGATION_TEMPORALITY_DELTA","dataPoints":[{"asInt":1}]}}]}]}]}`,
+			want: []types.Metrics{{ID: "hits", MType: types.Counter, Delta: i64(1)}},
+		},
+		{
+			name: "non-monotonic sum becomes gauge",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"queue","sum":{"aggregationTemporality":2,"dataPoints":[{"asInt":"-3"}]}}]}]}]}`,
+			want: []types.Metrics{{ID: "queue", MType: types.Gauge, Value: f64(-3)}},
+		},
+		{
+			name: "delta histogram count",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"latency","histogram":{"aggregationTemporality":1,"dataPoints":[{"count":"4","sum":1.5}]}}]}]}]}`,
+			want: []types.Metrics{{ID: "latency_count", MType: types.Counter, Delta: i64(4)}},
+		},
+		{
+			name:         "cumulative sum rejected",
+			body:         `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"requests","sum":{"isMonotonic":true,"aggregationTemporality":2,"dataPoints":[{"asInt":"1"},{"asInt":"2"}]}}]}]}]}`,
+			wantRejected: 2,
+			wantErrs:     []error{errors.ErrUnsupportedTemporality},
+		},
+		{
+			name: "partial rejection",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[
+				{"name":"temp","gauge":{"dataPoints":[{"asDouble":1},{}]}},
+				{"name":"summary","summary":{"dataPoints":[{}]}},
+				{"gauge":{"dataPoints":[{"asDouble":1}]}}]}]}]}`,
+			want:         []types.Metrics{{ID: "temp", MType: types.Gauge, Value: f64(1)}},
+			wantRejected: 3,
+			wantErrs:     []error{errors.ErrMissingDataPointValue, errors.ErrUnsupportedMetricData, errors.ErrInvalidMetricID},
+		},
+		{name: "empty", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req types.OTLPExportMetricsRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+
+			got, rejected, errs := ConvertOTLPMetrics(req)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantRejected, rejected)
+			require.Len(t, errs, len(tt.wantErrs))
+			for i, want := range tt.wantErrs {
+				assert.ErrorIs(t, errs[i], want)
+			}
+		})
+	}
+}
+
+func TestOTLPPartialSuccess_Marshal(t *testing.T) {
+	data, err := json.Marshal(types.OTLPExportMetricsResponse{
+		PartialSuccess: &types.OTLPPartialSuccess{RejectedDataPoints: 2, ErrorMessage: "bad"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"partialSuccess":{"rejectedDataPoints":"2","errorMessage":"bad"}}`, string(data))
+
+	data, err = json.Marshal(types.OTLPExportMetricsResponse{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
@@ -0,0 +1,92 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// ParseStatsDLine parses a single StatsD line such as "name:1|c|@0.5".
+//
+// Counters ("c") become counters with the delta scaled up by the sample rate.
+// Gauges ("g") become gauges; signed gauge values are relative adjustments in
+// StatsD and are rejected since they cannot be applied atomically here.
+// Timings ("ms", "h") have no histogram type to map to, so the last observed
+// value is stored as a gauge. Sets ("s") are not supported. DogStatsD tag
+// sections ("|#...") are ignored.
+func ParseStatsDLine(line string) (types.Metrics, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return types.Metrics{}, fmt.Errorf("%w: %q", errors.ErrMalformedLine, line)
+	}
+
+	sections := strings.Split(rest, "|")
+	if len(sections) < 2 || sections[0] == "" {
+		return types.Metrics{}, fmt.Errorf("%w: %q", errors.ErrMalformedLine, line)
+	}
+
+	rawValue, statType := sections[0], sections[1]
+
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return types.Metrics{}, fmt.Errorf("%w: invalid value %q", errors.ErrMalformedLine, rawValue)
+	}
+
+	rate := 1.0
+	for _, section := range sections[2:] {
+		switch {
+		case strings.HasPrefix(section, "@"):
+			rate, err = strconv.ParseFloat(section[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return types.Metrics{}, fmt.Errorf("%w: invalid sample rate %q", errors.ErrMalformedLine, section)
+			}
+		case strings.HasPrefix(section, "#"):
+		default:
+			return types.Metrics{}, fmt.Errorf("%w: unknown section %q", errors.ErrMalformedLine, section)
+		}
+	}
+
+	switch statType {
+	case "c":
+		delta := int64(math.Round(value / rate))
+		return types.Metrics{ID: name, MType: types.Counter, Delta: &delta}, nil
+	case "g":
+		if rawValue[0] == '+' || rawValue[0] == '-' {
+			return types.Metrics{}, fmt.Errorf("%w: relative gauge %q", errors.ErrUnsupportedStatType, line)
+		}
+		return types.Metrics{ID: name, MType: types.Gauge, Value: &value}, nil
+	case "ms", "h":
+		return types.Metrics{ID: name, MType: types.Gauge, Value: &value}, nil
+	default:
+		return types.Metrics{}, fmt.Errorf("%w: %q", errors.ErrUnsupportedStatType, statType)
+	}
+}
+
+// ParseStatsDPacket parses every newline separated line of a packet. Lines
+// that fail to parse are returned as errors alongside the metrics that did.
+func ParseStatsDPacket(packet []byte) ([]types.Metrics, []error) {
+	var (
+		metrics []types.Metrics
+		errs    []error
+	)
+
+	for _, line := range strings.Split(string(packet), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		metric, err := ParseStatsDLine(line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, errs
+}
@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestParseStatsDLine(t *testing.T) {
+	i64 := func(v int64) *int64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		line    string
+		want    types.Metrics
+		wantErr error
+	}{
+		{"counter", "hits:1|c", types.Metrics{ID: "hits", MType: types.Counter, Delta: i64(1)}, nil},
+		{"sampled counter", "hits:3|c|@0.1", types.Metrics{ID: "hits", MType: types.Counter, Delta: i64(30)}, nil},
+		{"negative counter", "hits:-2|c", types.Metrics{ID: "hits", MType: types.Counter, Delta: i64(-2)}, nil},
+		{"gauge", "load:3.2|g", types.Metrics{ID: "load", MType: types.Gauge, Value: f64(3.2)}, nil},
+		{"timing", "latency:5|ms", types.Metrics{ID: "latency", MType: types.Gauge, Value: f64(5)}, nil},
+		{"histogram", "size:12.5|h|@0.5", types.Metrics{ID: "size", MType: types.Gauge, Value: f64(12.5)}, nil},
+		{"tags ignored", "hits:1|c|#env:prod", types.Metrics{ID: "hits", MType: types.Counter, Delta: i64(1)}, nil},
+		{"relative gauge", "load:+1|g", types.Metrics{}, errors.ErrUnsupportedStatType},
+		{"set", "users:42|s", types.Metrics{}, errors.ErrUnsupportedStatType},
+		{"missing colon", "hits1|c", types.Metrics{}, errors.ErrMalformedLine},
+		{"missing type", "hits:1", types.Metrics{}, errors.ErrMalformedLine},
+		{"empty name", ":1|c", types.Metrics{}, errors.ErrMalformedLine},
+		{"bad value", "hits:x|c", types.Metrics{}, errors.ErrMalformedLine},
+		{"bad rate", "hits:1|c|@2", types.Metrics{}, errors.ErrMalformedLine},
+		{"unknown section", "hits:1|c|x", types.Metrics{}, errors.ErrMalformedLine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatsDLine(tt.line)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseStatsDPacket(t *testing.T) {
+	metrics, errs := ParseStatsDPacket([]byte("hits:1|c\nbroken\n\nload:2|g\n"))
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "hits", metrics[0].ID)
+	assert.Equal(t, "load", metrics[1].ID)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], errors.ErrMalformedLine)
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type metricFileSnapshot struct {
+	Segment int             `json:"segment"`
+	Metrics []types.Metrics `json:"metrics"`
+}
+
+type MetricFileSnapshotRepository struct {
+	storage engines.Storage[types.MetricID, types.Metrics]
+	wal     *engines.WAL
+	path    string
+}
+
+func NewMetricFileSnapshotRepository(
+	storage engines.Storage[types.MetricID, types.Metrics],
+	wal *engines.WAL,
+	path string,
+) *MetricFileSnapshotRepository {
+	return &MetricFileSnapshotRepository{
+		storage: storage,
+		wal:     wal,
+		path:    path,
+	}
+}
+
+func (repo *MetricFileSnapshotRepository) Snapshot(
+	ctx context.Context,
+) error {
+	// Rotating while the copy is still locked makes the new segment start
+	// exactly where the snapshot ends.
+	var segment int
+	data, err := repo.storage.Snapshot(func() error {
+		var err error
+		segment, err = repo.wal.Rotate()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	snapshot := metricFileSnapshot{
+		Segment: segment,
+		Metrics: make([]types.Metrics, 0, len(data)),
+	}
+	for _, metric := range data {
+		snapshot.Metrics = append(snapshot.Metrics, metric)
+	}
+
+	if err := writeFileAtomic(repo.path, snapshot); err != nil {
+		return err
+	}
+
+	return repo.wal.Truncate(segment)
+}
+
+func (repo *MetricFileSnapshotRepository) Restore(
+	ctx context.Context,
+) error {
+	var snapshot metricFileSnapshot
+
+	data, err := os.ReadFile(repo.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return err
+		}
+	}
+
+	restored := make(map[types.MetricID]types.Metrics, len(snapshot.Metrics))
+	for _, metric := range snapshot.Metrics {
+		restored[types.MetricID{ID: metric.ID, MType: metric.MType}] = metric
+	}
+
+	err = repo.wal.Replay(snapshot.Segment, func(record []byte) error {
+		return applyMetricWALRecord(restored, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	return repo.storage.Load(restored, true, nil)
+}
+
+func writeFileAtomic(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(v); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
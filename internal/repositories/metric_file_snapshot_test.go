@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricFileSnapshotRepository_SnapshotAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.json")
+	ctx := context.Background()
+
+	gauge := 1.5
+	counter := int64(10)
+	counterAfter := int64(15)
+
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	wal, err := engines.NewWAL(path+".wal", engines.FsyncAlways)
+	require.NoError(t, err)
+
+	saver := NewMetricMemoryWALSaveRepository(storage, wal)
+	snapshots := NewMetricFileSnapshotRepository(storage, wal, path)
+
+	require.NoError(t, saver.Save(ctx, types.Metrics{ID: "g", MType: types.Gauge, Value: &gauge}))
+	require.NoError(t, saver.Save(ctx, types.Metrics{ID: "c", MType: types.Counter, Delta: &counter}))
+	require.NoError(t, snapshots.Snapshot(ctx))
+
+	_, err = os.Stat(path)
+	require.NoError(t, err, "snapshot file should exist")
+
+	// Written after the snapshot, so only the write-ahead log has it.
+	require.NoError(t, saver.Save(ctx, types.Metrics{ID: "c", MType: types.Counter, Delta: &counterAfter}))
+
+	// Simulate a kill: no final snapshot, reopen everything from disk.
+	restoredStorage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	restoredWAL, err := engines.NewWAL(path+".wal", engines.FsyncAlways)
+	require.NoError(t, err)
+	defer restoredWAL.Close()
+
+	restorer := NewMetricFileSnapshotRepository(restoredStorage, restoredWAL, path)
+	require.NoError(t, restorer.Restore(ctx))
+
+	assert.Len(t, restoredStorage.Data, 2)
+	assert.Equal(t, gauge, *restoredStorage.Data[types.MetricID{ID: "g", MType: types.Gauge}].Value)
+	assert.Equal(t, counterAfter, *restoredStorage.Data[types.MetricID{ID: "c", MType: types.Counter}].Delta)
+}
+
+func TestMetricFileSnapshotRepository_SnapshotTruncatesWAL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.json")
+	ctx := context.Background()
+
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	wal, err := engines.NewWAL(path+".wal", engines.FsyncAlways)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	saver := NewMetricMemoryWALSaveRepository(storage, wal)
+	snapshots := NewMetricFileSnapshotRepository(storage, wal, path)
+
+	value := 3.0
+	require.NoError(t, saver.Save(ctx, types.Metrics{ID: "g", MType: types.Gauge, Value: &value}))
+	require.NoError(t, snapshots.Snapshot(ctx))
+
+	var records int
+	require.NoError(t, wal.Replay(0, func([]byte) error {
+		records++
+		return nil
+	}))
+	assert.Zero(t, records, "records covered by the snapshot should be truncated")
+
+	entries, err := os.ReadDir(path + ".wal")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestMetricFileSnapshotRepository_RestoreWithoutFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	wal, err := engines.NewWAL(path+".wal", engines.FsyncNone)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	repo := NewMetricFileSnapshotRepository(storage, wal, path)
+	require.NoError(t, repo.Restore(context.Background()))
+	assert.Empty(t, storage.Data)
+}
+
+func TestMetricFileSnapshotRepository_RestoreInvalidSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	wal, err := engines.NewWAL(path+".wal", engines.FsyncNone)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	repo := NewMetricFileSnapshotRepository(storage, wal, path)
+	assert.Error(t, repo.Restore(context.Background()))
+}
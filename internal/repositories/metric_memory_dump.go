@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricMemoryDumpRepository struct {
+	storage engines.Storage[types.MetricID, types.Metrics]
+	wal     *engines.WAL
+}
+
+// NewMetricMemoryDumpRepository creates a repository that dumps and loads the
+// whole store. wal may be nil when persistence is disabled.
+func NewMetricMemoryDumpRepository(
+	storage engines.Storage[types.MetricID, types.Metrics],
+	wal *engines.WAL,
+) *MetricMemoryDumpRepository {
+	return &MetricMemoryDumpRepository{
+		storage: storage,
+		wal:     wal,
+	}
+}
+
+// Dump returns a point-in-time copy of the store. The lock is held only
+// while copying, sorting and serialization happen outside of it.
+func (repo *MetricMemoryDumpRepository) Dump(
+	ctx context.Context,
+) ([]types.Metrics, error) {
+	data, err := repo.storage.Snapshot(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]types.Metrics, 0, len(data))
+	for _, metric := range data {
+		metrics = append(metrics, metric)
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].ID != metrics[j].ID {
+			return metrics[i].ID < metrics[j].ID
+		}
+		return metrics[i].MType < metrics[j].MType
+	})
+
+	return metrics, nil
+}
+
+// Load atomically replaces the store with metrics or merges them into it.
+func (repo *MetricMemoryDumpRepository) Load(
+	ctx context.Context,
+	metrics []types.Metrics,
+	replace bool,
+) error {
+	data := make(map[types.MetricID]types.Metrics, len(metrics))
+	loadMetrics(data, metrics, false)
+
+	var hook func() error
+	if repo.wal != nil {
+		record, err := json.Marshal(metricWALRecord{
+			Op:      metricWALOpLoad,
+			Metrics: metrics,
+			Replace: replace,
+		})
+		if err != nil {
+			return err
+		}
+
+		hook = func() error {
+			return repo.wal.Append(record)
+		}
+	}
+
+	return repo.storage.Load(data, replace, hook)
+}
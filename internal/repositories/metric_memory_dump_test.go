@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMemoryDumpRepository_Dump(t *testing.T) {
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	repo := NewMetricMemoryDumpRepository(storage, nil)
+
+	storage.Mu.Lock()
+	for _, metric := range []types.Metrics{
+		{ID: "b", MType: types.Gauge},
+		{ID: "a", MType: types.Gauge},
+		{ID: "a", MType: types.Counter},
+	} {
+		storage.Data[types.MetricID{ID: metric.ID, MType: metric.MType}] = metric
+	}
+	storage.Mu.Unlock()
+
+	metrics, err := repo.Dump(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []types.Metrics{
+		{ID: "a", MType: types.Counter},
+		{ID: "a", MType: types.Gauge},
+		{ID: "b", MType: types.Gauge},
+	}, metrics)
+}
+
+func TestMetricMemoryDumpRepository_Load(t *testing.T) {
+	existing := types.Metrics{ID: "existing", MType: types.Gauge}
+	loaded := types.Metrics{ID: "loaded", MType: types.Gauge}
+
+	tests := []struct {
+		name    string
+		replace bool
+		want    map[types.MetricID]types.Metrics
+	}{
+		{
+			name:    "replace drops existing metrics",
+			replace: true,
+			want: map[types.MetricID]types.Metrics{
+				{ID: "loaded", MType: types.Gauge}: loaded,
+			},
+		},
+		{
+			name:    "merge keeps existing metrics",
+			replace: false,
+			want: map[types.MetricID]types.Metrics{
+				{ID: "existing", MType: types.Gauge}: existing,
+				{ID: "loaded", MType: types.Gauge}:   loaded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wal, err := engines.NewWAL(filepath.Join(t.TempDir(), "wal"), engines.FsyncAlways)
+			require.NoError(t, err)
+			defer wal.Close()
+
+			storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+			storage.Data[types.MetricID{ID: existing.ID, MType: existing.MType}] = existing
+
+			repo := NewMetricMemoryDumpRepository(storage, wal)
+			require.NoError(t, repo.Load(context.Background(), []types.Metrics{loaded}, tt.replace))
+
+			assert.Equal(t, tt.want, storage.Data)
+
+			// Replaying the write-ahead log on top of the old state must give the same result.
+			replayed := map[types.MetricID]types.Metrics{
+				{ID: existing.ID, MType: existing.MType}: existing,
+			}
+			require.NoError(t, wal.Replay(0, func(record []byte) error {
+				return applyMetricWALRecord(replayed, record)
+			}))
+			assert.Equal(t, tt.want, replayed)
+		})
+	}
+}
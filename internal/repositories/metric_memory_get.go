@@ -8,11 +8,11 @@ import (
 )
 
 type MetricMemoryGetRepository struct {
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics]
+	storage engines.Storage[types.MetricID, types.Metrics]
 }
 
 func NewMetricMemoryGetRepository(
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics],
+	storage engines.Storage[types.MetricID, types.Metrics],
 ) *MetricMemoryGetRepository {
 	return &MetricMemoryGetRepository{
 		storage: storage,
@@ -23,10 +23,7 @@ func (repo *MetricMemoryGetRepository) Get(
 	ctx context.Context,
 	id types.MetricID,
 ) (*types.Metrics, error) {
-	repo.storage.Mu.RLock()
-	defer repo.storage.Mu.RUnlock()
-
-	metric, ok := repo.storage.Data[id]
+	metric, ok := repo.storage.Get(id)
 	if !ok {
 		return nil, nil
 	}
@@ -9,11 +9,11 @@ import (
 )
 
 type MetricMemoryListRepository struct {
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics]
+	storage engines.Storage[types.MetricID, types.Metrics]
 }
 
 func NewMetricMemoryListRepository(
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics],
+	storage engines.Storage[types.MetricID, types.Metrics],
 ) *MetricMemoryListRepository {
 	return &MetricMemoryListRepository{
 		storage: storage,
@@ -23,16 +23,21 @@ func NewMetricMemoryListRepository(
 func (repo *MetricMemoryListRepository) List(
 	ctx context.Context,
 ) ([]types.Metrics, error) {
-	repo.storage.Mu.RLock()
-	defer repo.storage.Mu.RUnlock()
+	data, err := repo.storage.Snapshot(nil)
+	if err != nil {
+		return nil, err
+	}
 
-	metrics := make([]types.Metrics, 0, len(repo.storage.Data))
-	for _, metric := range repo.storage.Data {
+	metrics := make([]types.Metrics, 0, len(data))
+	for _, metric := range data {
 		metrics = append(metrics, metric)
 	}
 
 	sort.Slice(metrics, func(i, j int) bool {
-		return metrics[i].ID < metrics[j].ID
+		if metrics[i].ID != metrics[j].ID {
+			return metrics[i].ID < metrics[j].ID
+		}
+		return metrics[i].MType < metrics[j].MType
 	})
 
 	return metrics, nil
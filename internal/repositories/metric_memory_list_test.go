@@ -62,3 +62,16 @@ func TestMetricMemoryListRepository_List(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricMemoryListRepository_List_SameIDOrderedByType(t *testing.T) {
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	storage.Data[types.MetricID{ID: "Alloc", MType: types.Gauge}] = types.Metrics{ID: "Alloc", MType: types.Gauge}
+	storage.Data[types.MetricID{ID: "Alloc", MType: types.Counter}] = types.Metrics{ID: "Alloc", MType: types.Counter}
+
+	result, err := NewMetricMemoryListRepository(storage).List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Metrics{
+		{ID: "Alloc", MType: types.Counter},
+		{ID: "Alloc", MType: types.Gauge},
+	}, result)
+}
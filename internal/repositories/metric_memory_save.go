@@ -8,11 +8,11 @@ import (
 )
 
 type MetricMemorySaveRepository struct {
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics]
+	storage engines.Storage[types.MetricID, types.Metrics]
 }
 
 func NewMetricMemorySaveRepository(
-	storage *engines.MemoryStorage[types.MetricID, types.Metrics],
+	storage engines.Storage[types.MetricID, types.Metrics],
 ) *MetricMemorySaveRepository {
 	return &MetricMemorySaveRepository{
 		storage: storage,
@@ -23,13 +23,8 @@ func (repo *MetricMemorySaveRepository) Save(
 	ctx context.Context,
 	metrics types.Metrics,
 ) error {
-	repo.storage.Mu.Lock()
-	defer repo.storage.Mu.Unlock()
-
-	repo.storage.Data[types.MetricID{
+	return repo.storage.Set(types.MetricID{
 		ID:    metrics.ID,
 		MType: metrics.MType,
-	}] = metrics
-
-	return nil
+	}, metrics, nil)
 }
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const (
+	metricWALOpSet  = "set"
+	metricWALOpLoad = "load"
+)
+
+type metricWALRecord struct {
+	Op      string          `json:"op"`
+	Metric  *types.Metrics  `json:"metric,omitempty"`
+	Metrics []types.Metrics `json:"metrics,omitempty"`
+	Replace bool            `json:"replace,omitempty"`
+}
+
+type MetricMemoryWALSaveRepository struct {
+	storage engines.Storage[types.MetricID, types.Metrics]
+	wal     *engines.WAL
+}
+
+func NewMetricMemoryWALSaveRepository(
+	storage engines.Storage[types.MetricID, types.Metrics],
+	wal *engines.WAL,
+) *MetricMemoryWALSaveRepository {
+	return &MetricMemoryWALSaveRepository{
+		storage: storage,
+		wal:     wal,
+	}
+}
+
+func (repo *MetricMemoryWALSaveRepository) Save(
+	ctx context.Context,
+	metrics types.Metrics,
+) error {
+	record, err := json.Marshal(metricWALRecord{Op: metricWALOpSet, Metric: &metrics})
+	if err != nil {
+		return err
+	}
+
+	return repo.storage.Set(types.MetricID{
+		ID:    metrics.ID,
+		MType: metrics.MType,
+	}, metrics, func() error {
+		return repo.wal.Append(record)
+	})
+}
+
+func applyMetricWALRecord(data map[types.MetricID]types.Metrics, record []byte) error {
+	var r metricWALRecord
+	if err := json.Unmarshal(record, &r); err != nil {
+		return err
+	}
+
+	switch r.Op {
+	case metricWALOpSet:
+		if r.Metric != nil {
+			data[types.MetricID{ID: r.Metric.ID, MType: r.Metric.MType}] = *r.Metric
+		}
+	case metricWALOpLoad:
+		loadMetrics(data, r.Metrics, r.Replace)
+	}
+
+	return nil
+}
+
+func loadMetrics(data map[types.MetricID]types.Metrics, metrics []types.Metrics, replace bool) {
+	if replace {
+		clear(data)
+	}
+
+	for _, metric := range metrics {
+		data[types.MetricID{ID: metric.ID, MType: metric.MType}] = metric
+	}
+}
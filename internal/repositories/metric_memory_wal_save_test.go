@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMemoryWALSaveRepository_Save(t *testing.T) {
+	storage := engines.NewMemoryStorage[types.MetricID, types.Metrics]()
+	wal, err := engines.NewWAL(t.TempDir(), engines.FsyncAlways)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	repo := NewMetricMemoryWALSaveRepository(storage, wal)
+
+	value := 42.5
+	metric := types.Metrics{ID: "metric1", MType: types.Gauge, Value: &value}
+
+	err = repo.Save(context.Background(), metric)
+	require.NoError(t, err)
+
+	storage.Mu.RLock()
+	saved, ok := storage.Data[types.MetricID{ID: metric.ID, MType: metric.MType}]
+	storage.Mu.RUnlock()
+
+	assert.True(t, ok, "metric should be saved")
+	assert.Equal(t, metric, saved)
+
+	replayed := make(map[types.MetricID]types.Metrics)
+	err = wal.Replay(0, func(record []byte) error {
+		return applyMetricWALRecord(replayed, record)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, storage.Data, replayed)
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/engines"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The repositories must behave the same on top of the sharded engine.
+func TestMetricRepositories_ShardedMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	storage := engines.NewShardedMemoryStorage[types.MetricID, types.Metrics](4)
+	wal, err := engines.NewWAL(path+".wal", engines.FsyncAlways)
+	require.NoError(t, err)
+	defer wal.Close()
+
+	saver := NewMetricMemoryWALSaveRepository(storage, wal)
+	getter := NewMetricMemoryGetRepository(storage)
+	lister := NewMetricMemoryListRepository(storage)
+	dumper := NewMetricMemoryDumpRepository(storage, wal)
+	snapshots := NewMetricFileSnapshotRepository(storage, wal, path)
+
+	for _, id := range []string{"metricC", "metricA", "metricB"} {
+		require.NoError(t, saver.Save(ctx, types.Metrics{ID: id, MType: types.Gauge}))
+	}
+
+	metric, err := getter.Get(ctx, types.MetricID{ID: "metricA", MType: types.Gauge})
+	require.NoError(t, err)
+	require.NotNil(t, metric)
+	assert.Equal(t, "metricA", metric.ID)
+
+	listed, err := lister.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+	assert.Equal(t, []string{"metricA", "metricB", "metricC"}, []string{listed[0].ID, listed[1].ID, listed[2].ID})
+
+	require.NoError(t, snapshots.Snapshot(ctx))
+	require.NoError(t, dumper.Load(ctx, []types.Metrics{{ID: "metricD", MType: types.Counter}}, true))
+
+	restored := engines.NewShardedMemoryStorage[types.MetricID, types.Metrics](2)
+	require.NoError(t, NewMetricFileSnapshotRepository(restored, wal, path).Restore(ctx))
+
+	dumped, err := NewMetricMemoryDumpRepository(restored, nil).Dump(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Metrics{{ID: "metricD", MType: types.Counter}}, dumped)
+}
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewAdminRouter(
+	snapshotHandler http.HandlerFunc,
+	restoreHandler http.HandlerFunc,
+	middlewares ...func(http.Handler) http.Handler,
+) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	r.Get("/snapshot", snapshotHandler)
+	r.Post("/restore", restoreHandler)
+
+	return r
+}
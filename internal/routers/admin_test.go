@@ -0,0 +1,53 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAdminRouter(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		expectStatus int
+		expectBody   string
+	}{
+		{"GET /snapshot", http.MethodGet, "/snapshot", http.StatusOK, "snapshot-ok"},
+		{"POST /restore", http.MethodPost, "/restore", http.StatusOK, "restore-ok"},
+		{"POST /snapshot not allowed", http.MethodPost, "/snapshot", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var middlewareCalled bool
+
+			middleware := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					middlewareCalled = true
+					next.ServeHTTP(w, r)
+				})
+			}
+
+			snapshotHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("snapshot-ok"))
+			}
+			restoreHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("restore-ok"))
+			}
+
+			router := NewAdminRouter(snapshotHandler, restoreHandler, middleware)
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectStatus, rec.Code)
+			assert.True(t, middlewareCalled)
+			assert.Equal(t, tt.expectBody, rec.Body.String())
+		})
+	}
+}
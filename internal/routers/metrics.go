@@ -10,6 +10,14 @@ func NewMetricsRouter(
 	metricUpdatePathHandler http.HandlerFunc,
 	metricValuePathHandler http.HandlerFunc,
 	metricsListHandler http.HandlerFunc, // ← Новый параметр
+	metricsQueryHandler http.HandlerFunc,
+	metricsStreamHandler http.HandlerFunc,
+	metricsExportHandler http.HandlerFunc,
+	metricsImportHandler http.HandlerFunc,
+	metricsInfluxWriteHandler http.HandlerFunc,
+	metricsOTLPHandler http.HandlerFunc,
+	metricUpdateBatchHandler http.HandlerFunc,
+	staticHandler http.Handler,
 	middlewares ...func(http.Handler) http.Handler,
 ) *chi.Mux {
 	r := chi.NewRouter()
@@ -17,11 +25,20 @@ func NewMetricsRouter(
 
 	r.Post("/update/{type}/{name}/{value}", metricUpdatePathHandler)
 	r.Post("/update/{type}/{name}", metricUpdatePathHandler)
+	r.Post("/updates/", metricUpdateBatchHandler)
 
 	r.Get("/value/{type}/{name}", metricValuePathHandler)
 	r.Get("/value/{type}", metricValuePathHandler)
 
+	r.Get("/query", metricsQueryHandler)
+	r.Get("/stream", metricsStreamHandler)
+	r.Get("/export", metricsExportHandler)
+	r.Post("/import", metricsImportHandler)
+	r.Post("/write", metricsInfluxWriteHandler)
+	r.Post("/v1/metrics", metricsOTLPHandler)
+
 	r.Get("/", metricsListHandler)
+	r.Handle("/static/*", staticHandler)
 
 	return r
 }
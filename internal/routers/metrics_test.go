@@ -19,6 +19,14 @@ func TestNewMetricsRouter_HandlersAndMiddleware(t *testing.T) {
 		expectUpdateHandler bool
 		expectValueHandler  bool
 		expectListHandler   bool
+		expectQueryHandler  bool
+		expectStreamHandler bool
+		expectStaticHandler bool
+		expectExportHandler bool
+		expectImportHandler bool
+		expectInfluxHandler bool
+		expectOTLPHandler   bool
+		expectBatchHandler  bool
 	}{
 		{
 			name:                "POST /update route",
@@ -44,12 +52,76 @@ func TestNewMetricsRouter_HandlersAndMiddleware(t *testing.T) {
 			expectMiddleware:  true,
 			expectListHandler: true,
 		},
+		{
+			name:               "GET /query route",
+			method:             "GET",
+			url:                "/query?match=Heap*&agg=sum",
+			expectStatus:       http.StatusOK,
+			expectMiddleware:   true,
+			expectQueryHandler: true,
+		},
+		{
+			name:                "GET /stream route",
+			method:              "GET",
+			url:                 "/stream?type=gauge",
+			expectStatus:        http.StatusOK,
+			expectMiddleware:    true,
+			expectStreamHandler: true,
+		},
+		{
+			name:                "GET /static route",
+			method:              "GET",
+			url:                 "/static/dashboard.js",
+			expectStatus:        http.StatusOK,
+			expectMiddleware:    true,
+			expectStaticHandler: true,
+		},
+		{
+			name:                "GET /export route",
+			method:              "GET",
+			url:                 "/export?format=csv",
+			expectStatus:        http.StatusOK,
+			expectMiddleware:    true,
+			expectExportHandler: true,
+		},
+		{
+			name:                "POST /import route",
+			method:              "POST",
+			url:                 "/import?mode=merge",
+			expectStatus:        http.StatusOK,
+			expectMiddleware:    true,
+			expectImportHandler: true,
+		},
+		{
+			name:                "POST /write route",
+			method:              "POST",
+			url:                 "/write?precision=s",
+			expectStatus:        http.StatusOK,
+			expectMiddleware:    true,
+			expectInfluxHandler: true,
+		},
+		{
+			name:              "POST /v1/metrics route",
+			method:            "POST",
+			url:               "/v1/metrics",
+			expectStatus:      http.StatusOK,
+			expectMiddleware:  true,
+			expectOTLPHandler: true,
+		},
+		{
+			name:               "POST /updates/ route",
+			method:             "POST",
+			url:                "/updates/",
+			expectStatus:       http.StatusOK,
+			expectMiddleware:   true,
+			expectBatchHandler: true,
+		},
 	}
 
 	for _, tt := range tests {
 		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
-			var middlewareCalled, updateHandlerCalled, valueHandlerCalled, listHandlerCalled bool
+			var middlewareCalled, updateHandlerCalled, valueHandlerCalled, listHandlerCalled, queryHandlerCalled, streamHandlerCalled, staticHandlerCalled, exportHandlerCalled, importHandlerCalled, influxHandlerCalled, otlpHandlerCalled, batchHandlerCalled bool
 
 			middleware := func(next http.Handler) http.Handler {
 				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -76,7 +148,55 @@ func TestNewMetricsRouter_HandlersAndMiddleware(t *testing.T) {
 				w.Write([]byte("list-ok"))
 			}
 
-			router := NewMetricsRouter(updateHandler, valueHandler, listHandler, middleware)
+			queryHandler := func(w http.ResponseWriter, r *http.Request) {
+				queryHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("query-ok"))
+			}
+
+			streamHandler := func(w http.ResponseWriter, r *http.Request) {
+				streamHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("stream-ok"))
+			}
+
+			exportHandler := func(w http.ResponseWriter, r *http.Request) {
+				exportHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("export-ok"))
+			}
+
+			importHandler := func(w http.ResponseWriter, r *http.Request) {
+				importHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("import-ok"))
+			}
+
+			influxHandler := func(w http.ResponseWriter, r *http.Request) {
+				influxHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("write-ok"))
+			}
+
+			otlpHandler := func(w http.ResponseWriter, r *http.Request) {
+				otlpHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("otlp-ok"))
+			}
+
+			batchHandler := func(w http.ResponseWriter, r *http.Request) {
+				batchHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("updates-ok"))
+			}
+
+			staticHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				staticHandlerCalled = true
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("static-ok"))
+			})
+
+			router := NewMetricsRouter(updateHandler, valueHandler, listHandler, queryHandler, streamHandler, exportHandler, importHandler, influxHandler, otlpHandler, batchHandler, staticHandler, middleware)
 
 			req := httptest.NewRequest(tt.method, tt.url, nil)
 			w := httptest.NewRecorder()
@@ -90,6 +210,14 @@ func TestNewMetricsRouter_HandlersAndMiddleware(t *testing.T) {
 			assert.Equal(t, tt.expectUpdateHandler, updateHandlerCalled, "updateHandler called")
 			assert.Equal(t, tt.expectValueHandler, valueHandlerCalled, "valueHandler called")
 			assert.Equal(t, tt.expectListHandler, listHandlerCalled, "listHandler called")
+			assert.Equal(t, tt.expectQueryHandler, queryHandlerCalled, "queryHandler called")
+			assert.Equal(t, tt.expectStreamHandler, streamHandlerCalled, "streamHandler called")
+			assert.Equal(t, tt.expectStaticHandler, staticHandlerCalled, "staticHandler called")
+			assert.Equal(t, tt.expectExportHandler, exportHandlerCalled, "exportHandler called")
+			assert.Equal(t, tt.expectImportHandler, importHandlerCalled, "importHandler called")
+			assert.Equal(t, tt.expectInfluxHandler, influxHandlerCalled, "influxHandler called")
+			assert.Equal(t, tt.expectOTLPHandler, otlpHandlerCalled, "otlpHandler called")
+			assert.Equal(t, tt.expectBatchHandler, batchHandlerCalled, "batchHandler called")
 		})
 	}
 }
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewSidecarRouter serves the agent sidecar listener, which accepts the
+// same update requests as the server.
+func NewSidecarRouter(
+	metricUpdatePathHandler http.HandlerFunc,
+	metricUpdateBatchHandler http.HandlerFunc,
+	middlewares ...func(http.Handler) http.Handler,
+) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	r.Post("/update/{type}/{name}/{value}", metricUpdatePathHandler)
+	r.Post("/update/{type}/{name}", metricUpdatePathHandler)
+	r.Post("/updates/", metricUpdateBatchHandler)
+
+	return r
+}
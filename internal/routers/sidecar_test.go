@@ -0,0 +1,54 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSidecarRouter(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		expectStatus int
+		expectBody   string
+	}{
+		{"POST /update path", http.MethodPost, "/update/counter/Requests/1", http.StatusOK, "update-ok"},
+		{"POST /update without value", http.MethodPost, "/update/counter/Requests", http.StatusOK, "update-ok"},
+		{"POST /updates/", http.MethodPost, "/updates/", http.StatusOK, "batch-ok"},
+		{"GET /value not served", http.MethodGet, "/value/counter/Requests", http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var middlewareCalled bool
+
+			middleware := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					middlewareCalled = true
+					next.ServeHTTP(w, r)
+				})
+			}
+
+			updateHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("update-ok"))
+			}
+			batchHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("batch-ok"))
+			}
+
+			router := NewSidecarRouter(updateHandler, batchHandler, middleware)
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectStatus, rec.Code)
+			assert.True(t, middlewareCalled)
+			assert.Equal(t, tt.expectBody, rec.Body.String())
+		})
+	}
+}
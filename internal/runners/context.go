@@ -2,8 +2,11 @@ package runners
 
 import (
 	"context"
+	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 )
 
 func NewRunContext(ctx context.Context) (context.Context, context.CancelFunc) {
@@ -15,3 +18,57 @@ func NewRunContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	)
 	return ctx, stop
 }
+
+// NotifyReload delivers a value for every SIGHUP until ctx is done. Signals
+// arriving while a reload is still pending are coalesced into it.
+func NotifyReload(ctx context.Context) <-chan struct{} {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	reloadCh := make(chan struct{}, 1)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				select {
+				case reloadCh <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return reloadCh
+}
+
+// WatchReloads calls load for every value from signals and delivers what it
+// returns. A configuration that fails to load is logged and skipped.
+func WatchReloads[T any](ctx context.Context, signals <-chan struct{}, load func() (T, error)) <-chan T {
+	reloads := make(chan T)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				config, err := load()
+				if err != nil {
+					logger.Log.Errorw("Failed to reload configuration", "error", err)
+					continue
+				}
+				select {
+				case reloads <- config:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return reloads
+}
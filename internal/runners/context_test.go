@@ -2,10 +2,14 @@ package runners
 
 import (
 	"context"
+	"errors"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewRunContext(t *testing.T) {
@@ -34,3 +38,54 @@ func TestNewRunContext(t *testing.T) {
 		t.Fatal("context was not cancelled after cancel function called")
 	}
 }
+
+func TestNotifyReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloadCh := NotifyReload(ctx)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloadCh:
+	case <-time.After(time.Second):
+		t.Fatal("reload was not delivered after SIGHUP")
+	}
+}
+
+type testConfig struct{ interval int }
+
+func TestWatchReloads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan struct{})
+	results := []struct {
+		cfg *testConfig
+		err error
+	}{
+		{err: errors.New("bad config")},
+		{cfg: &testConfig{interval: 3}},
+	}
+	calls := 0
+	parseFunc := func() (*testConfig, error) {
+		r := results[calls]
+		calls++
+		return r.cfg, r.err
+	}
+
+	reloads := WatchReloads(ctx, signals, parseFunc)
+
+	signals <- struct{}{}
+	signals <- struct{}{}
+
+	select {
+	case cfg := <-reloads:
+		require.NotNil(t, cfg)
+		assert.Equal(t, 3, cfg.interval)
+	case <-time.After(time.Second):
+		t.Fatal("reloaded config was not delivered")
+	}
+	assert.Equal(t, 2, calls, "a config that fails to load is skipped")
+}
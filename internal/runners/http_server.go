@@ -2,6 +2,8 @@ package runners
 
 import (
 	"context"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
@@ -14,8 +16,28 @@ type Server interface {
 
 const defaultShutdownTimeout = 5 * time.Second
 
-func RunServer(ctx context.Context, srv Server) error {
+// NewTLSServer adapts srv so that ListenAndServe serves HTTPS with the
+// certificate and key in the given PEM files.
+func NewTLSServer(srv *http.Server, certFile, keyFile string) Server {
+	return &tlsServer{Server: srv, certFile: certFile, keyFile: keyFile}
+}
+
+type tlsServer struct {
+	*http.Server
+	certFile string
+	keyFile  string
+}
+
+func (s *tlsServer) ListenAndServe() error {
+	return s.ListenAndServeTLS(s.certFile, s.keyFile)
+}
+
+// RunServer serves srv until ctx is cancelled, running the optional
+// background workers alongside it. Workers are stopped only after the
+// server has shut down, so they observe every accepted request.
+func RunServer(ctx context.Context, srv Server, workers ...func(ctx context.Context) error) error {
 	errCh := make(chan error, 1)
+	workerErrCh := make(chan error, len(workers))
 
 	logger.Log.Infow("Starting server")
 
@@ -23,25 +45,71 @@ func RunServer(ctx context.Context, srv Server) error {
 		errCh <- srv.ListenAndServe()
 	}()
 
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := worker(workersCtx); err != nil {
+				workerErrCh <- err
+			}
+		}()
+	}
+
+	stopWorkers := func() {
+		cancelWorkers()
+		wg.Wait()
+	}
+
 	select {
 	case <-ctx.Done():
 		logger.Log.Infow("Context cancelled, initiating shutdown")
 
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
-		defer cancel()
-
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			logger.Log.Errorw("Server shutdown error", "error", err)
+		err := shutdownServer(srv)
+		stopWorkers()
+		if err != nil {
 			return err
 		}
+		return firstWorkerError(workerErrCh)
 
-		logger.Log.Infow("Server shutdown complete")
-		return nil
+	case err := <-workerErrCh:
+		logger.Log.Errorw("Worker stopped with error", "error", err)
+
+		shutdownServer(srv)
+		stopWorkers()
+		return err
 
 	case err := <-errCh:
 		if err != nil {
 			logger.Log.Errorw("Server stopped with error", "error", err)
 		}
+		stopWorkers()
+		return err
+	}
+}
+
+func shutdownServer(srv Server) error {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Log.Errorw("Server shutdown error", "error", err)
 		return err
 	}
+
+	logger.Log.Infow("Server shutdown complete")
+	return nil
+}
+
+func firstWorkerError(workerErrCh <-chan error) error {
+	select {
+	case err := <-workerErrCh:
+		logger.Log.Errorw("Worker stopped with error", "error", err)
+		return err
+	default:
+		return nil
+	}
 }
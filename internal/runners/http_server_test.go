@@ -2,12 +2,20 @@ package runners
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/testutils"
 )
 
 func TestRunServer(t *testing.T) {
@@ -103,3 +111,134 @@ func TestRunServer(t *testing.T) {
 		})
 	}
 }
+
+func TestRunServer_WithWorkers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("workers are stopped after server shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockSrv := NewMockServer(ctrl)
+
+		var shutdownDone, workerDone bool
+
+		mockSrv.EXPECT().ListenAndServe().DoAndReturn(func() error {
+			<-ctx.Done()
+			return nil
+		}).Times(1)
+		mockSrv.EXPECT().Shutdown(gomock.Any()).DoAndReturn(func(context.Context) error {
+			shutdownDone = true
+			return nil
+		}).Times(1)
+
+		worker := func(ctx context.Context) error {
+			<-ctx.Done()
+			require.True(t, shutdownDone, "worker must be stopped after server shutdown")
+			workerDone = true
+			return nil
+		}
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := RunServer(ctx, mockSrv, worker)
+		require.NoError(t, err)
+		require.True(t, workerDone)
+	})
+
+	t.Run("worker error on shutdown is returned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockSrv := NewMockServer(ctrl)
+
+		mockSrv.EXPECT().ListenAndServe().DoAndReturn(func() error {
+			<-ctx.Done()
+			return nil
+		}).Times(1)
+		mockSrv.EXPECT().Shutdown(gomock.Any()).Return(nil).Times(1)
+
+		worker := func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("final snapshot failed")
+		}
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := RunServer(ctx, mockSrv, worker)
+		require.EqualError(t, err, "final snapshot failed")
+	})
+
+	t.Run("worker error shuts the server down", func(t *testing.T) {
+		started := make(chan struct{})
+		stopped := make(chan struct{})
+		mockSrv := NewMockServer(ctrl)
+
+		mockSrv.EXPECT().ListenAndServe().DoAndReturn(func() error {
+			close(started)
+			<-stopped
+			return nil
+		}).Times(1)
+		mockSrv.EXPECT().Shutdown(gomock.Any()).DoAndReturn(func(context.Context) error {
+			close(stopped)
+			return nil
+		}).Times(1)
+
+		worker := func(ctx context.Context) error {
+			<-started
+			return errors.New("worker error")
+		}
+
+		err := RunServer(context.Background(), mockSrv, worker)
+		require.EqualError(t, err, "worker error")
+	})
+}
+
+func TestNewTLSServer(t *testing.T) {
+	certFile, keyFile := testutils.SelfSignedCert(t, t.TempDir())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	srv := NewTLSServer(&http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("secure"))
+		}),
+	}, certFile, keyFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- RunServer(ctx, srv) }()
+
+	caPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	var body []byte
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("https://" + addr)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, _ = io.ReadAll(resp.Body)
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+	require.Equal(t, "secure", string(body))
+
+	resp, err := http.Get("http://" + addr)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "plain HTTP is refused")
+
+	cancel()
+	require.NoError(t, <-done)
+}
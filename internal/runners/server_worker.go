@@ -0,0 +1,31 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// NewServerWorker adapts an additional Server, such as a protocol listener,
+// into a worker for RunServer. The server is shut down gracefully when the
+// worker context is cancelled.
+func NewServerWorker(srv Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		errCh := make(chan error, 1)
+
+		go func() {
+			errCh <- srv.ListenAndServe()
+		}()
+
+		select {
+		case <-ctx.Done():
+			shutdownErr := shutdownServer(srv)
+			if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return shutdownErr
+		case err := <-errCh:
+			return err
+		}
+	}
+}
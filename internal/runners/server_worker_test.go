@@ -0,0 +1,65 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServerWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("listen error is returned", func(t *testing.T) {
+		mockSrv := NewMockServer(ctrl)
+		mockSrv.EXPECT().ListenAndServe().Return(errors.New("listen error"))
+
+		err := NewServerWorker(mockSrv)(context.Background())
+		require.EqualError(t, err, "listen error")
+	})
+
+	tests := []struct {
+		name        string
+		listenErr   error
+		shutdownErr error
+		wantErr     string
+	}{
+		{name: "cancel shuts the server down", shutdownErr: nil},
+		{name: "server closed is not an error", listenErr: http.ErrServerClosed},
+		{name: "shutdown error is returned", shutdownErr: errors.New("shutdown error"), wantErr: "shutdown error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stopped := make(chan struct{})
+
+			mockSrv := NewMockServer(ctrl)
+			mockSrv.EXPECT().ListenAndServe().DoAndReturn(func() error {
+				<-stopped
+				return tt.listenErr
+			})
+			mockSrv.EXPECT().Shutdown(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+				close(stopped)
+				return tt.shutdownErr
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+			}()
+
+			err := NewServerWorker(mockSrv)(ctx)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package runners
 
 import (
 	"context"
+	"sync"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 )
@@ -26,3 +27,35 @@ func RunWorker(ctx context.Context, worker func(ctx context.Context) error) erro
 		return err
 	}
 }
+
+// NewWorkerGroup runs workers concurrently as a single worker. The first
+// worker to fail cancels the others; the group returns once all of them
+// have stopped, with that first error.
+func NewWorkerGroup(workers ...func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var (
+			wg       sync.WaitGroup
+			once     sync.Once
+			firstErr error
+		)
+
+		for _, worker := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := worker(ctx); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}()
+		}
+
+		wg.Wait()
+		return firstErr
+	}
+}
@@ -3,6 +3,7 @@ package runners
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -68,3 +69,36 @@ func TestRunWorker(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWorkerGroup(t *testing.T) {
+	t.Run("runs workers until the context is cancelled", func(t *testing.T) {
+		var stopped atomic.Int32
+		worker := func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped.Add(1)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, NewWorkerGroup(worker, worker)(ctx))
+		require.Equal(t, int32(2), stopped.Load())
+	})
+
+	t.Run("first error stops the other workers", func(t *testing.T) {
+		var cancelled atomic.Bool
+		failing := func(ctx context.Context) error {
+			return errors.New("worker error")
+		}
+		blocking := func(ctx context.Context) error {
+			<-ctx.Done()
+			cancelled.Store(true)
+			return nil
+		}
+
+		err := NewWorkerGroup(blocking, failing)(context.Background())
+		require.EqualError(t, err, "worker error")
+		require.True(t, cancelled.Load())
+	})
+}
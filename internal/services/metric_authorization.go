@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricAuthorizedUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+type MetricAuthorizedImporter interface {
+	Import(ctx context.Context, metrics []types.Metrics, mode string) error
+}
+
+// MetricAuthorizationService guards the HTTP write paths: a request is
+// applied only if its client identity may write every metric in it, that
+// is, each name starts with one of the identity's prefixes. Requests
+// without an identity are rejected.
+type MetricAuthorizationService struct {
+	updater  MetricAuthorizedUpdater
+	importer MetricAuthorizedImporter
+	prefixes map[string][]string
+}
+
+func NewMetricAuthorizationService(
+	updater MetricAuthorizedUpdater,
+	importer MetricAuthorizedImporter,
+	prefixes map[string][]string,
+) *MetricAuthorizationService {
+	return &MetricAuthorizationService{updater: updater, importer: importer, prefixes: prefixes}
+}
+
+func (svc *MetricAuthorizationService) Update(
+	ctx context.Context,
+	metrics []types.Metrics,
+) error {
+	if err := svc.authorize(ctx, metrics); err != nil {
+		return err
+	}
+	return svc.updater.Update(ctx, metrics)
+}
+
+func (svc *MetricAuthorizationService) Import(
+	ctx context.Context,
+	metrics []types.Metrics,
+	mode string,
+) error {
+	if err := svc.authorize(ctx, metrics); err != nil {
+		return err
+	}
+	return svc.importer.Import(ctx, metrics, mode)
+}
+
+func (svc *MetricAuthorizationService) authorize(ctx context.Context, metrics []types.Metrics) error {
+	identity, _ := types.ClientIdentityFromContext(ctx)
+	prefixes := svc.prefixes[identity]
+
+	for _, m := range metrics {
+		if !hasAnyPrefix(m.ID, prefixes) {
+			logger.Log.Warnw("Rejected metric write",
+				"identity", identity,
+				"id", m.ID,
+			)
+			return errors.ErrMetricForbidden
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_authorization.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricAuthorizedUpdater is a mock of MetricAuthorizedUpdater interface.
+type MockMetricAuthorizedUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricAuthorizedUpdaterMockRecorder
+}
+
+// MockMetricAuthorizedUpdaterMockRecorder is the mock recorder for MockMetricAuthorizedUpdater.
+type MockMetricAuthorizedUpdaterMockRecorder struct {
+	mock *MockMetricAuthorizedUpdater
+}
+
+// NewMockMetricAuthorizedUpdater creates a new mock instance.
+func NewMockMetricAuthorizedUpdater(ctrl *gomock.Controller) *MockMetricAuthorizedUpdater {
+	mock := &MockMetricAuthorizedUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricAuthorizedUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricAuthorizedUpdater) EXPECT() *MockMetricAuthorizedUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricAuthorizedUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricAuthorizedUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricAuthorizedUpdater)(nil).Update), ctx, metrics)
+}
+
+// MockMetricAuthorizedImporter is a mock of MetricAuthorizedImporter interface.
+type MockMetricAuthorizedImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricAuthorizedImporterMockRecorder
+}
+
+// MockMetricAuthorizedImporterMockRecorder is the mock recorder for MockMetricAuthorizedImporter.
+type MockMetricAuthorizedImporterMockRecorder struct {
+	mock *MockMetricAuthorizedImporter
+}
+
+// NewMockMetricAuthorizedImporter creates a new mock instance.
+func NewMockMetricAuthorizedImporter(ctrl *gomock.Controller) *MockMetricAuthorizedImporter {
+	mock := &MockMetricAuthorizedImporter{ctrl: ctrl}
+	mock.recorder = &MockMetricAuthorizedImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricAuthorizedImporter) EXPECT() *MockMetricAuthorizedImporterMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockMetricAuthorizedImporter) Import(ctx context.Context, metrics []types.Metrics, mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, metrics, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockMetricAuthorizedImporterMockRecorder) Import(ctx, metrics, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockMetricAuthorizedImporter)(nil).Import), ctx, metrics, mode)
+}
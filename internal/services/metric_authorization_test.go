@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricAuthorizationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockMetricAuthorizedUpdater(ctrl)
+	mockImporter := NewMockMetricAuthorizedImporter(ctrl)
+	svc := NewMetricAuthorizationService(mockUpdater, mockImporter, map[string][]string{
+		"agent-a": {"app.", "db."},
+		"admin":   {""},
+	})
+
+	agentCtx := types.WithClientIdentity(context.Background(), "agent-a")
+	adminCtx := types.WithClientIdentity(context.Background(), "admin")
+	unknownCtx := types.WithClientIdentity(context.Background(), "agent-b")
+
+	allowed := []types.Metrics{{ID: "app.hits", MType: types.Counter}, {ID: "db.conns", MType: types.Gauge}}
+	mixed := []types.Metrics{{ID: "app.hits", MType: types.Counter}, {ID: "cpu", MType: types.Gauge}}
+
+	t.Run("names under the identity prefixes are written", func(t *testing.T) {
+		mockUpdater.EXPECT().Update(agentCtx, allowed).Return(nil)
+		mockImporter.EXPECT().Import(agentCtx, allowed, types.ImportModeMerge).Return(nil)
+
+		assert.NoError(t, svc.Update(agentCtx, allowed))
+		assert.NoError(t, svc.Import(agentCtx, allowed, types.ImportModeMerge))
+	})
+
+	t.Run("empty prefix allows every name", func(t *testing.T) {
+		mockUpdater.EXPECT().Update(adminCtx, mixed).Return(nil)
+
+		assert.NoError(t, svc.Update(adminCtx, mixed))
+	})
+
+	t.Run("one disallowed name rejects the whole request", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Update(agentCtx, mixed), errors.ErrMetricForbidden)
+		assert.ErrorIs(t, svc.Import(agentCtx, mixed, types.ImportModeReplace), errors.ErrMetricForbidden)
+	})
+
+	t.Run("unknown or missing identity is rejected", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Update(unknownCtx, allowed), errors.ErrMetricForbidden)
+		assert.ErrorIs(t, svc.Update(context.Background(), allowed), errors.ErrMetricForbidden)
+	})
+}
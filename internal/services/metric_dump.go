@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricDumpDumper interface {
+	Dump(ctx context.Context) ([]types.Metrics, error)
+}
+
+type MetricDumpService struct {
+	dumper MetricDumpDumper
+}
+
+func NewMetricDumpService(
+	dumper MetricDumpDumper,
+) *MetricDumpService {
+	return &MetricDumpService{dumper: dumper}
+}
+
+func (svc *MetricDumpService) Dump(
+	ctx context.Context,
+) ([]types.Metrics, error) {
+	metrics, err := svc.dumper.Dump(ctx)
+	if err != nil {
+		logger.Log.Errorw("Failed to dump metrics", "error", err)
+		return nil, err
+	}
+
+	return metrics, nil
+}
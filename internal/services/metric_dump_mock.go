@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_dump.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricDumpDumper is a mock of MetricDumpDumper interface.
+type MockMetricDumpDumper struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricDumpDumperMockRecorder
+}
+
+// MockMetricDumpDumperMockRecorder is the mock recorder for MockMetricDumpDumper.
+type MockMetricDumpDumperMockRecorder struct {
+	mock *MockMetricDumpDumper
+}
+
+// NewMockMetricDumpDumper creates a new mock instance.
+func NewMockMetricDumpDumper(ctrl *gomock.Controller) *MockMetricDumpDumper {
+	mock := &MockMetricDumpDumper{ctrl: ctrl}
+	mock.recorder = &MockMetricDumpDumperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricDumpDumper) EXPECT() *MockMetricDumpDumperMockRecorder {
+	return m.recorder
+}
+
+// Dump mocks base method.
+func (m *MockMetricDumpDumper) Dump(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dump", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dump indicates an expected call of Dump.
+func (mr *MockMetricDumpDumperMockRecorder) Dump(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dump", reflect.TypeOf((*MockMetricDumpDumper)(nil).Dump), ctx)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricDumpService_Dump(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDumper := NewMockMetricDumpDumper(ctrl)
+	svc := NewMetricDumpService(mockDumper)
+
+	ctx := context.Background()
+
+	t.Run("success returns metrics", func(t *testing.T) {
+		expected := []types.Metrics{{ID: "m1", MType: types.Gauge}}
+		mockDumper.EXPECT().Dump(ctx).Return(expected, nil)
+
+		got, err := svc.Dump(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("dumper returns error", func(t *testing.T) {
+		mockDumper.EXPECT().Dump(ctx).Return(nil, errors.New("some error"))
+
+		got, err := svc.Dump(ctx)
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricForwardSender interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// MetricForwardService relays accepted updates to upstream servers. Updates
+// are coalesced between flushes: counters accumulate the increments that
+// were received, never the local totals, and gauges keep the latest value.
+// Every flush turns the coalesced updates into a batch that is queued for
+// each upstream and sent in order. While an upstream is unreachable its
+// queue holds up to queueSize batches; beyond that new batches are merged
+// into the last queued one, so memory stays bounded and counter increments
+// are never lost. Delivery is at least once: a batch whose response is lost
+// is sent again.
+type MetricForwardService struct {
+	mu      sync.Mutex
+	pending map[types.MetricID]*types.Metrics
+	order   []types.MetricID
+
+	flushMu   sync.Mutex
+	upstreams []*forwardUpstream
+	queueSize int
+}
+
+type forwardUpstream struct {
+	name   string
+	sender MetricForwardSender
+	queue  [][]types.Metrics
+}
+
+func NewMetricForwardService(queueSize int, senders map[string]MetricForwardSender) *MetricForwardService {
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	upstreams := make([]*forwardUpstream, 0, len(names))
+	for _, name := range names {
+		upstreams = append(upstreams, &forwardUpstream{name: name, sender: senders[name]})
+	}
+
+	return &MetricForwardService{
+		pending:   make(map[types.MetricID]*types.Metrics),
+		upstreams: upstreams,
+		queueSize: max(queueSize, 1),
+	}
+}
+
+func (svc *MetricForwardService) OnMetricUpdate(_ context.Context, event types.MetricsUpdateEvent) {
+	id := types.MetricID{ID: event.Update.ID, MType: event.Update.MType}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	switch id.MType {
+	case types.Counter:
+		if event.Update.Delta == nil {
+			return
+		}
+		svc.coalesce(types.Metrics{ID: id.ID, MType: id.MType, Delta: event.Update.Delta})
+	case types.Gauge:
+		if event.Saved.Value == nil {
+			return
+		}
+		svc.coalesce(types.Metrics{ID: id.ID, MType: id.MType, Value: event.Saved.Value})
+	}
+}
+
+func (svc *MetricForwardService) coalesce(m types.Metrics) {
+	id := types.MetricID{ID: m.ID, MType: m.MType}
+	existing, ok := svc.pending[id]
+	if !ok {
+		svc.order = append(svc.order, id)
+		svc.pending[id] = copyForwardMetric(m)
+		return
+	}
+	mergeForwardMetric(existing, m)
+}
+
+// Flush queues the updates accepted since the previous flush and sends
+// every upstream's queue. It returns the errors of upstreams that could not
+// be drained; their batches stay queued for the next flush.
+func (svc *MetricForwardService) Flush(ctx context.Context) error {
+	svc.flushMu.Lock()
+	defer svc.flushMu.Unlock()
+
+	svc.mu.Lock()
+	batch := make([]types.Metrics, 0, len(svc.order))
+	for _, id := range svc.order {
+		batch = append(batch, *svc.pending[id])
+	}
+	svc.pending = make(map[types.MetricID]*types.Metrics)
+	svc.order = nil
+	svc.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(svc.upstreams))
+	)
+	for i, upstream := range svc.upstreams {
+		if len(batch) > 0 {
+			upstream.enqueue(batch, svc.queueSize)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = upstream.drain(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (u *forwardUpstream) enqueue(batch []types.Metrics, queueSize int) {
+	if len(u.queue) < queueSize {
+		u.queue = append(u.queue, slices.Clone(batch))
+		return
+	}
+
+	tail := u.queue[len(u.queue)-1]
+	index := make(map[types.MetricID]int, len(tail))
+	for i, m := range tail {
+		index[types.MetricID{ID: m.ID, MType: m.MType}] = i
+	}
+	for _, m := range batch {
+		if i, ok := index[types.MetricID{ID: m.ID, MType: m.MType}]; ok {
+			mergeForwardMetric(&tail[i], m)
+			continue
+		}
+		tail = append(tail, *copyForwardMetric(m))
+	}
+	u.queue[len(u.queue)-1] = tail
+
+	logger.Log.Warnw("Forward queue is full, coalescing into the last batch",
+		"upstream", u.name,
+		"batches", len(u.queue),
+	)
+}
+
+func (u *forwardUpstream) drain(ctx context.Context) error {
+	for len(u.queue) > 0 {
+		if err := u.sender.Update(ctx, u.queue[0]); err != nil {
+			logger.Log.Warnw("Failed to forward metrics",
+				"upstream", u.name,
+				"queued_batches", len(u.queue),
+				"error", err,
+			)
+			return fmt.Errorf("forward to %s: %w", u.name, err)
+		}
+		u.queue[0] = nil
+		u.queue = u.queue[1:]
+	}
+	return nil
+}
+
+func copyForwardMetric(m types.Metrics) *types.Metrics {
+	c := types.Metrics{ID: m.ID, MType: m.MType}
+	if m.Delta != nil {
+		delta := *m.Delta
+		c.Delta = &delta
+	}
+	if m.Value != nil {
+		value := *m.Value
+		c.Value = &value
+	}
+	return &c
+}
+
+func mergeForwardMetric(dst *types.Metrics, src types.Metrics) {
+	switch dst.MType {
+	case types.Counter:
+		total := *dst.Delta + *src.Delta
+		dst.Delta = &total
+	case types.Gauge:
+		value := *src.Value
+		dst.Value = &value
+	}
+}
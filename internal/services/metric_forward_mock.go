@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_forward.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricForwardSender is a mock of MetricForwardSender interface.
+type MockMetricForwardSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricForwardSenderMockRecorder
+}
+
+// MockMetricForwardSenderMockRecorder is the mock recorder for MockMetricForwardSender.
+type MockMetricForwardSenderMockRecorder struct {
+	mock *MockMetricForwardSender
+}
+
+// NewMockMetricForwardSender creates a new mock instance.
+func NewMockMetricForwardSender(ctrl *gomock.Controller) *MockMetricForwardSender {
+	mock := &MockMetricForwardSender{ctrl: ctrl}
+	mock.recorder = &MockMetricForwardSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricForwardSender) EXPECT() *MockMetricForwardSenderMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricForwardSender) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricForwardSenderMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricForwardSender)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func newForwardEvent(id string, mType string, update, saved float64) types.MetricsUpdateEvent {
+	m := func(v float64) types.Metrics {
+		if mType == types.Counter {
+			delta := int64(v)
+			return types.Metrics{ID: id, MType: mType, Delta: &delta}
+		}
+		return types.Metrics{ID: id, MType: mType, Value: &v}
+	}
+	return types.MetricsUpdateEvent{Update: m(update), Saved: m(saved), Timestamp: time.Now()}
+}
+
+func forwardCounter(id string, delta int64) types.Metrics {
+	return types.Metrics{ID: id, MType: types.Counter, Delta: &delta}
+}
+
+func forwardGauge(id string, value float64) types.Metrics {
+	return types.Metrics{ID: id, MType: types.Gauge, Value: &value}
+}
+
+func TestMetricForwardService_CoalescesIncrements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	sender := NewMockMetricForwardSender(ctrl)
+	svc := NewMetricForwardService(4, map[string]MetricForwardSender{"central": sender})
+
+	svc.OnMetricUpdate(ctx, newForwardEvent("hits", types.Counter, 2, 102))
+	svc.OnMetricUpdate(ctx, newForwardEvent("temp", types.Gauge, 1.5, 1.5))
+	svc.OnMetricUpdate(ctx, newForwardEvent("hits", types.Counter, 3, 105))
+	svc.OnMetricUpdate(ctx, newForwardEvent("temp", types.Gauge, 2.5, 2.5))
+
+	sender.EXPECT().Update(gomock.Any(), []types.Metrics{
+		forwardCounter("hits", 5),
+		forwardGauge("temp", 2.5),
+	}).Return(nil)
+
+	require.NoError(t, svc.Flush(ctx))
+
+	// Nothing pending and nothing queued: no request is made.
+	require.NoError(t, svc.Flush(ctx))
+}
+
+func TestMetricForwardService_BuffersDuringOutage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	sender := NewMockMetricForwardSender(ctrl)
+	svc := NewMetricForwardService(4, map[string]MetricForwardSender{"central": sender})
+
+	svc.OnMetricUpdate(ctx, newForwardEvent("hits", types.Counter, 1, 1))
+	sender.EXPECT().Update(gomock.Any(), []types.Metrics{forwardCounter("hits", 1)}).
+		Return(errors.New("connection refused"))
+	assert.Error(t, svc.Flush(ctx))
+
+	svc.OnMetricUpdate(ctx, newForwardEvent("hits", types.Counter, 2, 3))
+	gomock.InOrder(
+		sender.EXPECT().Update(gomock.Any(), []types.Metrics{forwardCounter("hits", 1)}).Return(nil),
+		sender.EXPECT().Update(gomock.Any(), []types.Metrics{forwardCounter("hits", 2)}).Return(nil),
+	)
+	require.NoError(t, svc.Flush(ctx))
+}
+
+func TestMetricForwardService_FullQueueMergesIntoTail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	down := NewMockMetricForwardSender(ctrl)
+	up := NewMockMetricForwardSender(ctrl)
+	svc := NewMetricForwardService(2, map[string]MetricForwardSender{"down": down, "up": up})
+
+	down.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("timeout")).Times(4)
+	up.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	for i := 1; i <= 4; i++ {
+		svc.OnMetricUpdate(ctx, newForwardEvent("hits", types.Counter, float64(i), 0))
+		svc.OnMetricUpdate(ctx, newForwardEvent("temp", types.Gauge, float64(i), float64(i)))
+		if i == 3 {
+			svc.OnMetricUpdate(ctx, newForwardEvent("errors", types.Counter, 7, 7))
+		}
+		assert.Error(t, svc.Flush(ctx))
+	}
+
+	// Batches 2-4 were merged into the second slot; counters add up exactly.
+	gomock.InOrder(
+		down.EXPECT().Update(gomock.Any(), []types.Metrics{
+			forwardCounter("hits", 1),
+			forwardGauge("temp", 1),
+		}).Return(nil),
+		down.EXPECT().Update(gomock.Any(), []types.Metrics{
+			forwardCounter("hits", 9),
+			forwardGauge("temp", 4),
+			forwardCounter("errors", 7),
+		}).Return(nil),
+	)
+	require.NoError(t, svc.Flush(ctx))
+}
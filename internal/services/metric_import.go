@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricImportUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+type MetricImportLoader interface {
+	Load(ctx context.Context, metrics []types.Metrics, replace bool) error
+}
+
+// MetricImportService applies imported rows. In merge mode rows go through the
+// regular update rules, so counters are added to the stored values; in replace
+// mode each imported row overwrites the stored metric as is.
+type MetricImportService struct {
+	updater MetricImportUpdater
+	loader  MetricImportLoader
+}
+
+func NewMetricImportService(
+	updater MetricImportUpdater,
+	loader MetricImportLoader,
+) *MetricImportService {
+	return &MetricImportService{updater: updater, loader: loader}
+}
+
+func (svc *MetricImportService) Import(
+	ctx context.Context,
+	metrics []types.Metrics,
+	mode string,
+) error {
+	var err error
+	switch mode {
+	case types.ImportModeReplace:
+		now := time.Now()
+		for i := range metrics {
+			if metrics[i].UpdatedAt.IsZero() {
+				metrics[i].UpdatedAt = now
+			}
+		}
+		err = svc.loader.Load(ctx, metrics, false)
+	default:
+		err = svc.updater.Update(ctx, metrics)
+	}
+
+	if err != nil {
+		logger.Log.Errorw("Failed to import metrics",
+			"mode", mode,
+			"count", len(metrics),
+			"error", err,
+		)
+		return err
+	}
+
+	logger.Log.Infow("Metrics imported",
+		"mode", mode,
+		"count", len(metrics),
+	)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_import.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricImportUpdater is a mock of MetricImportUpdater interface.
+type MockMetricImportUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricImportUpdaterMockRecorder
+}
+
+// MockMetricImportUpdaterMockRecorder is the mock recorder for MockMetricImportUpdater.
+type MockMetricImportUpdaterMockRecorder struct {
+	mock *MockMetricImportUpdater
+}
+
+// NewMockMetricImportUpdater creates a new mock instance.
+func NewMockMetricImportUpdater(ctrl *gomock.Controller) *MockMetricImportUpdater {
+	mock := &MockMetricImportUpdater{ctrl: ctrl}
+	mock.recorder = &MockMetricImportUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricImportUpdater) EXPECT() *MockMetricImportUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockMetricImportUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMetricImportUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricImportUpdater)(nil).Update), ctx, metrics)
+}
+
+// MockMetricImportLoader is a mock of MetricImportLoader interface.
+type MockMetricImportLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricImportLoaderMockRecorder
+}
+
+// MockMetricImportLoaderMockRecorder is the mock recorder for MockMetricImportLoader.
+type MockMetricImportLoaderMockRecorder struct {
+	mock *MockMetricImportLoader
+}
+
+// NewMockMetricImportLoader creates a new mock instance.
+func NewMockMetricImportLoader(ctrl *gomock.Controller) *MockMetricImportLoader {
+	mock := &MockMetricImportLoader{ctrl: ctrl}
+	mock.recorder = &MockMetricImportLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricImportLoader) EXPECT() *MockMetricImportLoaderMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockMetricImportLoader) Load(ctx context.Context, metrics []types.Metrics, replace bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", ctx, metrics, replace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockMetricImportLoaderMockRecorder) Load(ctx, metrics, replace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockMetricImportLoader)(nil).Load), ctx, metrics, replace)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricImportService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockMetricImportUpdater(ctrl)
+	mockLoader := NewMockMetricImportLoader(ctrl)
+	svc := NewMetricImportService(mockUpdater, mockLoader)
+
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("merge mode goes through updater", func(t *testing.T) {
+		metrics := []types.Metrics{{ID: "c", MType: types.Counter}}
+		mockUpdater.EXPECT().Update(ctx, metrics).Return(nil)
+
+		assert.NoError(t, svc.Import(ctx, metrics, types.ImportModeMerge))
+	})
+
+	t.Run("replace mode loads rows without wiping the store", func(t *testing.T) {
+		metrics := []types.Metrics{
+			{ID: "g", MType: types.Gauge, UpdatedAt: updatedAt},
+			{ID: "c", MType: types.Counter},
+		}
+		mockLoader.EXPECT().Load(ctx, gomock.Any(), false).
+			DoAndReturn(func(_ context.Context, got []types.Metrics, _ bool) error {
+				assert.Equal(t, updatedAt, got[0].UpdatedAt, "existing timestamp is kept")
+				assert.False(t, got[1].UpdatedAt.IsZero(), "missing timestamp is stamped")
+				return nil
+			})
+
+		assert.NoError(t, svc.Import(ctx, metrics, types.ImportModeReplace))
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		wantErr := errors.New("some error")
+		mockUpdater.EXPECT().Update(ctx, gomock.Any()).Return(wantErr)
+		mockLoader.EXPECT().Load(ctx, gomock.Any(), false).Return(wantErr)
+
+		assert.Equal(t, wantErr, svc.Import(ctx, nil, types.ImportModeMerge))
+		assert.Equal(t, wantErr, svc.Import(ctx, nil, types.ImportModeReplace))
+	})
+}
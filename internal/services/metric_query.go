@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricQueryLister interface {
+	List(ctx context.Context) ([]types.Metrics, error)
+}
+
+type MetricQueryService struct {
+	lister MetricQueryLister
+}
+
+func NewMetricQueryService(
+	lister MetricQueryLister,
+) *MetricQueryService {
+	return &MetricQueryService{lister: lister}
+}
+
+func (svc *MetricQueryService) Query(
+	ctx context.Context,
+	query types.MetricsQuery,
+) ([]types.MetricsAggregate, error) {
+	metrics, err := svc.lister.List(ctx)
+	if err != nil {
+		logger.Log.Errorw("Failed to list metrics for query",
+			"match", query.Match,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	groups := make(map[string][]float64)
+	for _, m := range metrics {
+		if query.MType != "" && m.MType != query.MType {
+			continue
+		}
+
+		if query.Match != "" {
+			if ok, _ := path.Match(query.Match, m.ID); !ok {
+				continue
+			}
+		}
+
+		value, ok := types.GetMetricFloatValue(&m)
+		if !ok {
+			continue
+		}
+
+		group := getMetricGroup(&m, query.By)
+		groups[group] = append(groups[group], value)
+	}
+
+	result := make([]types.MetricsAggregate, 0, len(groups))
+	for group, values := range groups {
+		result = append(result, types.MetricsAggregate{
+			Group: group,
+			Agg:   query.Agg,
+			Value: aggregate(query.Agg, values),
+			Count: len(values),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Group < result[j].Group
+	})
+
+	return result, nil
+}
+
+func getMetricGroup(metric *types.Metrics, by string) string {
+	switch by {
+	case types.GroupByType:
+		return metric.MType
+	case types.GroupByNone:
+		return ""
+	default:
+		_, labels := types.ParseMetricName(metric.ID)
+		return labels[by]
+	}
+}
+
+func aggregate(agg string, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	switch agg {
+	case types.AggCount:
+		return float64(len(values))
+	case types.AggMin:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = min(result, v)
+		}
+		return result
+	case types.AggMax:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = max(result, v)
+		}
+		return result
+	case types.AggAvg:
+		return aggregate(types.AggSum, values) / float64(len(values))
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	}
+}
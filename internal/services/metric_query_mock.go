@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_query.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricQueryLister is a mock of MetricQueryLister interface.
+type MockMetricQueryLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricQueryListerMockRecorder
+}
+
+// MockMetricQueryListerMockRecorder is the mock recorder for MockMetricQueryLister.
+type MockMetricQueryListerMockRecorder struct {
+	mock *MockMetricQueryLister
+}
+
+// NewMockMetricQueryLister creates a new mock instance.
+func NewMockMetricQueryLister(ctrl *gomock.Controller) *MockMetricQueryLister {
+	mock := &MockMetricQueryLister{ctrl: ctrl}
+	mock.recorder = &MockMetricQueryListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricQueryLister) EXPECT() *MockMetricQueryListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMetricQueryLister) List(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMetricQueryListerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMetricQueryLister)(nil).List), ctx)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricQueryService_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLister := NewMockMetricQueryLister(ctrl)
+	svc := NewMetricQueryService(mockLister)
+
+	ctx := context.Background()
+
+	heapAlloc := 10.0
+	heapSys := 30.0
+	heapCount := int64(5)
+	other := 100.0
+
+	metrics := []types.Metrics{
+		{ID: "HeapAlloc", MType: types.Gauge, Value: &heapAlloc},
+		{ID: "HeapSys", MType: types.Gauge, Value: &heapSys},
+		{ID: "HeapObjects", MType: types.Counter, Delta: &heapCount},
+		{ID: "Other", MType: types.Gauge, Value: &other},
+		{ID: "HeapBroken", MType: types.Gauge},
+	}
+
+	tests := []struct {
+		name    string
+		query   types.MetricsQuery
+		mockErr error
+		want    []types.MetricsAggregate
+		wantErr bool
+	}{
+		{
+			name:  "sum over matched metrics",
+			query: types.MetricsQuery{Match: "Heap*", Agg: types.AggSum},
+			want: []types.MetricsAggregate{
+				{Agg: types.AggSum, Value: 45, Count: 3},
+			},
+		},
+		{
+			name:  "avg grouped by type",
+			query: types.MetricsQuery{Match: "Heap*", Agg: types.AggAvg, By: types.GroupByType},
+			want: []types.MetricsAggregate{
+				{Group: types.Counter, Agg: types.AggAvg, Value: 5, Count: 1},
+				{Group: types.Gauge, Agg: types.AggAvg, Value: 20, Count: 2},
+			},
+		},
+		{
+			name:  "min with type filter",
+			query: types.MetricsQuery{Match: "*", MType: types.Gauge, Agg: types.AggMin},
+			want: []types.MetricsAggregate{
+				{Agg: types.AggMin, Value: 10, Count: 3},
+			},
+		},
+		{
+			name:  "max without match",
+			query: types.MetricsQuery{Agg: types.AggMax},
+			want: []types.MetricsAggregate{
+				{Agg: types.AggMax, Value: 100, Count: 4},
+			},
+		},
+		{
+			name:  "count over matched metrics",
+			query: types.MetricsQuery{Match: "Heap*", Agg: types.AggCount},
+			want: []types.MetricsAggregate{
+				{Agg: types.AggCount, Value: 3, Count: 3},
+			},
+		},
+		{
+			name:  "no matches returns empty result",
+			query: types.MetricsQuery{Match: "Missing*", Agg: types.AggSum},
+			want:  []types.MetricsAggregate{},
+		},
+		{
+			name:    "lister error",
+			query:   types.MetricsQuery{Agg: types.AggSum},
+			mockErr: errors.New("some error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockErr != nil {
+				mockLister.EXPECT().List(ctx).Return(nil, tt.mockErr)
+			} else {
+				mockLister.EXPECT().List(ctx).Return(metrics, nil)
+			}
+
+			got, err := svc.Query(ctx, tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMetricQueryService_QueryByLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLister := NewMockMetricQueryLister(ctrl)
+	svc := NewMetricQueryService(mockLister)
+
+	ctx := context.Background()
+
+	gauge := func(id string, value float64) types.Metrics {
+		return types.Metrics{ID: id, MType: types.Gauge, Value: &value}
+	}
+
+	metrics := []types.Metrics{
+		gauge(types.FormatMetricName("cpu_usage", map[string]string{"host": "a", "core": "0"}), 10),
+		gauge(types.FormatMetricName("cpu_usage", map[string]string{"host": "a", "core": "1"}), 30),
+		gauge(types.FormatMetricName("cpu_usage", map[string]string{"host": "b,c", "core": "0"}), 50),
+		gauge("cpu_usage", 70),
+	}
+
+	tests := []struct {
+		name  string
+		query types.MetricsQuery
+		want  []types.MetricsAggregate
+	}{
+		{
+			name:  "avg grouped by host",
+			query: types.MetricsQuery{Match: "cpu_usage*", Agg: types.AggAvg, By: "host"},
+			want: []types.MetricsAggregate{
+				{Agg: types.AggAvg, Value: 70, Count: 1},
+				{Group: "a", Agg: types.AggAvg, Value: 20, Count: 2},
+				{Group: "b,c", Agg: types.AggAvg, Value: 50, Count: 1},
+			},
+		},
+		{
+			name:  "sum grouped by core",
+			query: types.MetricsQuery{Match: "cpu_usage{*", Agg: types.AggSum, By: "core"},
+			want: []types.MetricsAggregate{
+				{Group: "0", Agg: types.AggSum, Value: 60, Count: 2},
+				{Group: "1", Agg: types.AggSum, Value: 30, Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLister.EXPECT().List(ctx).Return(metrics, nil)
+
+			got, err := svc.Query(ctx, tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
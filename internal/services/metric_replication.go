@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// replicationStaleAfter is twice the leader's heartbeat interval: a replica
+// that has heard nothing for longer counts the silence as lag.
+const replicationStaleAfter = 30 * time.Second
+
+type MetricReplicationLeader interface {
+	Snapshot(ctx context.Context) ([]types.Metrics, error)
+	Subscribe(ctx context.Context) (<-chan types.MetricsReplicationMessage, error)
+}
+
+type MetricReplicationLoader interface {
+	Load(ctx context.Context, metrics []types.Metrics, replace bool) error
+}
+
+type MetricReplicationSaver interface {
+	Save(ctx context.Context, metrics types.Metrics) error
+}
+
+type MetricReplicationGetter interface {
+	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
+}
+
+// MetricReplicationService keeps a read-only replica in step with its leader.
+// Each sync subscribes to the leader's change stream, loads a snapshot and
+// then applies streamed updates. Every resyncInterval seconds the replica's
+// state is replaced by a fresh snapshot, which repairs what the stream cannot
+// carry, such as restores, deletions or events dropped for a slow consumer.
+// Streamed values older than the stored ones are skipped, so events that
+// were already part of the snapshot are not applied twice. Only streamed
+// updates reach the listeners: snapshot values are totals, not deltas.
+type MetricReplicationService struct {
+	leader         MetricReplicationLeader
+	loader         MetricReplicationLoader
+	saver          MetricReplicationSaver
+	getter         MetricReplicationGetter
+	listeners      []MetricUpdateListener
+	resyncInterval time.Duration
+
+	mu         sync.Mutex
+	startedAt  time.Time
+	synced     bool
+	lastSeen   time.Time
+	lastLeader time.Time
+}
+
+func NewMetricReplicationService(
+	leader MetricReplicationLeader,
+	loader MetricReplicationLoader,
+	saver MetricReplicationSaver,
+	getter MetricReplicationGetter,
+	resyncInterval int,
+	listeners ...MetricUpdateListener,
+) *MetricReplicationService {
+	return &MetricReplicationService{
+		leader:         leader,
+		loader:         loader,
+		saver:          saver,
+		getter:         getter,
+		listeners:      listeners,
+		resyncInterval: time.Duration(max(resyncInterval, 1)) * time.Second,
+		startedAt:      time.Now(),
+	}
+}
+
+// Sync replicates until the stream ends, an error occurs or ctx is done.
+func (svc *MetricReplicationService) Sync(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages, err := svc.leader.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.bootstrap(ctx); err != nil {
+		return err
+	}
+
+	resync := time.NewTicker(svc.resyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resync.C:
+			if err := svc.bootstrap(ctx); err != nil {
+				return err
+			}
+		case msg, ok := <-messages:
+			if !ok {
+				return errors.ErrReplicationStreamClosed
+			}
+			if msg.Event != nil {
+				if err := svc.apply(ctx, *msg.Event); err != nil {
+					return err
+				}
+			}
+			svc.seen(msg.Timestamp)
+		}
+	}
+}
+
+func (svc *MetricReplicationService) bootstrap(ctx context.Context) error {
+	requestedAt := time.Now()
+
+	metrics, err := svc.leader.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.loader.Load(ctx, metrics, true); err != nil {
+		logger.Log.Errorw("Failed to load leader snapshot",
+			"count", len(metrics),
+			"error", err,
+		)
+		return err
+	}
+
+	svc.seen(requestedAt)
+
+	logger.Log.Infow("Replica synchronised from leader snapshot", "metrics", len(metrics))
+
+	return nil
+}
+
+func (svc *MetricReplicationService) apply(ctx context.Context, event types.MetricsUpdateEvent) error {
+	m := event.Saved
+
+	existing, err := svc.getter.Get(ctx, types.MetricID{ID: m.ID, MType: m.MType})
+	if err != nil {
+		return err
+	}
+	if existing != nil && (existing.UpdatedAt.After(m.UpdatedAt) || sameReplicatedValue(*existing, m)) {
+		return nil
+	}
+
+	if err := svc.saver.Save(ctx, m); err != nil {
+		logger.Log.Errorw("Failed to save replicated metric",
+			"id", m.ID,
+			"error", err,
+		)
+		return err
+	}
+
+	for _, listener := range svc.listeners {
+		listener.OnMetricUpdate(ctx, event)
+	}
+
+	return nil
+}
+
+func (svc *MetricReplicationService) seen(leaderTime time.Time) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.synced = true
+	svc.lastSeen = time.Now()
+	svc.lastLeader = leaderTime
+}
+
+// Lag estimates how far the replica is behind: the delivery delay of the
+// last message from the leader, plus the silence since then once it exceeds
+// replicationStaleAfter. Before the first sync it is the time since start.
+func (svc *MetricReplicationService) Lag() time.Duration {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	now := time.Now()
+	if !svc.synced {
+		return now.Sub(svc.startedAt)
+	}
+
+	lag := max(svc.lastSeen.Sub(svc.lastLeader), 0)
+	if silent := now.Sub(svc.lastSeen); silent > replicationStaleAfter {
+		lag += silent
+	}
+	return lag
+}
+
+// ReportLag stores the current lag as the ReplicationLagMetric gauge.
+func (svc *MetricReplicationService) ReportLag(ctx context.Context) error {
+	lag := svc.Lag().Seconds()
+	return svc.saver.Save(ctx, types.Metrics{
+		ID:        types.ReplicationLagMetric,
+		MType:     types.Gauge,
+		Value:     &lag,
+		UpdatedAt: time.Now(),
+	})
+}
+
+func sameReplicatedValue(a, b types.Metrics) bool {
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return false
+	}
+	switch a.MType {
+	case types.Counter:
+		return a.Delta != nil && b.Delta != nil && *a.Delta == *b.Delta
+	case types.Gauge:
+		return a.Value != nil && b.Value != nil && *a.Value == *b.Value
+	}
+	return false
+}
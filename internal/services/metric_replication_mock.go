@@ -0,0 +1,178 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_replication.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricReplicationLeader is a mock of MetricReplicationLeader interface.
+type MockMetricReplicationLeader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricReplicationLeaderMockRecorder
+}
+
+// MockMetricReplicationLeaderMockRecorder is the mock recorder for MockMetricReplicationLeader.
+type MockMetricReplicationLeaderMockRecorder struct {
+	mock *MockMetricReplicationLeader
+}
+
+// NewMockMetricReplicationLeader creates a new mock instance.
+func NewMockMetricReplicationLeader(ctrl *gomock.Controller) *MockMetricReplicationLeader {
+	mock := &MockMetricReplicationLeader{ctrl: ctrl}
+	mock.recorder = &MockMetricReplicationLeaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricReplicationLeader) EXPECT() *MockMetricReplicationLeaderMockRecorder {
+	return m.recorder
+}
+
+// Snapshot mocks base method.
+func (m *MockMetricReplicationLeader) Snapshot(ctx context.Context) ([]types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx)
+	ret0, _ := ret[0].([]types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockMetricReplicationLeaderMockRecorder) Snapshot(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockMetricReplicationLeader)(nil).Snapshot), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockMetricReplicationLeader) Subscribe(ctx context.Context) (<-chan types.MetricsReplicationMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(<-chan types.MetricsReplicationMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockMetricReplicationLeaderMockRecorder) Subscribe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockMetricReplicationLeader)(nil).Subscribe), ctx)
+}
+
+// MockMetricReplicationLoader is a mock of MetricReplicationLoader interface.
+type MockMetricReplicationLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricReplicationLoaderMockRecorder
+}
+
+// MockMetricReplicationLoaderMockRecorder is the mock recorder for MockMetricReplicationLoader.
+type MockMetricReplicationLoaderMockRecorder struct {
+	mock *MockMetricReplicationLoader
+}
+
+// NewMockMetricReplicationLoader creates a new mock instance.
+func NewMockMetricReplicationLoader(ctrl *gomock.Controller) *MockMetricReplicationLoader {
+	mock := &MockMetricReplicationLoader{ctrl: ctrl}
+	mock.recorder = &MockMetricReplicationLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricReplicationLoader) EXPECT() *MockMetricReplicationLoaderMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockMetricReplicationLoader) Load(ctx context.Context, metrics []types.Metrics, replace bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", ctx, metrics, replace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockMetricReplicationLoaderMockRecorder) Load(ctx, metrics, replace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockMetricReplicationLoader)(nil).Load), ctx, metrics, replace)
+}
+
+// MockMetricReplicationSaver is a mock of MetricReplicationSaver interface.
+type MockMetricReplicationSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricReplicationSaverMockRecorder
+}
+
+// MockMetricReplicationSaverMockRecorder is the mock recorder for MockMetricReplicationSaver.
+type MockMetricReplicationSaverMockRecorder struct {
+	mock *MockMetricReplicationSaver
+}
+
+// NewMockMetricReplicationSaver creates a new mock instance.
+func NewMockMetricReplicationSaver(ctrl *gomock.Controller) *MockMetricReplicationSaver {
+	mock := &MockMetricReplicationSaver{ctrl: ctrl}
+	mock.recorder = &MockMetricReplicationSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricReplicationSaver) EXPECT() *MockMetricReplicationSaverMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockMetricReplicationSaver) Save(ctx context.Context, metrics types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockMetricReplicationSaverMockRecorder) Save(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockMetricReplicationSaver)(nil).Save), ctx, metrics)
+}
+
+// MockMetricReplicationGetter is a mock of MetricReplicationGetter interface.
+type MockMetricReplicationGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricReplicationGetterMockRecorder
+}
+
+// MockMetricReplicationGetterMockRecorder is the mock recorder for MockMetricReplicationGetter.
+type MockMetricReplicationGetterMockRecorder struct {
+	mock *MockMetricReplicationGetter
+}
+
+// NewMockMetricReplicationGetter creates a new mock instance.
+func NewMockMetricReplicationGetter(ctrl *gomock.Controller) *MockMetricReplicationGetter {
+	mock := &MockMetricReplicationGetter{ctrl: ctrl}
+	mock.recorder = &MockMetricReplicationGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricReplicationGetter) EXPECT() *MockMetricReplicationGetterMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockMetricReplicationGetter) Get(ctx context.Context, id types.MetricID) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockMetricReplicationGetterMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricReplicationGetter)(nil).Get), ctx, id)
+}
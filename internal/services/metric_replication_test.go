@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func replicatedGauge(id string, value float64, updatedAt time.Time) types.Metrics {
+	return types.Metrics{ID: id, MType: types.Gauge, Value: &value, UpdatedAt: updatedAt}
+}
+
+func TestMetricReplicationService_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	t0 := time.Now().Add(-time.Minute)
+
+	leader := NewMockMetricReplicationLeader(ctrl)
+	loader := NewMockMetricReplicationLoader(ctrl)
+	saver := NewMockMetricReplicationSaver(ctrl)
+	getter := NewMockMetricReplicationGetter(ctrl)
+	listener := NewMockMetricUpdateListener(ctrl)
+
+	svc := NewMetricReplicationService(leader, loader, saver, getter, 300, listener)
+
+	messages := make(chan types.MetricsReplicationMessage, 4)
+	fresh := replicatedGauge("temp", 2, t0.Add(2*time.Second))
+	stale := replicatedGauge("temp", 1, t0.Add(time.Second))
+	messages <- types.MetricsReplicationMessage{
+		Event:     &types.MetricsUpdateEvent{Update: fresh, Saved: fresh, Timestamp: fresh.UpdatedAt},
+		Timestamp: fresh.UpdatedAt,
+	}
+	messages <- types.MetricsReplicationMessage{
+		Event:     &types.MetricsUpdateEvent{Update: stale, Saved: stale, Timestamp: stale.UpdatedAt},
+		Timestamp: stale.UpdatedAt,
+	}
+	messages <- types.MetricsReplicationMessage{Timestamp: time.Now()}
+	close(messages)
+
+	snapshot := replicatedGauge("temp", 1, t0.Add(time.Second))
+	id := types.MetricID{ID: "temp", MType: types.Gauge}
+
+	gomock.InOrder(
+		leader.EXPECT().Subscribe(gomock.Any()).Return((<-chan types.MetricsReplicationMessage)(messages), nil),
+		leader.EXPECT().Snapshot(gomock.Any()).Return([]types.Metrics{snapshot}, nil),
+
+		// The snapshot replaces the replica state without notifying listeners.
+		loader.EXPECT().Load(gomock.Any(), []types.Metrics{snapshot}, true).Return(nil),
+
+		// Newer streamed value wins.
+		getter.EXPECT().Get(gomock.Any(), id).Return(&snapshot, nil),
+		saver.EXPECT().Save(gomock.Any(), fresh).Return(nil),
+		listener.EXPECT().OnMetricUpdate(gomock.Any(), gomock.Any()),
+
+		// Older streamed value is ignored.
+		getter.EXPECT().Get(gomock.Any(), id).Return(&fresh, nil),
+	)
+
+	err := svc.Sync(ctx)
+	assert.ErrorIs(t, err, errors.ErrReplicationStreamClosed)
+	assert.Less(t, svc.Lag(), replicationStaleAfter)
+}
+
+func TestMetricReplicationService_SnapshotError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	leader := NewMockMetricReplicationLeader(ctrl)
+	svc := NewMetricReplicationService(leader, NewMockMetricReplicationLoader(ctrl), NewMockMetricReplicationSaver(ctrl), NewMockMetricReplicationGetter(ctrl), 300)
+
+	leader.EXPECT().Subscribe(gomock.Any()).Return(make(<-chan types.MetricsReplicationMessage), nil)
+	leader.EXPECT().Snapshot(gomock.Any()).Return(nil, assert.AnError)
+
+	assert.ErrorIs(t, svc.Sync(context.Background()), assert.AnError)
+}
+
+func TestMetricReplicationService_ResyncDropsDeletedMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	leader := NewMockMetricReplicationLeader(ctrl)
+	loader := NewMockMetricReplicationLoader(ctrl)
+	svc := NewMetricReplicationService(leader, loader, NewMockMetricReplicationSaver(ctrl), NewMockMetricReplicationGetter(ctrl), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The leader restored an older dump that no longer has "temp".
+	restored := []types.Metrics{replicatedGauge("load", 1, time.Now().Add(-time.Hour))}
+
+	gomock.InOrder(
+		leader.EXPECT().Subscribe(gomock.Any()).Return(make(<-chan types.MetricsReplicationMessage), nil),
+		leader.EXPECT().Snapshot(gomock.Any()).Return([]types.Metrics{replicatedGauge("temp", 1, time.Now())}, nil),
+		loader.EXPECT().Load(gomock.Any(), gomock.Any(), true).Return(nil),
+		leader.EXPECT().Snapshot(gomock.Any()).Return(restored, nil),
+		loader.EXPECT().Load(gomock.Any(), restored, true).DoAndReturn(
+			func(context.Context, []types.Metrics, bool) error {
+				cancel()
+				return nil
+			},
+		),
+	)
+
+	assert.ErrorIs(t, svc.Sync(ctx), context.Canceled)
+}
+
+func TestMetricReplicationService_ReportLag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	saver := NewMockMetricReplicationSaver(ctrl)
+	svc := NewMetricReplicationService(NewMockMetricReplicationLeader(ctrl), NewMockMetricReplicationLoader(ctrl), saver, NewMockMetricReplicationGetter(ctrl), 300)
+	svc.startedAt = time.Now().Add(-10 * time.Second)
+
+	saver.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, m types.Metrics) error {
+		assert.Equal(t, types.ReplicationLagMetric, m.ID)
+		assert.Equal(t, types.Gauge, m.MType)
+		require.NotNil(t, m.Value)
+		assert.GreaterOrEqual(t, *m.Value, 10.0)
+		return nil
+	})
+
+	require.NoError(t, svc.ReportLag(context.Background()))
+
+	// A replica that heard the leader a minute ago counts the silence.
+	svc.synced = true
+	svc.lastSeen = time.Now().Add(-time.Minute)
+	svc.lastLeader = svc.lastSeen
+	assert.GreaterOrEqual(t, svc.Lag(), time.Minute)
+}
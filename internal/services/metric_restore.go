@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type MetricRestoreLoader interface {
+	Load(ctx context.Context, metrics []types.Metrics, replace bool) error
+}
+
+type MetricRestoreService struct {
+	loader MetricRestoreLoader
+}
+
+func NewMetricRestoreService(
+	loader MetricRestoreLoader,
+) *MetricRestoreService {
+	return &MetricRestoreService{loader: loader}
+}
+
+func (svc *MetricRestoreService) Restore(
+	ctx context.Context,
+	metrics []types.Metrics,
+	mode string,
+) error {
+	if err := svc.loader.Load(ctx, metrics, mode == types.RestoreModeReplace); err != nil {
+		logger.Log.Errorw("Failed to restore metrics",
+			"mode", mode,
+			"count", len(metrics),
+			"error", err,
+		)
+		return err
+	}
+
+	logger.Log.Infow("Metrics restored",
+		"mode", mode,
+		"count", len(metrics),
+	)
+
+	return nil
+}
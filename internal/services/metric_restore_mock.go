@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/services/metric_restore.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockMetricRestoreLoader is a mock of MetricRestoreLoader interface.
+type MockMetricRestoreLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricRestoreLoaderMockRecorder
+}
+
+// MockMetricRestoreLoaderMockRecorder is the mock recorder for MockMetricRestoreLoader.
+type MockMetricRestoreLoaderMockRecorder struct {
+	mock *MockMetricRestoreLoader
+}
+
+// NewMockMetricRestoreLoader creates a new mock instance.
+func NewMockMetricRestoreLoader(ctrl *gomock.Controller) *MockMetricRestoreLoader {
+	mock := &MockMetricRestoreLoader{ctrl: ctrl}
+	mock.recorder = &MockMetricRestoreLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricRestoreLoader) EXPECT() *MockMetricRestoreLoaderMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockMetricRestoreLoader) Load(ctx context.Context, metrics []types.Metrics, replace bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", ctx, metrics, replace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockMetricRestoreLoaderMockRecorder) Load(ctx, metrics, replace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockMetricRestoreLoader)(nil).Load), ctx, metrics, replace)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestMetricRestoreService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLoader := NewMockMetricRestoreLoader(ctrl)
+	svc := NewMetricRestoreService(mockLoader)
+
+	ctx := context.Background()
+	metrics := []types.Metrics{{ID: "m1", MType: types.Gauge}}
+
+	tests := []struct {
+		name        string
+		mode        string
+		wantReplace bool
+		mockErr     error
+	}{
+		{name: "replace mode", mode: types.RestoreModeReplace, wantReplace: true},
+		{name: "merge mode", mode: types.RestoreModeMerge, wantReplace: false},
+		{name: "loader error", mode: types.RestoreModeMerge, mockErr: errors.New("some error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoader.EXPECT().Load(ctx, metrics, tt.wantReplace).Return(tt.mockErr)
+
+			err := svc.Restore(ctx, metrics, tt.mode)
+			assert.Equal(t, tt.mockErr, err)
+		})
+	}
+}
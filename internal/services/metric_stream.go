@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MetricStreamService fans accepted updates out to live subscribers. Publishing
+// never blocks: a subscriber whose buffer is full either loses the event or is
+// disconnected, depending on the slow consumer policy.
+type MetricStreamService struct {
+	mu          sync.Mutex
+	subscribers map[*metricStreamSubscriber]struct{}
+	bufferSize  int
+	policy      string
+	closed      bool
+}
+
+type metricStreamSubscriber struct {
+	filter  types.MetricsStreamFilter
+	events  chan types.MetricsUpdateEvent
+	dropped int
+}
+
+func NewMetricStreamService(
+	bufferSize int,
+	policy string,
+) (*MetricStreamService, error) {
+	switch policy {
+	case "":
+		policy = types.SlowConsumerDrop
+	case types.SlowConsumerDrop, types.SlowConsumerDisconnect:
+	default:
+		return nil, errors.ErrInvalidSlowConsumerPolicy
+	}
+
+	return &MetricStreamService{
+		subscribers: make(map[*metricStreamSubscriber]struct{}),
+		bufferSize:  max(bufferSize, 1),
+		policy:      policy,
+	}, nil
+}
+
+// Subscribe registers a subscriber for events matching filter. The returned
+// channel is closed when the subscriber is disconnected or the stream is closed;
+// cancel must be called once the caller stops reading.
+func (svc *MetricStreamService) Subscribe(
+	ctx context.Context,
+	filter types.MetricsStreamFilter,
+) (<-chan types.MetricsUpdateEvent, func(), error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if svc.closed {
+		return nil, nil, errors.ErrStreamClosed
+	}
+
+	sub := &metricStreamSubscriber{
+		filter: filter,
+		events: make(chan types.MetricsUpdateEvent, svc.bufferSize),
+	}
+	svc.subscribers[sub] = struct{}{}
+
+	cancel := func() {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		svc.remove(sub)
+	}
+
+	return sub.events, cancel, nil
+}
+
+func (svc *MetricStreamService) OnMetricUpdate(
+	ctx context.Context,
+	event types.MetricsUpdateEvent,
+) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for sub := range svc.subscribers {
+		if !sub.matches(&event.Saved) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+			continue
+		default:
+		}
+
+		if svc.policy == types.SlowConsumerDisconnect {
+			logger.Log.Warnw("Disconnecting slow stream subscriber",
+				"buffer", svc.bufferSize,
+			)
+			svc.remove(sub)
+			continue
+		}
+
+		sub.dropped++
+		if sub.dropped == 1 || sub.dropped%svc.bufferSize == 0 {
+			logger.Log.Warnw("Dropping events for slow stream subscriber",
+				"dropped", sub.dropped,
+			)
+		}
+	}
+}
+
+// Close disconnects all subscribers and rejects new ones. It is meant to be
+// registered as a server shutdown hook so open streams do not hold shutdown.
+func (svc *MetricStreamService) Close() {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.closed = true
+	for sub := range svc.subscribers {
+		svc.remove(sub)
+	}
+}
+
+func (svc *MetricStreamService) remove(sub *metricStreamSubscriber) {
+	if _, ok := svc.subscribers[sub]; !ok {
+		return
+	}
+	delete(svc.subscribers, sub)
+	close(sub.events)
+}
+
+func (sub *metricStreamSubscriber) matches(m *types.Metrics) bool {
+	if sub.filter.MType != "" && m.MType != sub.filter.MType {
+		return false
+	}
+
+	if sub.filter.Match != "" {
+		if ok, _ := path.Match(sub.filter.Match, m.ID); !ok {
+			return false
+		}
+	}
+
+	return true
+}
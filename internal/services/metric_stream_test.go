@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func newStreamEvent(id string, mType string) types.MetricsUpdateEvent {
+	m := types.Metrics{ID: id, MType: mType}
+	return types.MetricsUpdateEvent{Update: m, Saved: m, Timestamp: time.Now()}
+}
+
+func TestNewMetricStreamService_InvalidPolicy(t *testing.T) {
+	svc, err := NewMetricStreamService(8, "block")
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errors.ErrInvalidSlowConsumerPolicy)
+}
+
+func TestMetricStreamService_Filters(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		filter  types.MetricsStreamFilter
+		wantIDs []string
+	}{
+		{
+			name:    "no filter",
+			filter:  types.MetricsStreamFilter{},
+			wantIDs: []string{"cpu_user", "hits", "cpu_sys"},
+		},
+		{
+			name:    "by type",
+			filter:  types.MetricsStreamFilter{MType: types.Counter},
+			wantIDs: []string{"hits"},
+		},
+		{
+			name:    "by match",
+			filter:  types.MetricsStreamFilter{Match: "cpu_*"},
+			wantIDs: []string{"cpu_user", "cpu_sys"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewMetricStreamService(8, types.SlowConsumerDrop)
+			require.NoError(t, err)
+
+			events, cancel, err := svc.Subscribe(ctx, tt.filter)
+			require.NoError(t, err)
+			defer cancel()
+
+			svc.OnMetricUpdate(ctx, newStreamEvent("cpu_user", types.Gauge))
+			svc.OnMetricUpdate(ctx, newStreamEvent("hits", types.Counter))
+			svc.OnMetricUpdate(ctx, newStreamEvent("cpu_sys", types.Gauge))
+
+			var got []string
+			for len(events) > 0 {
+				event := <-events
+				got = append(got, event.Saved.ID)
+			}
+			assert.Equal(t, tt.wantIDs, got)
+		})
+	}
+}
+
+func TestMetricStreamService_SlowConsumerDrop(t *testing.T) {
+	ctx := context.Background()
+
+	svc, err := NewMetricStreamService(2, types.SlowConsumerDrop)
+	require.NoError(t, err)
+
+	events, cancel, err := svc.Subscribe(ctx, types.MetricsStreamFilter{})
+	require.NoError(t, err)
+	defer cancel()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		svc.OnMetricUpdate(ctx, newStreamEvent(id, types.Gauge))
+	}
+
+	assert.Equal(t, "a", (<-events).Saved.ID)
+	assert.Equal(t, "b", (<-events).Saved.ID)
+
+	svc.OnMetricUpdate(ctx, newStreamEvent("e", types.Gauge))
+	event, ok := <-events
+	assert.True(t, ok)
+	assert.Equal(t, "e", event.Saved.ID)
+}
+
+func TestMetricStreamService_SlowConsumerDisconnect(t *testing.T) {
+	ctx := context.Background()
+
+	svc, err := NewMetricStreamService(1, types.SlowConsumerDisconnect)
+	require.NoError(t, err)
+
+	events, cancel, err := svc.Subscribe(ctx, types.MetricsStreamFilter{})
+	require.NoError(t, err)
+	defer cancel()
+
+	svc.OnMetricUpdate(ctx, newStreamEvent("a", types.Gauge))
+	svc.OnMetricUpdate(ctx, newStreamEvent("b", types.Gauge))
+
+	event, ok := <-events
+	assert.True(t, ok)
+	assert.Equal(t, "a", event.Saved.ID)
+
+	_, ok = <-events
+	assert.False(t, ok, "slow subscriber should be disconnected")
+}
+
+func TestMetricStreamService_Close(t *testing.T) {
+	ctx := context.Background()
+
+	svc, err := NewMetricStreamService(4, types.SlowConsumerDrop)
+	require.NoError(t, err)
+
+	events, cancel, err := svc.Subscribe(ctx, types.MetricsStreamFilter{})
+	require.NoError(t, err)
+
+	svc.Close()
+	cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+
+	_, _, err = svc.Subscribe(ctx, types.MetricsStreamFilter{})
+	assert.ErrorIs(t, err, errors.ErrStreamClosed)
+}
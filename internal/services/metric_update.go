@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
@@ -15,16 +16,22 @@ type MetricUpdateGetter interface {
 	Get(ctx context.Context, id types.MetricID) (*types.Metrics, error)
 }
 
+type MetricUpdateListener interface {
+	OnMetricUpdate(ctx context.Context, event types.MetricsUpdateEvent)
+}
+
 type MetricUpdateService struct {
-	saver  MetricUpdateSaver
-	getter MetricUpdateGetter
+	saver     MetricUpdateSaver
+	getter    MetricUpdateGetter
+	listeners []MetricUpdateListener
 }
 
 func NewMetricUpdateService(
 	saver MetricUpdateSaver,
 	getter MetricUpdateGetter,
+	listeners ...MetricUpdateListener,
 ) *MetricUpdateService {
-	return &MetricUpdateService{saver: saver, getter: getter}
+	return &MetricUpdateService{saver: saver, getter: getter, listeners: listeners}
 }
 
 func (svc *MetricUpdateService) Update(
@@ -32,6 +39,9 @@ func (svc *MetricUpdateService) Update(
 	metrics []types.Metrics,
 ) error {
 	for _, m := range metrics {
+		saved := m
+		saved.UpdatedAt = time.Now()
+
 		if m.MType == types.Counter {
 			existing, err := svc.getter.Get(ctx, types.MetricID{ID: m.ID, MType: m.MType})
 			if err != nil {
@@ -43,18 +53,31 @@ func (svc *MetricUpdateService) Update(
 			}
 
 			if existing != nil && m.Delta != nil && existing.Delta != nil {
-				*m.Delta += *existing.Delta
+				total := *m.Delta + *existing.Delta
+				saved.Delta = &total
 			}
 		}
 
-		if err := svc.saver.Save(ctx, m); err != nil {
+		if err := svc.saver.Save(ctx, saved); err != nil {
 			logger.Log.Errorw("Failed to save metric",
 				"id", m.ID,
 				"error", err,
 			)
 			return err
 		}
+
+		svc.notify(ctx, types.MetricsUpdateEvent{
+			Update:    m,
+			Saved:     saved,
+			Timestamp: saved.UpdatedAt,
+		})
 	}
 
 	return nil
 }
+
+func (svc *MetricUpdateService) notify(ctx context.Context, event types.MetricsUpdateEvent) {
+	for _, listener := range svc.listeners {
+		listener.OnMetricUpdate(ctx, event)
+	}
+}
@@ -86,3 +86,38 @@ func (mr *MockMetricUpdateGetterMockRecorder) Get(ctx, id interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetricUpdateGetter)(nil).Get), ctx, id)
 }
+
+// MockMetricUpdateListener is a mock of MetricUpdateListener interface.
+type MockMetricUpdateListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricUpdateListenerMockRecorder
+}
+
+// MockMetricUpdateListenerMockRecorder is the mock recorder for MockMetricUpdateListener.
+type MockMetricUpdateListenerMockRecorder struct {
+	mock *MockMetricUpdateListener
+}
+
+// NewMockMetricUpdateListener creates a new mock instance.
+func NewMockMetricUpdateListener(ctrl *gomock.Controller) *MockMetricUpdateListener {
+	mock := &MockMetricUpdateListener{ctrl: ctrl}
+	mock.recorder = &MockMetricUpdateListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricUpdateListener) EXPECT() *MockMetricUpdateListenerMockRecorder {
+	return m.recorder
+}
+
+// OnMetricUpdate mocks base method.
+func (m *MockMetricUpdateListener) OnMetricUpdate(ctx context.Context, event types.MetricsUpdateEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnMetricUpdate", ctx, event)
+}
+
+// OnMetricUpdate indicates an expected call of OnMetricUpdate.
+func (mr *MockMetricUpdateListenerMockRecorder) OnMetricUpdate(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnMetricUpdate", reflect.TypeOf((*MockMetricUpdateListener)(nil).OnMetricUpdate), ctx, event)
+}
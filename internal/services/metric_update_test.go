@@ -64,12 +64,13 @@ func TestMetricUpdateService_Update_TableDriven(t *testing.T) {
 			name: "Simple gauge metric saves successfully",
 			setupMocks: func(mockSaver *MockMetricUpdateSaver, mockGetter *MockMetricUpdateGetter) {
 				mockSaver.EXPECT().
-					Save(ctx, types.Metrics{
-						ID:    "cpu_usage",
-						MType: types.Gauge,
-						Value: &valueGauge,
-					}).
-					Return(nil)
+					Save(ctx, gomock.AssignableToTypeOf(types.Metrics{})).
+					DoAndReturn(func(_ context.Context, m types.Metrics) error {
+						assert.Equal(t, "cpu_usage", m.ID)
+						assert.Equal(t, &valueGauge, m.Value)
+						assert.False(t, m.UpdatedAt.IsZero())
+						return nil
+					})
 			},
 			args: args{metrics: []types.Metrics{{
 				ID:    "cpu_usage",
@@ -82,12 +83,13 @@ func TestMetricUpdateService_Update_TableDriven(t *testing.T) {
 			name: "Save returns error",
 			setupMocks: func(mockSaver *MockMetricUpdateSaver, mockGetter *MockMetricUpdateGetter) {
 				mockSaver.EXPECT().
-					Save(ctx, types.Metrics{
-						ID:    "memory_usage",
-						MType: types.Gauge,
-						Value: &valueMem,
-					}).
-					Return(wantErr)
+					Save(ctx, gomock.AssignableToTypeOf(types.Metrics{})).
+					DoAndReturn(func(_ context.Context, m types.Metrics) error {
+						assert.Equal(t, "memory_usage", m.ID)
+						assert.Equal(t, &valueMem, m.Value)
+						assert.False(t, m.UpdatedAt.IsZero())
+						return wantErr
+					})
 			},
 			args: args{metrics: []types.Metrics{{
 				ID:    "memory_usage",
@@ -131,3 +133,54 @@ func TestMetricUpdateService_Update_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricUpdateService_Update_NotifiesListeners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	delta := int64(3)
+	existingDelta := int64(7)
+
+	mockSaver := NewMockMetricUpdateSaver(ctrl)
+	mockGetter := NewMockMetricUpdateGetter(ctrl)
+	mockListener := NewMockMetricUpdateListener(ctrl)
+
+	mockGetter.EXPECT().
+		Get(ctx, types.MetricID{ID: "hits", MType: types.Counter}).
+		Return(&types.Metrics{ID: "hits", MType: types.Counter, Delta: &existingDelta}, nil)
+	mockSaver.EXPECT().Save(ctx, gomock.Any()).Return(nil)
+	mockListener.EXPECT().
+		OnMetricUpdate(ctx, gomock.AssignableToTypeOf(types.MetricsUpdateEvent{})).
+		Do(func(_ context.Context, event types.MetricsUpdateEvent) {
+			assert.Equal(t, int64(3), *event.Update.Delta)
+			assert.Equal(t, int64(10), *event.Saved.Delta)
+			assert.False(t, event.Timestamp.IsZero())
+		})
+
+	service := NewMetricUpdateService(mockSaver, mockGetter, mockListener)
+
+	err := service.Update(ctx, []types.Metrics{{ID: "hits", MType: types.Counter, Delta: &delta}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), delta, "caller's increment must not be modified")
+}
+
+func TestMetricUpdateService_Update_SaveErrorSkipsListeners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	value := 1.5
+
+	mockSaver := NewMockMetricUpdateSaver(ctrl)
+	mockGetter := NewMockMetricUpdateGetter(ctrl)
+	mockListener := NewMockMetricUpdateListener(ctrl)
+
+	mockSaver.EXPECT().Save(ctx, gomock.Any()).Return(assert.AnError)
+
+	service := NewMetricUpdateService(mockSaver, mockGetter, mockListener)
+
+	err := service.Update(ctx, []types.Metrics{{ID: "load", MType: types.Gauge, Value: &value}})
+	assert.ErrorIs(t, err, assert.AnError)
+}
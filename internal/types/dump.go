@@ -0,0 +1,11 @@
+package types
+
+const (
+	DumpFormatJSON = "json"
+	DumpFormatGzip = "gzip"
+)
+
+const (
+	RestoreModeReplace = "replace"
+	RestoreModeMerge   = "merge"
+)
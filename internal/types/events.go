@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+const (
+	SlowConsumerDrop       = "drop"
+	SlowConsumerDisconnect = "disconnect"
+)
+
+// MetricsUpdateEvent describes an accepted update: Update is the metric as it
+// was received (an increment for counters), Saved is the resulting stored value.
+type MetricsUpdateEvent struct {
+	Update    Metrics   `json:"update"`
+	Saved     Metrics   `json:"saved"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type MetricsStreamFilter struct {
+	Match string `json:"match,omitempty"`
+	MType string `json:"type,omitempty"`
+}
+
+// MetricsReplicationMessage is what a replica reads from its leader's stream:
+// an update, or a heartbeat when Event is nil. Timestamp is the leader's
+// clock in both cases.
+type MetricsReplicationMessage struct {
+	Event     *MetricsUpdateEvent
+	Timestamp time.Time
+}
+
+const ReplicationLagMetric = "replication_lag_seconds"
@@ -0,0 +1,27 @@
+package types
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+)
+
+const (
+	ImportModeReplace = "replace"
+	ImportModeMerge   = "merge"
+)
+
+// MetricsImportReport summarizes an import: how many rows were applied and
+// which rows were rejected, with the reason for each.
+type MetricsImportReport struct {
+	Mode     string                   `json:"mode"`
+	Format   string                   `json:"format"`
+	Accepted int                      `json:"accepted"`
+	Rejected []MetricsImportRejection `json:"rejected"`
+}
+
+type MetricsImportRejection struct {
+	Row    int    `json:"row"`
+	ID     string `json:"id,omitempty"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import "context"
+
+type clientIdentityKey struct{}
+
+// WithClientIdentity returns ctx carrying the identity of the client that
+// sent the request, taken from its verified certificate.
+func WithClientIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, identity)
+}
+
+func ClientIdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(string)
+	return identity, ok
+}
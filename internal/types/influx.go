@@ -0,0 +1,14 @@
+package types
+
+// InfluxWriteError mirrors the InfluxDB error body returned when some lines
+// of a write could not be parsed. Valid lines are still applied.
+type InfluxWriteError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Errors  []InfluxWriteLineError `json:"errors"`
+}
+
+type InfluxWriteLineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`, `}`, `\}`)
+
+// FormatMetricName folds labels into a metric name as name{k1=v1,k2=v2}.
+// Keys are sorted so the same label set always yields the same MetricID;
+// separators inside keys and values are backslash-escaped.
+func FormatMetricName(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labelEscaper.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(labelEscaper.Replace(labels[k]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// ParseMetricName splits a name built by FormatMetricName back into the
+// metric name and its labels. An ID without a well-formed label set is
+// returned whole with nil labels.
+func ParseMetricName(id string) (string, map[string]string) {
+	start := strings.IndexByte(id, '{')
+	if start < 0 || !strings.HasSuffix(id, "}") {
+		return id, nil
+	}
+
+	labels := make(map[string]string)
+	var key, cur strings.Builder
+	inValue, escaped := false, false
+	body := id[start+1:]
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case escaped:
+			cur.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '=' && !inValue:
+			key.WriteString(cur.String())
+			cur.Reset()
+			inValue = true
+		case c == ',' || c == '}':
+			if !inValue || key.Len() == 0 {
+				return id, nil
+			}
+			labels[key.String()] = cur.String()
+			key.Reset()
+			cur.Reset()
+			inValue = false
+			if c == '}' {
+				if i != len(body)-1 {
+					return id, nil
+				}
+				return id[:start], labels
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+
+	return id, nil
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMetricName(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric string
+		labels map[string]string
+		want   string
+	}{
+		{"no labels", "cpu_usage", nil, "cpu_usage"},
+		{"sorted labels", "cpu_usage", map[string]string{"host": "a", "cpu": "0"}, "cpu_usage{cpu=0,host=a}"},
+		{"escaped separators", "disk_free", map[string]string{"path": `C:\x,y=z}`}, `disk_free{path=C:\\x\,y\=z\}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatMetricName(tt.metric, tt.labels))
+		})
+	}
+}
+
+func TestParseMetricName(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantName   string
+		wantLabels map[string]string
+	}{
+		{"no labels", "cpu_usage", "cpu_usage", nil},
+		{"labels", "cpu_usage{cpu=0,host=a}", "cpu_usage", map[string]string{"host": "a", "cpu": "0"}},
+		{"escaped separators", `disk_free{path=C:\\x\,y\=z\}}`, "disk_free", map[string]string{"path": `C:\x,y=z}`}},
+		{"empty value", "cpu_usage{host=}", "cpu_usage", map[string]string{"host": ""}},
+		{"missing value", "cpu_usage{host}", "cpu_usage{host}", nil},
+		{"trailing garbage", "cpu_usage{host=a}x}", "cpu_usage{host=a}x}", nil},
+		{"unterminated", "cpu_usage{host=a", "cpu_usage{host=a", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, labels := ParseMetricName(tt.id)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantLabels, labels)
+		})
+	}
+}
+
+func TestParseMetricName_RoundTrip(t *testing.T) {
+	labels := map[string]string{"path": `C:\x,y=z}`, "host": "a{b"}
+	name, got := ParseMetricName(FormatMetricName("disk_free", labels))
+	assert.Equal(t, "disk_free", name)
+	assert.Equal(t, labels, got)
+}
@@ -1,8 +1,8 @@
 package types
 
 import (
-	"html"
 	"strconv"
+	"time"
 )
 
 const (
@@ -21,6 +21,8 @@ type Metrics struct {
 	Delta *int64   `json:"delta,omitempty"`
 	Value *float64 `json:"value,omitempty"`
 	Hash  string   `json:"hash,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
 }
 
 func GetMetricStringValue(metric *Metrics) string {
@@ -44,21 +46,6 @@ func GetMetricStringValue(metric *Metrics) string {
 	}
 }
 
-func GetMetricsHTML(metrics []Metrics) string {
-	htmlStr := "<html><head><title>Metrics List</title></head><body>"
-	htmlStr += "<h1>Metrics</h1>"
-	htmlStr += "<ul>"
-
-	for _, metric := range metrics {
-		name := html.EscapeString(metric.ID)
-		value := html.EscapeString(GetMetricStringValue(&metric))
-		htmlStr += "<li>" + name + ": " + value + "</li>"
-	}
-
-	htmlStr += "</ul></body></html>"
-	return htmlStr
-}
-
 type MetricsUpdatePathRequest struct {
 	Name  string `json:"name"`
 	MType string `json:"type"`
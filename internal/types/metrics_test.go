@@ -1,7 +1,6 @@
 package types
 
 import (
-	"html"
 	"strconv"
 	"testing"
 
@@ -71,50 +70,3 @@ func TestGetMetricStringValue(t *testing.T) {
 		})
 	}
 }
-
-func TestGetMetricsHTML(t *testing.T) {
-	counterVal := float64(123)
-	gaugeVal := float64(45.6789)
-
-	metrics := []Metrics{
-		{
-			ID:    "metric1",
-			MType: Counter,
-			Value: &counterVal,
-		},
-		{
-			ID:    "metric2",
-			MType: Gauge,
-			Value: &gaugeVal,
-		},
-		{
-			ID:    "metric<3",
-			MType: Gauge,
-			Value: &gaugeVal,
-		},
-	}
-
-	result := GetMetricsHTML(metrics)
-
-	// Basic checks
-	assert.Contains(t, result, "<html>")
-	assert.Contains(t, result, "<h1>Metrics</h1>")
-	assert.Contains(t, result, "<ul>")
-	assert.Contains(t, result, "</ul>")
-	assert.Contains(t, result, "</html>")
-
-	// Check escaped metric ID
-	assert.Contains(t, result, "metric1: "+GetMetricStringValue(&metrics[0]))
-	assert.Contains(t, result, "metric2: "+GetMetricStringValue(&metrics[1]))
-
-	// Check HTML escaping (metric ID "metric<3" should be escaped)
-	escapedID := html.EscapeString(metrics[2].ID)
-	expectedEntry := "<li>" + escapedID + ": " + GetMetricStringValue(&metrics[2]) + "</li>"
-	assert.Contains(t, result, expectedEntry)
-}
-
-func TestGetMetricsHTMLEmpty(t *testing.T) {
-	result := GetMetricsHTML(nil)
-	assert.Contains(t, result, "<ul></ul>")
-	assert.Contains(t, result, "<h1>Metrics</h1>")
-}
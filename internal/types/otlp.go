@@ -0,0 +1,159 @@
+package types
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// The structs below cover the subset of the OTLP metrics JSON encoding the
+// receiver understands. OTLP encodes 64-bit integers as strings and enums as
+// either numbers or names, hence the lenient OTLPInt64 and OTLPTemporality.
+
+const (
+	OTLPTemporalityUnspecified OTLPTemporality = 0
+	OTLPTemporalityDelta       OTLPTemporality = 1
+	OTLPTemporalityCumulative  OTLPTemporality = 2
+)
+
+type OTLPExportMetricsRequest struct {
+	ResourceMetrics []OTLPResourceMetrics `json:"resourceMetrics"`
+}
+
+type OTLPResourceMetrics struct {
+	Resource     OTLPResource       `json:"resource"`
+	ScopeMetrics []OTLPScopeMetrics `json:"scopeMetrics"`
+}
+
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+type OTLPScopeMetrics struct {
+	Metrics []OTLPMetric `json:"metrics"`
+}
+
+type OTLPMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit,omitempty"`
+	Gauge     *OTLPGauge     `json:"gauge,omitempty"`
+	Sum       *OTLPSum       `json:"sum,omitempty"`
+	Histogram *OTLPHistogram `json:"histogram,omitempty"`
+}
+
+type OTLPGauge struct {
+	DataPoints []OTLPNumberDataPoint `json:"dataPoints"`
+}
+
+type OTLPSum struct {
+	DataPoints             []OTLPNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality OTLPTemporality       `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type OTLPHistogram struct {
+	DataPoints             []OTLPHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality OTLPTemporality          `json:"aggregationTemporality"`
+}
+
+type OTLPNumberDataPoint struct {
+	Attributes   []OTLPKeyValue `json:"attributes"`
+	TimeUnixNano OTLPInt64      `json:"timeUnixNano"`
+	AsDouble     *float64       `json:"asDouble,omitempty"`
+	AsInt        *OTLPInt64     `json:"asInt,omitempty"`
+}
+
+type OTLPHistogramDataPoint struct {
+	Attributes   []OTLPKeyValue `json:"attributes"`
+	TimeUnixNano OTLPInt64      `json:"timeUnixNano"`
+	Count        OTLPInt64      `json:"count"`
+	Sum          *float64       `json:"sum,omitempty"`
+}
+
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+type OTLPAnyValue struct {
+	StringValue *string    `json:"stringValue,omitempty"`
+	BoolValue   *bool      `json:"boolValue,omitempty"`
+	IntValue    *OTLPInt64 `json:"intValue,omitempty"`
+	DoubleValue *float64   `json:"doubleValue,omitempty"`
+}
+
+// String renders scalar attribute values; arrays and maps are not supported
+// as labels and render as an empty string.
+func (v OTLPAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+type OTLPExportMetricsResponse struct {
+	PartialSuccess *OTLPPartialSuccess `json:"partialSuccess,omitempty"`
+}
+
+type OTLPPartialSuccess struct {
+	RejectedDataPoints OTLPInt64 `json:"rejectedDataPoints,string"`
+	ErrorMessage       string    `json:"errorMessage,omitempty"`
+}
+
+type OTLPInt64 int64
+
+func (i *OTLPInt64) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		*i = OTLPInt64(v)
+		return nil
+	}
+
+	var v int64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*i = OTLPInt64(v)
+	return nil
+}
+
+type OTLPTemporality int
+
+func (t *OTLPTemporality) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		switch s {
+		case "AGGREGATION_TEMPORALITY_DELTA":
+			*t = OTLPTemporalityDelta
+		case "AGGREGATION_TEMPORALITY_CUMULATIVE":
+			*t = OTLPTemporalityCumulative
+		default:
+			*t = OTLPTemporalityUnspecified
+		}
+		return nil
+	}
+
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = OTLPTemporality(v)
+	return nil
+}
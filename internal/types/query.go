@@ -0,0 +1,53 @@
+package types
+
+const (
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggCount = "count"
+)
+
+// Queries are grouped by nothing, by metric type, or by any other value
+// taken as a label key: metrics are then grouped by the value of that label
+// in their name{k=v,...} ID, and metrics without it fall into the empty
+// group.
+const (
+	GroupByNone = ""
+	GroupByType = "type"
+)
+
+type MetricsQuery struct {
+	Match string `json:"match"`
+	MType string `json:"type,omitempty"`
+	Agg   string `json:"agg"`
+	By    string `json:"by,omitempty"`
+}
+
+type MetricsAggregate struct {
+	Group string  `json:"group,omitempty"`
+	Agg   string  `json:"agg"`
+	Value float64 `json:"value"`
+	Count int     `json:"count"`
+}
+
+func GetMetricFloatValue(metric *Metrics) (float64, bool) {
+	if metric == nil {
+		return 0, false
+	}
+
+	switch metric.MType {
+	case Counter:
+		if metric.Delta == nil {
+			return 0, false
+		}
+		return float64(*metric.Delta), true
+	case Gauge:
+		if metric.Value == nil {
+			return 0, false
+		}
+		return *metric.Value, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,15 @@
+package types
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// APIToken is an entry of the server token file. Only the SHA-256 of the
+// token is kept, hex-encoded; Name identifies the token in logs.
+type APIToken struct {
+	Name   string   `json:"name"`
+	SHA256 string   `json:"sha256"`
+	Scopes []string `json:"scopes"`
+}
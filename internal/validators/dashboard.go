@@ -0,0 +1,22 @@
+package validators
+
+import (
+	"strconv"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func ValidateMetricsListFilter(mType string, refresh string) error {
+	if mType != "" && mType != types.Counter && mType != types.Gauge {
+		return errors.ErrInvalidMetricType
+	}
+
+	if refresh != "" {
+		if v, err := strconv.Atoi(refresh); err != nil || v < 0 {
+			return errors.ErrInvalidRefreshInterval
+		}
+	}
+
+	return nil
+}
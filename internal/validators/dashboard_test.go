@@ -0,0 +1,18 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetricsListFilter(t *testing.T) {
+	assert.NoError(t, ValidateMetricsListFilter("", ""))
+	assert.NoError(t, ValidateMetricsListFilter(types.Gauge, "0"))
+	assert.NoError(t, ValidateMetricsListFilter(types.Counter, "30"))
+	assert.Equal(t, internalErrors.ErrInvalidMetricType, ValidateMetricsListFilter("histogram", ""))
+	assert.Equal(t, internalErrors.ErrInvalidRefreshInterval, ValidateMetricsListFilter("", "-1"))
+	assert.Equal(t, internalErrors.ErrInvalidRefreshInterval, ValidateMetricsListFilter("", "soon"))
+}
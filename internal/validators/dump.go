@@ -0,0 +1,24 @@
+package validators
+
+import (
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func ValidateDumpFormat(format string) error {
+	switch format {
+	case types.DumpFormatJSON, types.DumpFormatGzip:
+		return nil
+	default:
+		return errors.ErrInvalidDumpFormat
+	}
+}
+
+func ValidateRestoreMode(mode string) error {
+	switch mode {
+	case types.RestoreModeReplace, types.RestoreModeMerge:
+		return nil
+	default:
+		return errors.ErrInvalidRestoreMode
+	}
+}
@@ -0,0 +1,45 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDumpFormat(t *testing.T) {
+	assert.NoError(t, ValidateDumpFormat(types.DumpFormatJSON))
+	assert.NoError(t, ValidateDumpFormat(types.DumpFormatGzip))
+	assert.Equal(t, internalErrors.ErrInvalidDumpFormat, ValidateDumpFormat("xml"))
+}
+
+func TestValidateRestoreMode(t *testing.T) {
+	assert.NoError(t, ValidateRestoreMode(types.RestoreModeReplace))
+	assert.NoError(t, ValidateRestoreMode(types.RestoreModeMerge))
+	assert.Equal(t, internalErrors.ErrInvalidRestoreMode, ValidateRestoreMode("append"))
+}
+
+func TestValidateMetrics(t *testing.T) {
+	delta := int64(1)
+	value := 1.5
+
+	tests := []struct {
+		name    string
+		metric  types.Metrics
+		wantErr error
+	}{
+		{"valid counter", types.Metrics{ID: "c", MType: types.Counter, Delta: &delta}, nil},
+		{"valid gauge", types.Metrics{ID: "g", MType: types.Gauge, Value: &value}, nil},
+		{"empty id", types.Metrics{MType: types.Gauge, Value: &value}, internalErrors.ErrInvalidMetricID},
+		{"invalid type", types.Metrics{ID: "x", MType: "histogram"}, internalErrors.ErrInvalidMetricType},
+		{"counter without delta", types.Metrics{ID: "c", MType: types.Counter, Value: &value}, internalErrors.ErrInvalidCounterValue},
+		{"gauge without value", types.Metrics{ID: "g", MType: types.Gauge, Delta: &delta}, internalErrors.ErrInvalidGaugeValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, ValidateMetrics(tt.metric))
+		})
+	}
+}
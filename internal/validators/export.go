@@ -0,0 +1,24 @@
+package validators
+
+import (
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func ValidateExportFormat(format string) error {
+	switch format {
+	case types.ExportFormatCSV, types.ExportFormatJSON, types.ExportFormatNDJSON:
+		return nil
+	default:
+		return errors.ErrInvalidExportFormat
+	}
+}
+
+func ValidateImportMode(mode string) error {
+	switch mode {
+	case types.ImportModeReplace, types.ImportModeMerge:
+		return nil
+	default:
+		return errors.ErrInvalidImportMode
+	}
+}
@@ -0,0 +1,22 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExportFormat(t *testing.T) {
+	assert.NoError(t, ValidateExportFormat(types.ExportFormatCSV))
+	assert.NoError(t, ValidateExportFormat(types.ExportFormatJSON))
+	assert.NoError(t, ValidateExportFormat(types.ExportFormatNDJSON))
+	assert.Equal(t, internalErrors.ErrInvalidExportFormat, ValidateExportFormat("xml"))
+}
+
+func TestValidateImportMode(t *testing.T) {
+	assert.NoError(t, ValidateImportMode(types.ImportModeReplace))
+	assert.NoError(t, ValidateImportMode(types.ImportModeMerge))
+	assert.Equal(t, internalErrors.ErrInvalidImportMode, ValidateImportMode("append"))
+}
@@ -0,0 +1,12 @@
+package validators
+
+import "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+
+func ValidateInfluxPrecision(precision string) error {
+	switch precision {
+	case "", "ns", "n", "us", "u", "ms", "s":
+		return nil
+	default:
+		return errors.ErrInvalidInfluxPrecision
+	}
+}
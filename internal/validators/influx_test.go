@@ -0,0 +1,15 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateInfluxPrecision(t *testing.T) {
+	for _, precision := range []string{"", "ns", "n", "us", "u", "ms", "s"} {
+		assert.NoError(t, ValidateInfluxPrecision(precision), precision)
+	}
+	assert.Equal(t, internalErrors.ErrInvalidInfluxPrecision, ValidateInfluxPrecision("h"))
+}
@@ -42,6 +42,26 @@ func ValidateMetricPath(id string, mType string, value string) error {
 	return nil
 }
 
+func ValidateMetrics(metric types.Metrics) error {
+	err := ValidateMetricIDPath(metric.ID, metric.MType)
+	if err != nil {
+		return err
+	}
+
+	switch metric.MType {
+	case types.Counter:
+		if metric.Delta == nil {
+			return errors.ErrInvalidCounterValue
+		}
+	case types.Gauge:
+		if metric.Value == nil {
+			return errors.ErrInvalidGaugeValue
+		}
+	}
+
+	return nil
+}
+
 func HandleMetricsValidationError(err error) *types.APIError {
 	if err == nil {
 		return nil
@@ -56,11 +76,32 @@ func HandleMetricsValidationError(err error) *types.APIError {
 		}
 	case errors.ErrInvalidMetricType,
 		errors.ErrInvalidGaugeValue,
-		errors.ErrInvalidCounterValue:
+		errors.ErrInvalidCounterValue,
+		errors.ErrInvalidQueryMatch,
+		errors.ErrInvalidQueryAgg,
+		errors.ErrInvalidQueryGroupBy,
+		errors.ErrInvalidDumpFormat,
+		errors.ErrInvalidRestoreMode,
+		errors.ErrInvalidDump,
+		errors.ErrInvalidRefreshInterval,
+		errors.ErrInvalidExportFormat,
+		errors.ErrInvalidImportMode,
+		errors.ErrInvalidImport,
+		errors.ErrInvalidInfluxPrecision:
 		return &types.APIError{
 			Code:    http.StatusBadRequest,
 			Message: err.Error(),
 		}
+	case errors.ErrMetricForbidden:
+		return &types.APIError{
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		}
+	case errors.ErrStreamClosed:
+		return &types.APIError{
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		}
 	default:
 		return &types.APIError{
 			Code:    http.StatusInternalServerError,
@@ -92,6 +92,24 @@ func TestHandleMetricsValidationError(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantMsg:    internalErrors.ErrInvalidCounterValue.Error(),
 		},
+		{
+			name:       "ErrInvalidQueryAgg returns 400",
+			err:        internalErrors.ErrInvalidQueryAgg,
+			wantStatus: http.StatusBadRequest,
+			wantMsg:    internalErrors.ErrInvalidQueryAgg.Error(),
+		},
+		{
+			name:       "ErrStreamClosed returns 503",
+			err:        internalErrors.ErrStreamClosed,
+			wantStatus: http.StatusServiceUnavailable,
+			wantMsg:    internalErrors.ErrStreamClosed.Error(),
+		},
+		{
+			name:       "ErrMetricForbidden returns 403",
+			err:        internalErrors.ErrMetricForbidden,
+			wantStatus: http.StatusForbidden,
+			wantMsg:    internalErrors.ErrMetricForbidden.Error(),
+		},
 		{
 			name:       "unknown error returns 500",
 			err:        errors.New("some unknown error"),
@@ -0,0 +1,31 @@
+package validators
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func ValidateMetricsQuery(match string, mType string, agg string, by string) error {
+	if _, err := path.Match(match, ""); err != nil {
+		return errors.ErrInvalidQueryMatch
+	}
+
+	if mType != "" && mType != types.Counter && mType != types.Gauge {
+		return errors.ErrInvalidMetricType
+	}
+
+	switch agg {
+	case types.AggSum, types.AggAvg, types.AggMin, types.AggMax, types.AggCount:
+	default:
+		return errors.ErrInvalidQueryAgg
+	}
+
+	if strings.ContainsAny(by, "{}=,\\") {
+		return errors.ErrInvalidQueryGroupBy
+	}
+
+	return nil
+}
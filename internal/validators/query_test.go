@@ -0,0 +1,37 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetricsQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   string
+		mType   string
+		agg     string
+		by      string
+		wantErr error
+	}{
+		{"valid sum", "Heap*", "", types.AggSum, "", nil},
+		{"valid avg by type", "*", types.Gauge, types.AggAvg, types.GroupByType, nil},
+		{"valid count without match", "", "", types.AggCount, "", nil},
+		{"invalid match", "Heap[", "", types.AggSum, "", internalErrors.ErrInvalidQueryMatch},
+		{"invalid type", "*", "histogram", types.AggSum, "", internalErrors.ErrInvalidMetricType},
+		{"invalid agg", "*", "", "median", "", internalErrors.ErrInvalidQueryAgg},
+		{"missing agg", "*", "", "", "", internalErrors.ErrInvalidQueryAgg},
+		{"valid max by label", "cpu*", "", types.AggMax, "host", nil},
+		{"invalid group by", "*", "", types.AggMax, "host}", internalErrors.ErrInvalidQueryGroupBy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetricsQuery(tt.match, tt.mType, tt.agg, tt.by)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package validators
+
+import (
+	"path"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func ValidateMetricsStreamFilter(match string, mType string) error {
+	if _, err := path.Match(match, ""); err != nil {
+		return errors.ErrInvalidQueryMatch
+	}
+
+	if mType != "" && mType != types.Counter && mType != types.Gauge {
+		return errors.ErrInvalidMetricType
+	}
+
+	return nil
+}
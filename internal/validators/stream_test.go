@@ -0,0 +1,16 @@
+package validators
+
+import (
+	"testing"
+
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetricsStreamFilter(t *testing.T) {
+	assert.NoError(t, ValidateMetricsStreamFilter("", ""))
+	assert.NoError(t, ValidateMetricsStreamFilter("cpu_*", types.Gauge))
+	assert.Equal(t, internalErrors.ErrInvalidQueryMatch, ValidateMetricsStreamFilter("[", ""))
+	assert.Equal(t, internalErrors.ErrInvalidMetricType, ValidateMetricsStreamFilter("", "histogram"))
+}
@@ -2,109 +2,194 @@ package workers
 
 import (
 	"context"
-	"math/rand/v2"
-	"runtime"
+	"errors"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/collectors"
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 )
 
+// metricReportRetryDelays are the pauses before each retry of a batch
+// that failed because the server was unavailable.
+var metricReportRetryDelays = []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+
+// MetricUpdater sends a batch of metrics in a single request.
 type MetricUpdater interface {
-	Update(ctx context.Context, req types.MetricsUpdatePathRequest) error
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// MetricAgentTelemetry records the agent's own health for the telemetry
+// collector.
+type MetricAgentTelemetry interface {
+	SendAttempted()
+	SendFailed()
+	Retried()
+	Dropped(n int)
+	SetBufferDepth(n int)
+	ReportSucceeded(at time.Time)
+	CollectFinished(collector string, d time.Duration)
+}
+
+// MetricAgentReload is a new configuration for a running agent worker.
+type MetricAgentReload struct {
+	Updater        MetricUpdater
+	Collectors     []collectors.Collector
+	PollInterval   int
+	ReportInterval int
+	WorkerCount    int
 }
 
+// NewMetricAgentWorker polls metricCollectors and reports what they return
+// through updater. Each value received from reloads reconfigures the
+// running worker in place; metrics already buffered or queued are kept and
+// sent with the new settings.
 func NewMetricAgentWorker(
 	updater MetricUpdater,
+	metricCollectors []collectors.Collector,
+	telemetry MetricAgentTelemetry,
 	pollInterval int,
 	reportInterval int,
 	workerCount int,
+	reloads <-chan MetricAgentReload,
 ) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		return startMetricAgentWorker(ctx, updater, pollInterval, reportInterval, workerCount)
+		return startMetricAgentWorker(ctx, updater, metricCollectors, telemetry, pollInterval, reportInterval, workerCount, reloads)
 	}
 }
 
+type pollReload struct {
+	collectors   []collectors.Collector
+	pollInterval int
+}
+
+type reportReload struct {
+	updater        MetricUpdater
+	reportInterval int
+	workerCount    int
+}
+
 func startMetricAgentWorker(
 	ctx context.Context,
 	updater MetricUpdater,
+	metricCollectors []collectors.Collector,
+	telemetry MetricAgentTelemetry,
 	pollInterval, reportInterval, workerCount int,
+	reloads <-chan MetricAgentReload,
 ) error {
-	collectors := []func() []types.MetricsUpdatePathRequest{
-		collectRuntimeGaugeMetrics,
-		collectRuntimeCounterMetrics,
-	}
-	metricsCh := pollMetrics(ctx, pollInterval, collectors...)
-	errCh := reportMetrics(ctx, updater, reportInterval, workerCount, metricsCh)
-	return waitForContextOrError(ctx, errCh)
-}
+	pollReloads := make(chan pollReload)
+	reportReloads := make(chan reportReload)
 
-func collectRuntimeGaugeMetrics() []types.MetricsUpdatePathRequest {
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
-
-	return []types.MetricsUpdatePathRequest{
-		{MType: types.Gauge, Name: "Alloc", Value: floatToString(float64(memStats.Alloc))},
-		{MType: types.Gauge, Name: "BuckHashSys", Value: floatToString(float64(memStats.BuckHashSys))},
-		{MType: types.Gauge, Name: "Frees", Value: floatToString(float64(memStats.Frees))},
-		{MType: types.Gauge, Name: "GCCPUFraction", Value: floatToString(memStats.GCCPUFraction)},
-		{MType: types.Gauge, Name: "GCSys", Value: floatToString(float64(memStats.GCSys))},
-		{MType: types.Gauge, Name: "HeapAlloc", Value: floatToString(float64(memStats.HeapAlloc))},
-		{MType: types.Gauge, Name: "HeapIdle", Value: floatToString(float64(memStats.HeapIdle))},
-		{MType: types.Gauge, Name: "HeapInuse", Value: floatToString(float64(memStats.HeapInuse))},
-		{MType: types.Gauge, Name: "HeapObjects", Value: floatToString(float64(memStats.HeapObjects))},
-		{MType: types.Gauge, Name: "HeapReleased", Value: floatToString(float64(memStats.HeapReleased))},
-		{MType: types.Gauge, Name: "HeapSys", Value: floatToString(float64(memStats.HeapSys))},
-		{MType: types.Gauge, Name: "LastGC", Value: floatToString(float64(memStats.LastGC))},
-		{MType: types.Gauge, Name: "Lookups", Value: floatToString(float64(memStats.Lookups))},
-		{MType: types.Gauge, Name: "MCacheInuse", Value: floatToString(float64(memStats.MCacheInuse))},
-		{MType: types.Gauge, Name: "MCacheSys", Value: floatToString(float64(memStats.MCacheSys))},
-		{MType: types.Gauge, Name: "MSpanInuse", Value: floatToString(float64(memStats.MSpanInuse))},
-		{MType: types.Gauge, Name: "MSpanSys", Value: floatToString(float64(memStats.MSpanSys))},
-		{MType: types.Gauge, Name: "Mallocs", Value: floatToString(float64(memStats.Mallocs))},
-		{MType: types.Gauge, Name: "NextGC", Value: floatToString(float64(memStats.NextGC))},
-		{MType: types.Gauge, Name: "NumForcedGC", Value: floatToString(float64(memStats.NumForcedGC))},
-		{MType: types.Gauge, Name: "NumGC", Value: floatToString(float64(memStats.NumGC))},
-		{MType: types.Gauge, Name: "OtherSys", Value: floatToString(float64(memStats.OtherSys))},
-		{MType: types.Gauge, Name: "PauseTotalNs", Value: floatToString(float64(memStats.PauseTotalNs))},
-		{MType: types.Gauge, Name: "StackInuse", Value: floatToString(float64(memStats.StackInuse))},
-		{MType: types.Gauge, Name: "StackSys", Value: floatToString(float64(memStats.StackSys))},
-		{MType: types.Gauge, Name: "Sys", Value: floatToString(float64(memStats.Sys))},
-		{MType: types.Gauge, Name: "TotalAlloc", Value: floatToString(float64(memStats.TotalAlloc))},
-		{MType: types.Gauge, Name: "RandomValue", Value: floatToString(rand.Float64() * 100)},
-	}
-}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case reload, ok := <-reloads:
+				if !ok {
+					return
+				}
+				select {
+				case pollReloads <- pollReload{reload.Collectors, reload.PollInterval}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case reportReloads <- reportReload{reload.Updater, reload.ReportInterval, reload.WorkerCount}:
+				case <-ctx.Done():
+					return
+				}
+				logger.Log.Infow("Agent configuration reloaded",
+					"poll_interval", reload.PollInterval,
+					"report_interval", reload.ReportInterval,
+					"workers", reload.WorkerCount,
+					"collectors", len(reload.Collectors),
+				)
+			}
+		}
+	}()
 
-func collectRuntimeCounterMetrics() []types.MetricsUpdatePathRequest {
-	return []types.MetricsUpdatePathRequest{
-		{MType: types.Counter, Name: "PollCount", Value: intToString(1)},
-	}
+	metricsCh := pollMetrics(ctx, telemetry, pollInterval, pollReloads, metricCollectors...)
+	errCh := reportMetrics(ctx, updater, telemetry, reportInterval, workerCount, reportReloads, metricsCh)
+	return waitForContextOrError(ctx, errCh)
 }
 
+// pollMetrics runs every collector on its own interval, falling back to
+// pollInterval seconds. A failing collector is logged and polled again on
+// its next tick. On reload the pollers are replaced once they have handed
+// over what they already collected.
 func pollMetrics(
 	ctx context.Context,
+	telemetry MetricAgentTelemetry,
 	pollInterval int,
-	collectors ...func() []types.MetricsUpdatePathRequest,
+	reloads <-chan pollReload,
+	metricCollectors ...collectors.Collector,
 ) <-chan types.MetricsUpdatePathRequest {
 	out := make(chan types.MetricsUpdatePathRequest, 100)
 
+	var wg sync.WaitGroup
+
+	startPollers := func(pollCtx context.Context, pollInterval int, metricCollectors []collectors.Collector) {
+		for _, collector := range metricCollectors {
+			interval := collector.Interval()
+			if interval <= 0 {
+				interval = time.Duration(pollInterval) * time.Second
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-pollCtx.Done():
+						return
+					case <-ticker.C:
+						start := time.Now()
+						metrics, err := collector.Collect(ctx)
+						telemetry.CollectFinished(collector.Name(), time.Since(start))
+						if err != nil {
+							logger.Log.Warnw("Collector failed",
+								"collector", collector.Name(),
+								"error", err,
+							)
+							continue
+						}
+						for _, metric := range metrics {
+							select {
+							case out <- metric:
+							case <-ctx.Done():
+								return
+							}
+						}
+					}
+				}
+			}()
+		}
+	}
+
 	go func() {
 		defer close(out)
-		ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
-		defer ticker.Stop()
+
+		pollCtx, cancel := context.WithCancel(ctx)
+		startPollers(pollCtx, pollInterval, metricCollectors)
 
 		for {
 			select {
 			case <-ctx.Done():
+				cancel()
+				wg.Wait()
 				return
-			case <-ticker.C:
-				for _, collect := range collectors {
-					metrics := collect()
-					for _, metric := range metrics {
-						out <- metric
-					}
-				}
+			case reload := <-reloads:
+				cancel()
+				wg.Wait()
+				pollCtx, cancel = context.WithCancel(ctx)
+				startPollers(pollCtx, reload.pollInterval, reload.collectors)
 			}
 		}
 	}()
@@ -115,41 +200,36 @@ func pollMetrics(
 func reportMetrics(
 	ctx context.Context,
 	updater MetricUpdater,
+	telemetry MetricAgentTelemetry,
 	reportInterval int,
 	workerCount int,
+	reloads <-chan reportReload,
 	in <-chan types.MetricsUpdatePathRequest,
 ) <-chan error {
 	errCh := make(chan error, 100)
-	jobs := make(chan types.MetricsUpdatePathRequest, 100)
+	jobs := make(chan []types.Metrics, 100)
 
-	var wg sync.WaitGroup
-
-	worker := func() {
-		defer wg.Done()
-		for metric := range jobs {
-			if err := updater.Update(ctx, metric); err != nil {
-				errCh <- err
-			}
+	pool := &reportWorkerPool{updater: updater, jobs: jobs}
+	pool.work = func(updater MetricUpdater, batch []types.Metrics) {
+		if err := updateWithRetry(ctx, updater, telemetry, batch); err != nil {
+			telemetry.Dropped(len(batch))
+			errCh <- err
 		}
 	}
-
-	wg.Add(workerCount)
-	for i := 0; i < workerCount; i++ {
-		go worker()
-	}
+	pool.resize(workerCount)
 
 	go func() {
 		defer close(jobs)
 		ticker := time.NewTicker(time.Duration(reportInterval) * time.Second)
 		defer ticker.Stop()
 
-		var buffer []types.MetricsUpdatePathRequest
+		buffer := newMetricReportBuffer()
 
 		flush := func() {
-			for _, metric := range buffer {
-				jobs <- metric
+			if batch := buffer.drain(); len(batch) > 0 {
+				jobs <- batch
 			}
-			buffer = buffer[:0]
+			telemetry.SetBufferDepth(buffer.len())
 		}
 
 		for {
@@ -162,7 +242,21 @@ func reportMetrics(
 					flush()
 					return
 				}
-				buffer = append(buffer, metric)
+				if err := buffer.add(metric); err != nil {
+					logger.Log.Warnw("Dropping invalid metric",
+						"metric", metric.Name,
+						"type", metric.MType,
+						"value", metric.Value,
+						"error", err,
+					)
+					telemetry.Dropped(1)
+					continue
+				}
+				telemetry.SetBufferDepth(buffer.len())
+			case reload := <-reloads:
+				pool.setUpdater(reload.updater)
+				pool.resize(reload.workerCount)
+				ticker.Reset(time.Duration(reload.reportInterval) * time.Second)
 			case <-ticker.C:
 				flush()
 			}
@@ -170,13 +264,178 @@ func reportMetrics(
 	}()
 
 	go func() {
-		wg.Wait()
+		pool.wg.Wait()
 		close(errCh)
 	}()
 
 	return errCh
 }
 
+// reportWorkerPool sends queued batches with a resizable number of workers.
+// A worker retires after its current job once the pool has shrunk, so no
+// queued batch is abandoned.
+type reportWorkerPool struct {
+	mu      sync.Mutex
+	updater MetricUpdater
+	target  int
+	running int
+	wg      sync.WaitGroup
+
+	jobs <-chan []types.Metrics
+	work func(updater MetricUpdater, batch []types.Metrics)
+}
+
+func (p *reportWorkerPool) resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.target = max(n, 1)
+	for p.running < p.target {
+		p.running++
+		p.wg.Add(1)
+		go p.run()
+	}
+}
+
+func (p *reportWorkerPool) setUpdater(updater MetricUpdater) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updater = updater
+}
+
+func (p *reportWorkerPool) run() {
+	defer p.wg.Done()
+	for batch := range p.jobs {
+		p.mu.Lock()
+		updater := p.updater
+		p.mu.Unlock()
+
+		p.work(updater, batch)
+
+		if p.retire() {
+			return
+		}
+	}
+}
+
+func (p *reportWorkerPool) retire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running > p.target {
+		p.running--
+		return true
+	}
+	return false
+}
+
+// updateWithRetry sends batch, retrying with metricReportRetryDelays while
+// the server is unavailable. Other errors are returned immediately.
+func updateWithRetry(
+	ctx context.Context,
+	updater MetricUpdater,
+	telemetry MetricAgentTelemetry,
+	batch []types.Metrics,
+) error {
+	update := func() error {
+		telemetry.SendAttempted()
+		if err := updater.Update(ctx, batch); err != nil {
+			telemetry.SendFailed()
+			return err
+		}
+		telemetry.ReportSucceeded(time.Now())
+		return nil
+	}
+
+	err := update()
+	for _, delay := range metricReportRetryDelays {
+		if err == nil || !errors.Is(err, internalErrors.ErrServerUnavailable) {
+			return err
+		}
+
+		logger.Log.Warnw("Retrying metric batch",
+			"metrics", len(batch),
+			"delay", delay,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		telemetry.Retried()
+		err = update()
+	}
+	return err
+}
+
+// metricReportBuffer aggregates polled metrics between reports: a gauge
+// keeps only its latest value and a counter the sum of its deltas, so each
+// report carries one entry per metric.
+type metricReportBuffer struct {
+	order   []types.MetricID
+	metrics map[types.MetricID]types.Metrics
+}
+
+func newMetricReportBuffer() *metricReportBuffer {
+	return &metricReportBuffer{
+		metrics: make(map[types.MetricID]types.Metrics),
+	}
+}
+
+// add buffers metric. A value that does not parse is rejected, since it
+// would make the server refuse the whole batch.
+func (b *metricReportBuffer) add(metric types.MetricsUpdatePathRequest) error {
+	id := types.MetricID{ID: metric.Name, MType: metric.MType}
+	next := types.Metrics{ID: metric.Name, MType: metric.MType}
+
+	switch metric.MType {
+	case types.Counter:
+		delta, err := strconv.ParseInt(metric.Value, 10, 64)
+		if err != nil {
+			return internalErrors.ErrInvalidCounterValue
+		}
+		if prev, ok := b.metrics[id]; ok {
+			delta += *prev.Delta
+		}
+		next.Delta = &delta
+	case types.Gauge:
+		value, err := strconv.ParseFloat(metric.Value, 64)
+		if err != nil {
+			return internalErrors.ErrInvalidGaugeValue
+		}
+		next.Value = &value
+	default:
+		return internalErrors.ErrInvalidMetricType
+	}
+
+	if _, ok := b.metrics[id]; !ok {
+		b.order = append(b.order, id)
+	}
+	b.metrics[id] = next
+	return nil
+}
+
+func (b *metricReportBuffer) len() int {
+	return len(b.order)
+}
+
+// drain returns the aggregated metrics in first-seen order and empties the
+// buffer.
+func (b *metricReportBuffer) drain() []types.Metrics {
+	out := make([]types.Metrics, 0, b.len())
+	for _, id := range b.order {
+		out = append(out, b.metrics[id])
+	}
+
+	b.order = b.order[:0]
+	clear(b.metrics)
+
+	return out
+}
+
 func waitForContextOrError(ctx context.Context, errCh <-chan error) error {
 	for {
 		select {
@@ -192,11 +451,3 @@ func waitForContextOrError(ctx context.Context, errCh <-chan error) error {
 		}
 	}
 }
-
-func intToString(i int64) string {
-	return strconv.FormatInt(i, 10)
-}
-
-func floatToString(f float64) string {
-	return strconv.FormatFloat(f, 'f', -1, 64)
-}
@@ -7,6 +7,7 @@ package workers
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
@@ -36,15 +37,122 @@ func (m *MockMetricUpdater) EXPECT() *MockMetricUpdaterMockRecorder {
 }
 
 // Update mocks base method.
-func (m *MockMetricUpdater) Update(ctx context.Context, req types.MetricsUpdatePathRequest) error {
+func (m *MockMetricUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, req)
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockMetricUpdaterMockRecorder) Update(ctx, req interface{}) *gomock.Call {
+func (mr *MockMetricUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricUpdater)(nil).Update), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMetricUpdater)(nil).Update), ctx, metrics)
+}
+
+// MockMetricAgentTelemetry is a mock of MetricAgentTelemetry interface.
+type MockMetricAgentTelemetry struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricAgentTelemetryMockRecorder
+}
+
+// MockMetricAgentTelemetryMockRecorder is the mock recorder for MockMetricAgentTelemetry.
+type MockMetricAgentTelemetryMockRecorder struct {
+	mock *MockMetricAgentTelemetry
+}
+
+// NewMockMetricAgentTelemetry creates a new mock instance.
+func NewMockMetricAgentTelemetry(ctrl *gomock.Controller) *MockMetricAgentTelemetry {
+	mock := &MockMetricAgentTelemetry{ctrl: ctrl}
+	mock.recorder = &MockMetricAgentTelemetryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricAgentTelemetry) EXPECT() *MockMetricAgentTelemetryMockRecorder {
+	return m.recorder
+}
+
+// CollectFinished mocks base method.
+func (m *MockMetricAgentTelemetry) CollectFinished(collector string, d time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectFinished", collector, d)
+}
+
+// CollectFinished indicates an expected call of CollectFinished.
+func (mr *MockMetricAgentTelemetryMockRecorder) CollectFinished(collector, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectFinished", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).CollectFinished), collector, d)
+}
+
+// Dropped mocks base method.
+func (m *MockMetricAgentTelemetry) Dropped(n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Dropped", n)
+}
+
+// Dropped indicates an expected call of Dropped.
+func (mr *MockMetricAgentTelemetryMockRecorder) Dropped(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dropped", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).Dropped), n)
+}
+
+// ReportSucceeded mocks base method.
+func (m *MockMetricAgentTelemetry) ReportSucceeded(at time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportSucceeded", at)
+}
+
+// ReportSucceeded indicates an expected call of ReportSucceeded.
+func (mr *MockMetricAgentTelemetryMockRecorder) ReportSucceeded(at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSucceeded", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).ReportSucceeded), at)
+}
+
+// Retried mocks base method.
+func (m *MockMetricAgentTelemetry) Retried() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Retried")
+}
+
+// Retried indicates an expected call of Retried.
+func (mr *MockMetricAgentTelemetryMockRecorder) Retried() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retried", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).Retried))
+}
+
+// SendAttempted mocks base method.
+func (m *MockMetricAgentTelemetry) SendAttempted() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendAttempted")
+}
+
+// SendAttempted indicates an expected call of SendAttempted.
+func (mr *MockMetricAgentTelemetryMockRecorder) SendAttempted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAttempted", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).SendAttempted))
+}
+
+// SendFailed mocks base method.
+func (m *MockMetricAgentTelemetry) SendFailed() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendFailed")
+}
+
+// SendFailed indicates an expected call of SendFailed.
+func (mr *MockMetricAgentTelemetryMockRecorder) SendFailed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendFailed", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).SendFailed))
+}
+
+// SetBufferDepth mocks base method.
+func (m *MockMetricAgentTelemetry) SetBufferDepth(n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBufferDepth", n)
+}
+
+// SetBufferDepth indicates an expected call of SetBufferDepth.
+func (mr *MockMetricAgentTelemetryMockRecorder) SetBufferDepth(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBufferDepth", reflect.TypeOf((*MockMetricAgentTelemetry)(nil).SetBufferDepth), n)
 }
@@ -3,37 +3,40 @@ package workers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/collectors"
+	internalErrors "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/errors"
 	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// Test collectRuntimeGaugeMetrics returns expected metrics with correct types and values.
-func TestCollectRuntimeGaugeMetrics(t *testing.T) {
-	metrics := collectRuntimeGaugeMetrics()
+type stubCollector struct {
+	name     string
+	interval time.Duration
+	collect  func() ([]types.MetricsUpdatePathRequest, error)
+}
 
-	assert.NotEmpty(t, metrics)
-	for _, m := range metrics {
-		assert.Equal(t, types.Gauge, m.MType)
-		assert.NotEmpty(t, m.Name)
-		assert.NotEmpty(t, m.Value)
-	}
+func reportedGauge(id string, value float64) types.Metrics {
+	return types.Metrics{ID: id, MType: types.Gauge, Value: &value}
 }
 
-// Test collectRuntimeCounterMetrics returns expected metric.
-func TestCollectRuntimeCounterMetrics(t *testing.T) {
-	metrics := collectRuntimeCounterMetrics()
+func reportedCounter(id string, delta int64) types.Metrics {
+	return types.Metrics{ID: id, MType: types.Counter, Delta: &delta}
+}
 
-	assert.Len(t, metrics, 1)
-	m := metrics[0]
-	assert.Equal(t, types.Counter, m.MType)
-	assert.Equal(t, "PollCount", m.Name)
-	assert.Equal(t, "1", m.Value)
+func (c *stubCollector) Name() string            { return c.name }
+func (c *stubCollector) Interval() time.Duration { return c.interval }
+func (c *stubCollector) Collect(context.Context) ([]types.MetricsUpdatePathRequest, error) {
+	return c.collect()
 }
 
 // Test pollMetrics emits metrics periodically until context canceled.
@@ -42,15 +45,18 @@ func TestPollMetrics(t *testing.T) {
 	defer cancel()
 
 	pollInterval := 1
-	collectors := []func() []types.MetricsUpdatePathRequest{
-		func() []types.MetricsUpdatePathRequest {
-			return []types.MetricsUpdatePathRequest{
-				{MType: types.Counter, Name: "test_metric", Value: "42"},
-			}
+	metricCollectors := []collectors.Collector{
+		&stubCollector{
+			name: "test",
+			collect: func() ([]types.MetricsUpdatePathRequest, error) {
+				return []types.MetricsUpdatePathRequest{
+					{MType: types.Counter, Name: "test_metric", Value: "42"},
+				}, nil
+			},
 		},
 	}
 
-	ch := pollMetrics(ctx, pollInterval, collectors...)
+	ch := pollMetrics(ctx, collectors.NewAgentTelemetry(), pollInterval, nil, metricCollectors...)
 
 	// Read first metric, assert correctness
 	select {
@@ -67,6 +73,42 @@ func TestPollMetrics(t *testing.T) {
 	assert.False(t, ok)
 }
 
+// Test pollMetrics honours per-collector intervals and skips failing collectors.
+func TestPollMetrics_CollectorIntervalsAndErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	metricCollectors := []collectors.Collector{
+		&stubCollector{
+			name:     "fast",
+			interval: 50 * time.Millisecond,
+			collect: func() ([]types.MetricsUpdatePathRequest, error) {
+				return []types.MetricsUpdatePathRequest{{MType: types.Gauge, Name: "fast", Value: "1"}}, nil
+			},
+		},
+		&stubCollector{
+			name:     "broken",
+			interval: 50 * time.Millisecond,
+			collect: func() ([]types.MetricsUpdatePathRequest, error) {
+				return nil, errors.New("source unavailable")
+			},
+		},
+	}
+
+	// The default poll interval is far longer than the test: only the
+	// collector's own interval can produce metrics in time.
+	ch := pollMetrics(ctx, collectors.NewAgentTelemetry(), 60, nil, metricCollectors...)
+
+	for range 3 {
+		select {
+		case metric := <-ch:
+			assert.Equal(t, "fast", metric.Name)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for metric")
+		}
+	}
+}
+
 func TestReportMetrics(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -87,11 +129,13 @@ func TestReportMetrics(t *testing.T) {
 	}
 	close(inCh)
 
-	for _, m := range metrics {
-		mockUpdater.EXPECT().Update(gomock.Any(), m).Return(nil).Times(1)
-	}
+	mockUpdater.EXPECT().Update(gomock.Any(), []types.Metrics{
+		reportedCounter("m1", 1),
+		reportedCounter("m2", 2),
+		reportedCounter("m3", 3),
+	}).Return(nil).Times(1)
 
-	errCh := reportMetrics(ctx, mockUpdater, 1, 2, inCh)
+	errCh := reportMetrics(ctx, mockUpdater, collectors.NewAgentTelemetry(), 1, 2, nil, inCh)
 
 	// Wait a bit more than the reportInterval to let metrics flush
 	time.Sleep(1100 * time.Millisecond)
@@ -103,6 +147,61 @@ func TestReportMetrics(t *testing.T) {
 	}
 }
 
+// Test reportMetrics sends one batch per interval with one aggregated entry
+// per metric.
+func TestReportMetricsAggregates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := NewMockMetricUpdater(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inCh := make(chan types.MetricsUpdatePathRequest, 10)
+	for i := 1; i <= 5; i++ {
+		inCh <- types.MetricsUpdatePathRequest{MType: types.Gauge, Name: "Alloc", Value: strconv.Itoa(i * 100)}
+		inCh <- types.MetricsUpdatePathRequest{MType: types.Counter, Name: "PollCount", Value: "1"}
+	}
+	close(inCh)
+
+	mockUpdater.EXPECT().Update(gomock.Any(), []types.Metrics{
+		reportedGauge("Alloc", 500),
+		reportedCounter("PollCount", 5),
+	}).Return(nil).Times(1)
+
+	errCh := reportMetrics(ctx, mockUpdater, collectors.NewAgentTelemetry(), 1, 1, nil, inCh)
+
+	for err := range errCh {
+		assert.NoError(t, err)
+	}
+}
+
+func TestMetricReportBuffer(t *testing.T) {
+	buffer := newMetricReportBuffer()
+
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Counter, Name: "hits", Value: "2"}))
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Gauge, Name: "temp", Value: "1.5"}))
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Counter, Name: "hits", Value: "-1"}))
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Gauge, Name: "hits", Value: "7"}))
+	assert.ErrorIs(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Counter, Name: "bad", Value: "x"}), internalErrors.ErrInvalidCounterValue)
+	assert.ErrorIs(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Gauge, Name: "bad", Value: "x"}), internalErrors.ErrInvalidGaugeValue)
+	assert.ErrorIs(t, buffer.add(types.MetricsUpdatePathRequest{MType: "histogram", Name: "bad", Value: "1"}), internalErrors.ErrInvalidMetricType)
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Gauge, Name: "temp", Value: "2.5"}))
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Counter, Name: "hits", Value: "10"}))
+
+	assert.Equal(t, []types.Metrics{
+		reportedCounter("hits", 11),
+		reportedGauge("temp", 2.5),
+		reportedGauge("hits", 7),
+	}, buffer.drain())
+
+	assert.Empty(t, buffer.drain())
+
+	require.NoError(t, buffer.add(types.MetricsUpdatePathRequest{MType: types.Counter, Name: "hits", Value: "1"}))
+	assert.Equal(t, []types.Metrics{reportedCounter("hits", 1)}, buffer.drain())
+}
+
 // Test reportMetrics propagates errors from updater.
 func TestReportMetricsWithError(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -119,9 +218,9 @@ func TestReportMetricsWithError(t *testing.T) {
 
 	expectedErr := errors.New("update failed")
 
-	mockUpdater.EXPECT().Update(gomock.Any(), metric).Return(expectedErr)
+	mockUpdater.EXPECT().Update(gomock.Any(), []types.Metrics{reportedCounter("errMetric", 1)}).Return(expectedErr)
 
-	errCh := reportMetrics(ctx, mockUpdater, 1, 1, inCh)
+	errCh := reportMetrics(ctx, mockUpdater, collectors.NewAgentTelemetry(), 1, 1, nil, inCh)
 
 	select {
 	case err := <-errCh:
@@ -131,6 +230,63 @@ func TestReportMetricsWithError(t *testing.T) {
 	}
 }
 
+func TestUpdateWithRetry(t *testing.T) {
+	delays := metricReportRetryDelays
+	metricReportRetryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { metricReportRetryDelays = delays }()
+
+	batch := []types.Metrics{reportedGauge("Alloc", 1)}
+	unavailable := fmt.Errorf("%w: server returned status 503: ", internalErrors.ErrServerUnavailable)
+
+	tests := []struct {
+		name      string
+		results   []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "succeeds after retries", results: []error{unavailable, unavailable, nil}, wantCalls: 3},
+		{name: "gives up after the last retry", results: []error{unavailable, unavailable, unavailable}, wantCalls: 3, wantErr: unavailable},
+		{name: "client errors are not retried", results: []error{errors.New("bad request")}, wantCalls: 1, wantErr: errors.New("bad request")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUpdater := NewMockMetricUpdater(ctrl)
+			calls := 0
+			mockUpdater.EXPECT().Update(gomock.Any(), batch).DoAndReturn(
+				func(context.Context, []types.Metrics) error {
+					err := tt.results[calls]
+					calls++
+					return err
+				}).Times(tt.wantCalls)
+
+			failures := 0
+			for _, err := range tt.results {
+				if err != nil {
+					failures++
+				}
+			}
+			successes := len(tt.results) - failures
+
+			mockTelemetry := NewMockMetricAgentTelemetry(ctrl)
+			mockTelemetry.EXPECT().SendAttempted().Times(tt.wantCalls)
+			mockTelemetry.EXPECT().SendFailed().Times(failures)
+			mockTelemetry.EXPECT().Retried().Times(tt.wantCalls - 1)
+			mockTelemetry.EXPECT().ReportSucceeded(gomock.Any()).Times(successes)
+
+			err := updateWithRetry(context.Background(), mockUpdater, mockTelemetry, batch)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr.Error())
+			}
+		})
+	}
+}
+
 // Test NewMetricAgentWorker runs and respects context cancellation.
 func TestNewMetricAgentWorker(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -144,7 +300,11 @@ func TestNewMetricAgentWorker(t *testing.T) {
 	// Expect updater.Update to be called at least once
 	mockUpdater.EXPECT().Update(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
 
-	worker := NewMetricAgentWorker(mockUpdater, 1, 1, 2)
+	worker := NewMetricAgentWorker(mockUpdater, []collectors.Collector{
+		&stubCollector{name: "test", collect: func() ([]types.MetricsUpdatePathRequest, error) {
+			return []types.MetricsUpdatePathRequest{{MType: types.Gauge, Name: "g", Value: "1"}}, nil
+		}},
+	}, collectors.NewAgentTelemetry(), 1, 1, 2, nil)
 
 	doneCh := make(chan struct{})
 	go func() {
@@ -160,6 +320,86 @@ func TestNewMetricAgentWorker(t *testing.T) {
 	}
 }
 
+func TestNewMetricAgentWorker_Reload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oldUpdater := NewMockMetricUpdater(ctrl)
+	oldUpdater.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+	var (
+		mu       sync.Mutex
+		reported = map[string]float64{}
+	)
+	newUpdater := NewMockMetricUpdater(ctrl)
+	newUpdater.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch []types.Metrics) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, metric := range batch {
+				reported[metric.ID] = *metric.Value
+			}
+			return nil
+		}).AnyTimes()
+
+	collector := func(name string) collectors.Collector {
+		return &stubCollector{name: name, interval: 10 * time.Millisecond, collect: func() ([]types.MetricsUpdatePathRequest, error) {
+			return []types.MetricsUpdatePathRequest{{MType: types.Gauge, Name: name, Value: "1"}}, nil
+		}}
+	}
+
+	reloads := make(chan MetricAgentReload)
+	worker := NewMetricAgentWorker(oldUpdater, []collectors.Collector{collector("before")},
+		collectors.NewAgentTelemetry(), 1, 60, 1, reloads)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	reloads <- MetricAgentReload{
+		Updater:        newUpdater,
+		Collectors:     []collectors.Collector{collector("after")},
+		PollInterval:   1,
+		ReportInterval: 1,
+		WorkerCount:    3,
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return reported["before"] == 1 && reported["after"] == 1
+	}, 3*time.Second, 20*time.Millisecond, "metrics buffered before the reload are sent with the new settings")
+}
+
+func TestReportWorkerPool_Resize(t *testing.T) {
+	jobs := make(chan []types.Metrics)
+	var handled atomic.Int32
+
+	pool := &reportWorkerPool{jobs: jobs}
+	pool.work = func(MetricUpdater, []types.Metrics) { handled.Add(1) }
+
+	pool.resize(3)
+	assert.Equal(t, 3, pool.running)
+
+	pool.resize(1)
+	for range 5 {
+		jobs <- nil
+	}
+	require.Eventually(t, func() bool {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return pool.running == 1
+	}, time.Second, 10*time.Millisecond)
+
+	pool.resize(0)
+	assert.Equal(t, 1, pool.running, "the pool keeps at least one worker")
+
+	close(jobs)
+	pool.wg.Wait()
+	assert.Equal(t, int32(5), handled.Load())
+}
+
 func TestWaitForContextOrError(t *testing.T) {
 	t.Run("returns context error when context is done", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,50 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+const forwardShutdownTimeout = 5 * time.Second
+
+type MetricForwardFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// NewMetricForwardWorker flushes forwarded metrics every flushInterval
+// seconds and once more on shutdown, giving upstreams forwardShutdownTimeout
+// to accept what is still queued.
+func NewMetricForwardWorker(
+	flusher MetricForwardFlusher,
+	flushInterval int,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return startMetricForwardWorker(ctx, flusher, flushInterval)
+	}
+}
+
+func startMetricForwardWorker(
+	ctx context.Context,
+	flusher MetricForwardFlusher,
+	flushInterval int,
+) error {
+	ticker := time.NewTicker(time.Duration(max(flushInterval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), forwardShutdownTimeout)
+			defer cancel()
+
+			if err := flusher.Flush(flushCtx); err != nil {
+				logger.Log.Errorw("Metrics left unforwarded on shutdown", "error", err)
+			}
+			return nil
+		case <-ticker.C:
+			flusher.Flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/workers/metric_forward.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMetricForwardFlusher is a mock of MetricForwardFlusher interface.
+type MockMetricForwardFlusher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricForwardFlusherMockRecorder
+}
+
+// MockMetricForwardFlusherMockRecorder is the mock recorder for MockMetricForwardFlusher.
+type MockMetricForwardFlusherMockRecorder struct {
+	mock *MockMetricForwardFlusher
+}
+
+// NewMockMetricForwardFlusher creates a new mock instance.
+func NewMockMetricForwardFlusher(ctrl *gomock.Controller) *MockMetricForwardFlusher {
+	mock := &MockMetricForwardFlusher{ctrl: ctrl}
+	mock.recorder = &MockMetricForwardFlusherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricForwardFlusher) EXPECT() *MockMetricForwardFlusherMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockMetricForwardFlusher) Flush(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockMetricForwardFlusherMockRecorder) Flush(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockMetricForwardFlusher)(nil).Flush), ctx)
+}
@@ -0,0 +1,33 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricForwardWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFlusher := NewMockMetricForwardFlusher(ctrl)
+
+	gomock.InOrder(
+		mockFlusher.EXPECT().Flush(gomock.Any()).Return(errors.New("upstream down")).Times(1),
+		mockFlusher.EXPECT().Flush(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			// The final flush must not inherit the cancelled context.
+			require.NoError(t, ctx.Err())
+			return errors.New("upstream down")
+		}).Times(1),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	err := NewMetricForwardWorker(mockFlusher, 1)(ctx)
+	require.NoError(t, err)
+}
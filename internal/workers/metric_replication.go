@@ -0,0 +1,66 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+const (
+	replicationRetryInterval = time.Second
+	replicationLagInterval   = time.Second
+)
+
+type MetricReplicator interface {
+	Sync(ctx context.Context) error
+	ReportLag(ctx context.Context) error
+}
+
+// NewMetricReplicationWorker keeps a replica synchronised with its leader,
+// reconnecting after replicationRetryInterval whenever the sync ends, and
+// reports the replication lag every replicationLagInterval.
+func NewMetricReplicationWorker(
+	replicator MetricReplicator,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return startMetricReplicationWorker(ctx, replicator)
+	}
+}
+
+func startMetricReplicationWorker(
+	ctx context.Context,
+	replicator MetricReplicator,
+) error {
+	go reportReplicationLag(ctx, replicator)
+
+	for {
+		err := replicator.Sync(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		logger.Log.Warnw("Replication interrupted, reconnecting", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(replicationRetryInterval):
+		}
+	}
+}
+
+func reportReplicationLag(ctx context.Context, replicator MetricReplicator) {
+	ticker := time.NewTicker(replicationLagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := replicator.ReportLag(ctx); err != nil {
+				logger.Log.Errorw("Failed to report replication lag", "error", err)
+			}
+		}
+	}
+}
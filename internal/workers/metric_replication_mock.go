@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/workers/metric_replication.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMetricReplicator is a mock of MetricReplicator interface.
+type MockMetricReplicator struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricReplicatorMockRecorder
+}
+
+// MockMetricReplicatorMockRecorder is the mock recorder for MockMetricReplicator.
+type MockMetricReplicatorMockRecorder struct {
+	mock *MockMetricReplicator
+}
+
+// NewMockMetricReplicator creates a new mock instance.
+func NewMockMetricReplicator(ctrl *gomock.Controller) *MockMetricReplicator {
+	mock := &MockMetricReplicator{ctrl: ctrl}
+	mock.recorder = &MockMetricReplicatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricReplicator) EXPECT() *MockMetricReplicatorMockRecorder {
+	return m.recorder
+}
+
+// ReportLag mocks base method.
+func (m *MockMetricReplicator) ReportLag(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportLag", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportLag indicates an expected call of ReportLag.
+func (mr *MockMetricReplicatorMockRecorder) ReportLag(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportLag", reflect.TypeOf((*MockMetricReplicator)(nil).ReportLag), ctx)
+}
+
+// Sync mocks base method.
+func (m *MockMetricReplicator) Sync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockMetricReplicatorMockRecorder) Sync(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockMetricReplicator)(nil).Sync), ctx)
+}
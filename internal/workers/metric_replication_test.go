@@ -0,0 +1,33 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricReplicationWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReplicator := NewMockMetricReplicator(ctrl)
+
+	gomock.InOrder(
+		mockReplicator.EXPECT().Sync(gomock.Any()).Return(errors.New("leader unavailable")).Times(1),
+		mockReplicator.EXPECT().Sync(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).Times(1),
+	)
+	mockReplicator.EXPECT().ReportLag(gomock.Any()).Return(nil).MinTimes(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+
+	err := NewMetricReplicationWorker(mockReplicator)(ctx)
+	require.NoError(t, err)
+}
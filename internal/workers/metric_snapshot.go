@@ -0,0 +1,47 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+type MetricSnapshotter interface {
+	Snapshot(ctx context.Context) error
+}
+
+func NewMetricSnapshotWorker(
+	snapshotter MetricSnapshotter,
+	storeInterval int,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return startMetricSnapshotWorker(ctx, snapshotter, storeInterval)
+	}
+}
+
+func startMetricSnapshotWorker(
+	ctx context.Context,
+	snapshotter MetricSnapshotter,
+	storeInterval int,
+) error {
+	var tick <-chan time.Time
+	if storeInterval > 0 {
+		ticker := time.NewTicker(time.Duration(storeInterval) * time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The run context is already cancelled, so the final snapshot
+			// gets its own context to be able to finish.
+			return snapshotter.Snapshot(context.Background())
+		case <-tick:
+			if err := snapshotter.Snapshot(ctx); err != nil {
+				logger.Log.Errorw("Failed to snapshot metrics", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/workers/metric_snapshot.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMetricSnapshotter is a mock of MetricSnapshotter interface.
+type MockMetricSnapshotter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricSnapshotterMockRecorder
+}
+
+// MockMetricSnapshotterMockRecorder is the mock recorder for MockMetricSnapshotter.
+type MockMetricSnapshotterMockRecorder struct {
+	mock *MockMetricSnapshotter
+}
+
+// NewMockMetricSnapshotter creates a new mock instance.
+func NewMockMetricSnapshotter(ctrl *gomock.Controller) *MockMetricSnapshotter {
+	mock := &MockMetricSnapshotter{ctrl: ctrl}
+	mock.recorder = &MockMetricSnapshotterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricSnapshotter) EXPECT() *MockMetricSnapshotterMockRecorder {
+	return m.recorder
+}
+
+// Snapshot mocks base method.
+func (m *MockMetricSnapshotter) Snapshot(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockMetricSnapshotterMockRecorder) Snapshot(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockMetricSnapshotter)(nil).Snapshot), ctx)
+}
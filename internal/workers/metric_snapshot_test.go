@@ -0,0 +1,43 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricSnapshotWorker_PeriodicAndFinalSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSnapshotter := NewMockMetricSnapshotter(ctrl)
+
+	// At least one periodic snapshot plus the final one on shutdown.
+	mockSnapshotter.EXPECT().Snapshot(gomock.Any()).Return(nil).MinTimes(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	err := NewMetricSnapshotWorker(mockSnapshotter, 1)(ctx)
+	require.NoError(t, err)
+}
+
+func TestMetricSnapshotWorker_ZeroIntervalSnapshotsOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSnapshotter := NewMockMetricSnapshotter(ctrl)
+	expectedErr := errors.New("disk full")
+	mockSnapshotter.EXPECT().Snapshot(gomock.Any()).Return(expectedErr).Times(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := NewMetricSnapshotWorker(mockSnapshotter, 0)(ctx)
+	assert.ErrorIs(t, err, expectedErr)
+}
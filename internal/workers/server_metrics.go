@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+type ServerMetricsSource interface {
+	Metrics() []types.Metrics
+}
+
+type ServerMetricsUpdater interface {
+	Update(ctx context.Context, metrics []types.Metrics) error
+}
+
+// NewServerMetricsWorker stores the server's own metrics from source every
+// reportInterval seconds, so they can be read like any other metric.
+func NewServerMetricsWorker(
+	source ServerMetricsSource,
+	updater ServerMetricsUpdater,
+	reportInterval int,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return startServerMetricsWorker(ctx, source, updater, reportInterval)
+	}
+}
+
+func startServerMetricsWorker(
+	ctx context.Context,
+	source ServerMetricsSource,
+	updater ServerMetricsUpdater,
+	reportInterval int,
+) error {
+	ticker := time.NewTicker(time.Duration(max(reportInterval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			metrics := source.Metrics()
+			if len(metrics) == 0 {
+				continue
+			}
+			if err := updater.Update(ctx, metrics); err != nil {
+				logger.Log.Errorw("Failed to store server metrics", "error", err)
+			}
+		}
+	}
+}
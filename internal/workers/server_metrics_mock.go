@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/workers/server_metrics.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+// MockServerMetricsSource is a mock of ServerMetricsSource interface.
+type MockServerMetricsSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockServerMetricsSourceMockRecorder
+}
+
+// MockServerMetricsSourceMockRecorder is the mock recorder for MockServerMetricsSource.
+type MockServerMetricsSourceMockRecorder struct {
+	mock *MockServerMetricsSource
+}
+
+// NewMockServerMetricsSource creates a new mock instance.
+func NewMockServerMetricsSource(ctrl *gomock.Controller) *MockServerMetricsSource {
+	mock := &MockServerMetricsSource{ctrl: ctrl}
+	mock.recorder = &MockServerMetricsSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServerMetricsSource) EXPECT() *MockServerMetricsSourceMockRecorder {
+	return m.recorder
+}
+
+// Metrics mocks base method.
+func (m *MockServerMetricsSource) Metrics() []types.Metrics {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metrics")
+	ret0, _ := ret[0].([]types.Metrics)
+	return ret0
+}
+
+// Metrics indicates an expected call of Metrics.
+func (mr *MockServerMetricsSourceMockRecorder) Metrics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metrics", reflect.TypeOf((*MockServerMetricsSource)(nil).Metrics))
+}
+
+// MockServerMetricsUpdater is a mock of ServerMetricsUpdater interface.
+type MockServerMetricsUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockServerMetricsUpdaterMockRecorder
+}
+
+// MockServerMetricsUpdaterMockRecorder is the mock recorder for MockServerMetricsUpdater.
+type MockServerMetricsUpdaterMockRecorder struct {
+	mock *MockServerMetricsUpdater
+}
+
+// NewMockServerMetricsUpdater creates a new mock instance.
+func NewMockServerMetricsUpdater(ctrl *gomock.Controller) *MockServerMetricsUpdater {
+	mock := &MockServerMetricsUpdater{ctrl: ctrl}
+	mock.recorder = &MockServerMetricsUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServerMetricsUpdater) EXPECT() *MockServerMetricsUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method.
+func (m *MockServerMetricsUpdater) Update(ctx context.Context, metrics []types.Metrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockServerMetricsUpdaterMockRecorder) Update(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockServerMetricsUpdater)(nil).Update), ctx, metrics)
+}
@@ -0,0 +1,37 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+func TestServerMetricsWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSource := NewMockServerMetricsSource(ctrl)
+	mockUpdater := NewMockServerMetricsUpdater(ctrl)
+
+	delta := int64(3)
+	metrics := []types.Metrics{{ID: "server_shed_requests", MType: types.Counter, Delta: &delta}}
+
+	gomock.InOrder(
+		mockSource.EXPECT().Metrics().Return(nil),
+		mockSource.EXPECT().Metrics().Return(metrics),
+		mockUpdater.EXPECT().Update(gomock.Any(), metrics).Return(errors.New("save failed")),
+		mockSource.EXPECT().Metrics().Return(metrics).AnyTimes(),
+		mockUpdater.EXPECT().Update(gomock.Any(), metrics).Return(nil).AnyTimes(),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, NewServerMetricsWorker(mockSource, mockUpdater, 1)(ctx))
+}
@@ -0,0 +1,41 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/logger"
+)
+
+type WALSyncer interface {
+	Sync() error
+}
+
+func NewWALSyncWorker(
+	syncer WALSyncer,
+	syncInterval int,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return startWALSyncWorker(ctx, syncer, syncInterval)
+	}
+}
+
+func startWALSyncWorker(
+	ctx context.Context,
+	syncer WALSyncer,
+	syncInterval int,
+) error {
+	ticker := time.NewTicker(time.Duration(max(syncInterval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return syncer.Sync()
+		case <-ticker.C:
+			if err := syncer.Sync(); err != nil {
+				logger.Log.Errorw("Failed to sync write-ahead log", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Go/yandex-practicum-go-advanced-metrics/internal/workers/wal_sync.go
+
+// Package workers is a generated GoMock package.
+package workers
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWALSyncer is a mock of WALSyncer interface.
+type MockWALSyncer struct {
+	ctrl     *gomock.Controller
+	recorder *MockWALSyncerMockRecorder
+}
+
+// MockWALSyncerMockRecorder is the mock recorder for MockWALSyncer.
+type MockWALSyncerMockRecorder struct {
+	mock *MockWALSyncer
+}
+
+// NewMockWALSyncer creates a new mock instance.
+func NewMockWALSyncer(ctrl *gomock.Controller) *MockWALSyncer {
+	mock := &MockWALSyncer{ctrl: ctrl}
+	mock.recorder = &MockWALSyncerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWALSyncer) EXPECT() *MockWALSyncerMockRecorder {
+	return m.recorder
+}
+
+// Sync mocks base method.
+func (m *MockWALSyncer) Sync() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockWALSyncerMockRecorder) Sync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockWALSyncer)(nil).Sync))
+}
@@ -0,0 +1,29 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWALSyncWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSyncer := NewMockWALSyncer(ctrl)
+
+	gomock.InOrder(
+		mockSyncer.EXPECT().Sync().Return(errors.New("sync failed")).Times(1),
+		mockSyncer.EXPECT().Sync().Return(nil).MinTimes(1),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	err := NewWALSyncWorker(mockSyncer, 1)(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,315 @@
+// Package client pushes application metrics to a metrics server.
+//
+// Handles returned by Counter and Gauge are cheap to use on hot paths:
+// updates are aggregated in process and sent by a background flusher, one
+// request per changed metric per flush. Counters send the sum of their
+// increments since the last successful flush, gauges their latest value.
+//
+//	c := client.New("localhost:8080")
+//	defer c.Close(context.Background())
+//
+//	c.Counter("orders_total").Add(1)
+//	c.Gauge("queue_depth").Set(42)
+//
+// Servers that require API tokens or TLS are reached with WithToken and
+// WithTLSConfig.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/facades"
+	"github.com/sbilibin2017/yandex-practicum-go-advanced-metrics/internal/types"
+)
+
+const (
+	defaultFlushInterval = 10 * time.Second
+	defaultEndpoint      = "update"
+	defaultTimeout       = 5 * time.Second
+)
+
+type updater interface {
+	Update(ctx context.Context, req types.MetricsUpdatePathRequest) error
+}
+
+// Client aggregates metrics and flushes them to the server. It is safe for
+// concurrent use.
+type Client struct {
+	updater       updater
+	flushInterval time.Duration
+	endpoint      string
+	timeout       time.Duration
+	token         string
+	tlsConfig     *tls.Config
+	onError       func(error)
+
+	mu       sync.Mutex
+	counters map[string]*Counter
+	gauges   map[string]*Gauge
+
+	flushMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithFlushInterval sets how often the background flusher sends updates.
+// The default is 10 seconds.
+func WithFlushInterval(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.flushInterval = d
+		}
+	}
+}
+
+// WithEndpoint sets the update endpoint path on the server. The default is
+// "update".
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithTimeout sets the timeout of each request. The default is 5 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithToken sends token as a bearer token with every request, for servers
+// that require API tokens with the write scope.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to reach the server: the CA
+// that signed its certificate in RootCAs and, when the server requires a
+// client certificate, Certificates. A server address without a scheme then
+// defaults to HTTPS.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithErrorHandler receives errors from background flushes, which are
+// otherwise dropped. Failed updates are kept and retried on the next flush.
+func WithErrorHandler(fn func(error)) Option {
+	return func(c *Client) {
+		c.onError = fn
+	}
+}
+
+// New returns a client for the server at serverAddr and starts its
+// background flusher. Call Close to stop it and send what is pending.
+func New(serverAddr string, opts ...Option) *Client {
+	c := &Client{
+		flushInterval: defaultFlushInterval,
+		endpoint:      defaultEndpoint,
+		timeout:       defaultTimeout,
+		counters:      make(map[string]*Counter),
+		gauges:        make(map[string]*Gauge),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	httpClient := resty.New().SetTimeout(c.timeout)
+	if c.token != "" {
+		httpClient.SetAuthToken(c.token)
+	}
+	if c.tlsConfig != nil {
+		httpClient.SetTLSClientConfig(c.tlsConfig)
+		if !strings.HasPrefix(serverAddr, "http://") && !strings.HasPrefix(serverAddr, "https://") {
+			serverAddr = "https://" + serverAddr
+		}
+	}
+	c.updater = facades.NewMetricUpdateFacade(httpClient, serverAddr, c.endpoint)
+
+	go c.run()
+
+	return c
+}
+
+// Counter returns the handle for the counter called name, creating it on
+// first use.
+func (c *Client) Counter(name string) *Counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter, ok := c.counters[name]
+	if !ok {
+		counter = &Counter{name: name}
+		c.counters[name] = counter
+	}
+	return counter
+}
+
+// Gauge returns the handle for the gauge called name, creating it on first
+// use.
+func (c *Client) Gauge(name string) *Gauge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	gauge, ok := c.gauges[name]
+	if !ok {
+		gauge = &Gauge{name: name}
+		c.gauges[name] = gauge
+	}
+	return gauge
+}
+
+// Flush sends every metric that changed since the last successful flush.
+// Updates that fail are kept for the next flush, so counter increments are
+// not lost.
+func (c *Client) Flush(ctx context.Context) error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	counters := make([]*Counter, 0, len(c.counters))
+	for _, counter := range c.counters {
+		counters = append(counters, counter)
+	}
+	gauges := make([]*Gauge, 0, len(c.gauges))
+	for _, gauge := range c.gauges {
+		gauges = append(gauges, gauge)
+	}
+	c.mu.Unlock()
+
+	var errs []error
+
+	for _, counter := range counters {
+		delta := counter.pending.Swap(0)
+		if delta == 0 {
+			continue
+		}
+		err := c.updater.Update(ctx, types.MetricsUpdatePathRequest{
+			Name:  counter.name,
+			MType: types.Counter,
+			Value: strconv.FormatInt(delta, 10),
+		})
+		if err != nil {
+			counter.pending.Add(delta)
+			errs = append(errs, err)
+		}
+	}
+
+	for _, gauge := range gauges {
+		value, version, ok := gauge.pending()
+		if !ok {
+			continue
+		}
+		err := c.updater.Update(ctx, types.MetricsUpdatePathRequest{
+			Name:  gauge.name,
+			MType: types.Gauge,
+			Value: strconv.FormatFloat(value, 'f', -1, 64),
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		gauge.sent(version)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops the background flusher and flushes once more. Handles stay
+// usable but nothing is sent after Close returns.
+func (c *Client) Close(ctx context.Context) error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+	close(c.stop)
+	<-c.done
+
+	return c.Flush(ctx)
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.Flush(context.Background()); err != nil && c.onError != nil {
+				c.onError(err)
+			}
+		}
+	}
+}
+
+// Counter is a monotonically accumulated metric.
+type Counter struct {
+	name    string
+	pending atomic.Int64
+}
+
+// Add increments the counter by n.
+func (c *Counter) Add(n int64) {
+	c.pending.Add(n)
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.pending.Add(1)
+}
+
+// Gauge is a metric whose latest value is reported.
+type Gauge struct {
+	name string
+
+	mu       sync.Mutex
+	value    float64
+	version  uint64
+	reported uint64
+}
+
+// Set records v as the gauge's current value.
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.value = v
+	g.version++
+}
+
+func (g *Gauge) pending() (float64, uint64, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.value, g.version, g.version != g.reported
+}
+
+func (g *Gauge) sent(version uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.reported = max(g.reported, version)
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	failures int
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.failures > 0 {
+			s.failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.requests = append(s.requests, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := s.requests
+	s.requests = nil
+	sort.Strings(requests)
+	return requests
+}
+
+func (s *recordingServer) fail(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+}
+
+func TestClient_AggregatesBetweenFlushes(t *testing.T) {
+	srv := newRecordingServer(t)
+	c := New(srv.URL, WithFlushInterval(time.Hour))
+	defer c.Close(context.Background())
+
+	ctx := context.Background()
+
+	orders := c.Counter("orders_total")
+	orders.Add(2)
+	orders.Inc()
+	c.Counter("orders_total").Add(4)
+	c.Gauge("queue_depth").Set(10)
+	c.Gauge("queue_depth").Set(7.5)
+	c.Counter("unused_total")
+
+	require.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{
+		"/update/counter/orders_total/7",
+		"/update/gauge/queue_depth/7.5",
+	}, srv.take())
+
+	// Nothing changed: nothing is sent.
+	require.NoError(t, c.Flush(ctx))
+	assert.Empty(t, srv.take())
+
+	orders.Add(1)
+	require.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{"/update/counter/orders_total/1"}, srv.take())
+}
+
+func TestClient_RetriesFailedUpdates(t *testing.T) {
+	srv := newRecordingServer(t)
+	c := New(srv.URL, WithFlushInterval(time.Hour))
+	defer c.Close(context.Background())
+
+	ctx := context.Background()
+
+	c.Counter("orders_total").Add(3)
+	c.Gauge("queue_depth").Set(1)
+
+	srv.fail(2)
+	err := c.Flush(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server returned status 503")
+	assert.Empty(t, srv.take())
+
+	c.Counter("orders_total").Add(2)
+	c.Gauge("queue_depth").Set(2)
+
+	require.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{
+		"/update/counter/orders_total/5",
+		"/update/gauge/queue_depth/2",
+	}, srv.take())
+}
+
+func TestClient_BackgroundFlushAndClose(t *testing.T) {
+	srv := newRecordingServer(t)
+
+	var (
+		mu          sync.Mutex
+		flushErrors []error
+	)
+	c := New(strings.TrimPrefix(srv.URL, "http://"),
+		WithFlushInterval(20*time.Millisecond),
+		WithEndpoint("/update/"),
+		WithTimeout(time.Second),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			flushErrors = append(flushErrors, err)
+		}),
+	)
+
+	// The first background flush fails and the increment is sent by a later one.
+	srv.fail(1)
+	c.Counter("orders_total").Add(1)
+
+	require.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return len(srv.requests) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/update/counter/orders_total/1"}, srv.take())
+
+	mu.Lock()
+	assert.Len(t, flushErrors, 1)
+	mu.Unlock()
+
+	c.Gauge("queue_depth").Set(3)
+	require.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, []string{"/update/gauge/queue_depth/3"}, srv.take())
+
+	// Close is idempotent.
+	require.NoError(t, c.Close(context.Background()))
+}
+
+func TestClient_TokenAndTLS(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sdk-secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	tlsConfig := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	// Without a scheme the address defaults to HTTPS once TLS is configured.
+	addr := strings.TrimPrefix(srv.URL, "https://")
+	ctx := context.Background()
+
+	anonymous := New(addr, WithFlushInterval(time.Hour), WithTLSConfig(tlsConfig))
+	defer anonymous.Close(ctx)
+	anonymous.Counter("orders_total").Add(1)
+	require.Error(t, anonymous.Flush(ctx), "the server rejects requests without a token")
+
+	untrusted := New(addr, WithFlushInterval(time.Hour), WithToken("sdk-secret"),
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	defer untrusted.Close(ctx)
+	untrusted.Counter("orders_total").Add(1)
+	require.Error(t, untrusted.Flush(ctx), "the server certificate is not trusted without its CA")
+
+	c := New(addr, WithFlushInterval(time.Hour), WithToken("sdk-secret"), WithTLSConfig(tlsConfig))
+	defer c.Close(ctx)
+	c.Counter("orders_total").Add(2)
+	require.NoError(t, c.Flush(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"/update/counter/orders_total/2"}, requests)
+}